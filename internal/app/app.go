@@ -16,10 +16,11 @@ import (
 )
 
 type App struct {
-	services   *service.Services
-	repository *repository.Repository
-	hanlder    *http.Handler
-	logger     logger.Logger
+	services    *service.Services
+	repository  *repository.Repository
+	hanlder     *http.Handler
+	logger      logger.Logger
+	stopWorkers context.CancelFunc
 }
 
 func New(config config.Config) *App {
@@ -47,7 +48,7 @@ func New(config config.Config) *App {
 
 	jwt := jwt.New(config.JWT)
 	repository := repository.New(psql, logger)
-	services := service.New(ctx, repository, jwt, fileStorage)
+	services := service.New(ctx, repository, jwt, fileStorage, config.Services, logger)
 	handler := http.New(config.Server, services, logger, jwt)
 
 	return &App{
@@ -59,6 +60,8 @@ func New(config config.Config) *App {
 }
 
 func (app *App) Start() error {
+	app.startWorkers()
+
 	if err := app.hanlder.Start(); err != nil {
 		app.logger.Errorf("failed to start app: %s", err)
 		return err
@@ -68,7 +71,23 @@ func (app *App) Start() error {
 }
 
 func (app *App) Shutdown(context.Context) {
+	if app.stopWorkers != nil {
+		app.stopWorkers()
+	}
 	if err := app.hanlder.Stop(context.Background()); err != nil {
 		panic(err)
 	}
 }
+
+// startWorkers runs background jobs, they are stopped on Shutdown.
+func (app *App) startWorkers() {
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopWorkers = cancel
+
+	go app.services.Webhook.Run(ctx)
+	go app.services.Retention.Run(ctx)
+	go app.services.Presence.Run(ctx)
+	go app.services.Export.Run(ctx)
+	go app.services.ScheduledMessage.Run(ctx)
+	go app.services.LinkPreview.Run(ctx)
+}
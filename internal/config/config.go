@@ -7,6 +7,7 @@ import (
 	"spsu-chat/internal/handlers/http"
 	"spsu-chat/internal/jwt"
 	"spsu-chat/internal/repository/postgresql"
+	"spsu-chat/internal/service"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -27,6 +28,7 @@ type Config struct {
 	App         AppConfig                          `yaml:"app"`
 	JWT         jwt.Config                         `yaml:"jwt"`
 	FileStorage filestorage.LocalFileStorageConfig `yaml:"fileStorage"`
+	Services    service.Config                     `yaml:"services"`
 }
 
 var (
@@ -7,7 +7,8 @@ import (
 )
 
 const (
-	MangaBucket = "manga"
+	MangaBucket   = "manga"
+	ExportsBucket = "exports"
 )
 
 var (
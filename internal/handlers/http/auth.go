@@ -77,6 +77,10 @@ func (h *Handler) signIn(ctx echo.Context) error {
 		switch {
 		case errors.Is(err, models.ErrInvalidCredentials):
 			return h.newAuthErrorResponse(ctx, http.StatusUnauthorized, err)
+		case errors.Is(err, models.ErrUserBanned), errors.Is(err, models.ErrUserSuspended):
+			return h.newAuthErrorResponse(ctx, http.StatusForbidden, err)
+		case errors.Is(err, models.ErrUserDeleted):
+			return h.newAuthErrorResponse(ctx, http.StatusUnauthorized, err)
 		default:
 			return h.newAppErrorResponse(ctx, err)
 		}
@@ -108,6 +112,10 @@ func (h *Handler) refreshTokens(ctx echo.Context) error {
 			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, jwt.ErrInvalidToken)
 		case errors.Is(err, jwt.ErrTokenExpired):
 			return h.newAuthErrorResponse(ctx, http.StatusUnauthorized, jwt.ErrTokenExpired)
+		case errors.Is(err, models.ErrUserBanned), errors.Is(err, models.ErrUserSuspended):
+			return h.newAuthErrorResponse(ctx, http.StatusForbidden, err)
+		case errors.Is(err, models.ErrUserDeleted):
+			return h.newAuthErrorResponse(ctx, http.StatusUnauthorized, err)
 		default:
 			return h.newAppErrorResponse(ctx, err)
 		}
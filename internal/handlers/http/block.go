@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getBlockedUsersResponse struct {
+	Users []models.User `json:"users"`
+}
+
+func (h *Handler) getBlockedUsers(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	users, err := h.services.User.GetBlocked(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getBlockedUsersResponse{Users: users})
+
+	return nil
+}
+
+func (h *Handler) blockUser(ctx echo.Context) error {
+	blockedID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	err = h.services.User.Block(ctx.Request().Context(), user.ID, blockedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrCannotBlockSelf):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) unblockUser(ctx echo.Context) error {
+	blockedID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	err = h.services.User.Unblock(ctx.Request().Context(), user.ID, blockedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUserNotBlocked):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
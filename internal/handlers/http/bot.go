@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"spsu-chat/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createBotRequest struct {
+	Username    string  `json:"username"`
+	DisplayName *string `json:"display_name,omitempty"`
+}
+
+func (h *Handler) createBot(ctx echo.Context) error {
+	var req createBotRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	input, err := models.NewCreateBotInput(req.Username, req.DisplayName)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	credentials, err := h.services.Bot.Create(ctx.Request().Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrUsernameExists):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, credentials)
+
+	return nil
+}
+
+func (h *Handler) rotateBotToken(ctx echo.Context) error {
+	botID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid bot id"))
+	}
+
+	credentials, err := h.services.Bot.RotateToken(ctx.Request().Context(), botID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrBotNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, credentials)
+
+	return nil
+}
+
+func (h *Handler) revokeBotTokens(ctx echo.Context) error {
+	botID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid bot id"))
+	}
+
+	err = h.services.Bot.RevokeTokens(ctx.Request().Context(), botID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrBotNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type getBotUpdatesRequest struct {
+	Offset  int64  `query:"offset"`
+	Limit   uint64 `query:"limit"`
+	Timeout int64  `query:"timeout"`
+}
+
+func (h *Handler) getBotUpdates(ctx echo.Context) error {
+	req := getBotUpdatesRequest{
+		Limit: models.MaxBotUpdatesLimit,
+	}
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	bot, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewGetBotUpdatesInput(req.Offset, req.Limit, time.Duration(req.Timeout)*time.Second)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	// long polling request is a realtime connection, bot is online while it waits
+	disconnect := h.services.Presence.Connect(bot.ID)
+	defer disconnect()
+
+	updates, err := h.services.Bot.GetUpdates(ctx.Request().Context(), bot.ID, input)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, updates)
+
+	return nil
+}
+
+type botCommandsRequest struct {
+	Commands []models.BotCommand `json:"commands"`
+}
+
+type botCommandsResponse struct {
+	Commands []models.BotCommand `json:"commands"`
+}
+
+func (h *Handler) setBotCommands(ctx echo.Context) error {
+	var req botCommandsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	bot, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	commands, err := models.NewSetBotCommandsInput(bot.ID, req.Commands)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	if err := h.services.Bot.SetCommands(ctx.Request().Context(), bot.ID, commands); err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, botCommandsResponse{Commands: commands})
+
+	return nil
+}
+
+func (h *Handler) getBotCommands(ctx echo.Context) error {
+	bot, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	commands, err := h.services.Bot.GetCommands(ctx.Request().Context(), bot.ID)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, botCommandsResponse{Commands: commands})
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getAllCategoriesResponse struct {
+	Categories []models.Category `json:"categories"`
+}
+
+func (h *Handler) getAllCategories(ctx echo.Context) error {
+	categories, err := h.services.Category.GetAll(ctx.Request().Context())
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getAllCategoriesResponse{Categories: categories})
+
+	return nil
+}
+
+type createCategoryRequest struct {
+	Name string `json:"name"`
+}
+
+type createCategoryResponse struct {
+	Category models.Category `json:"category"`
+}
+
+func (h *Handler) createCategory(ctx echo.Context) error {
+	var req createCategoryRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	input, err := models.NewCreateCategoryInput(req.Name)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	category, err := h.services.Category.Create(ctx.Request().Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrCategoryExists):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, createCategoryResponse{Category: category})
+
+	return nil
+}
+
+func (h *Handler) deleteCategory(ctx echo.Context) error {
+	categoryID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid category id"))
+	}
+
+	if err := h.services.Category.Delete(ctx.Request().Context(), categoryID); err != nil {
+		switch {
+		case errors.Is(err, models.ErrCategoryNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type setChatCategoriesRequest struct {
+	CategoryIDs []int64 `json:"category_ids"`
+}
+
+func (h *Handler) setChatCategories(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	var req setChatCategoriesRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	categoryIDs, err := models.NewChatCategoryIDs(req.CategoryIDs)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	categories, err := h.services.Category.SetChatCategories(ctx.Request().Context(), chatID, categoryIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrCategoryNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getAllCategoriesResponse{Categories: categories})
+
+	return nil
+}
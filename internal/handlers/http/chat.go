@@ -45,11 +45,26 @@ type getAllChatsResponse struct {
 }
 
 func (h *Handler) getAllChats(ctx echo.Context) error {
+	var filters models.GetChatsFilters
+
+	err := ctx.Bind(&filters)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+	if err := filters.Validate(); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
 	reqPagination, err := getPaginationFromContext(ctx)
 	if err != nil {
 		return h.newAppErrorResponse(ctx, err)
 	}
-	chats, pagination, err := h.services.Chat.GetAll(ctx.Request().Context(), reqPagination)
+	chats, pagination, err := h.services.Chat.GetAll(ctx.Request().Context(), user.ID, reqPagination, filters)
 	if err != nil {
 		return h.newAppErrorResponse(ctx, err)
 	}
@@ -146,3 +161,93 @@ func (h *Handler) leaveChat(ctx echo.Context) error {
 
 	return nil
 }
+
+func (h *Handler) addChatModerator(ctx echo.Context) error {
+	return h.setChatMemberRole(ctx, models.ChatRoleModerator)
+}
+
+func (h *Handler) removeChatModerator(ctx echo.Context) error {
+	return h.setChatMemberRole(ctx, models.ChatRoleMember)
+}
+
+func (h *Handler) setChatMemberRole(ctx echo.Context, role models.ChatRole) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+	memberID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	err = h.services.Chat.SetMemberRole(ctx.Request().Context(), user, chatID, memberID, role)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrChatMemberNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+// updateChatSettingsRequest fields which are not set are left unchanged.
+type updateChatSettingsRequest struct {
+	// RetentionDays equal to zero disables retention.
+	RetentionDays *int `json:"retention_days"`
+	// ForwardingDisabled can be set only for private chats.
+	ForwardingDisabled *bool `json:"forwarding_disabled"`
+	// SlowModeSeconds equal to zero disables slow mode.
+	SlowModeSeconds *int `json:"slow_mode_seconds"`
+}
+
+func (h *Handler) updateChatSettings(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	var req updateChatSettingsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewUpdateChatSettingsInput(req.RetentionDays, req.ForwardingDisabled, req.SlowModeSeconds)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	chat, err := h.services.Chat.UpdateSettings(ctx.Request().Context(), user, chatID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrChatNotPrivate):
+			return h.newErrorResponse(ctx, http.StatusBadRequest, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, chat)
+
+	return nil
+}
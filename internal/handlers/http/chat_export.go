@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/service/chatexport"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+func (h *Handler) exportChat(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	format, err := models.NewChatExportFormat(ctx.QueryParam("format"))
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	chat, err := h.services.ChatExport.GetChat(ctx.Request().Context(), user.ID, chatID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	response := ctx.Response()
+	response.Header().Set(echo.HeaderContentType, chatexport.ContentType(format))
+	response.Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"%s\"", chatexport.Filename(chat, format)),
+	)
+	response.WriteHeader(http.StatusOK)
+
+	// response is already started, so the error can only be logged
+	if err := h.services.ChatExport.Write(ctx.Request().Context(), chat, format, response); err != nil {
+		h.logger.Errorf("exportChat: chat %d: %s", chat.ID, err)
+	}
+
+	return nil
+}
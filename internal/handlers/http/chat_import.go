@@ -0,0 +1,85 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// importChat accepts multipart form with Telegram export in "file", optional
+// JSON object "mapping" of Telegram sender ids to user ids and "dry_run" flag.
+func (h *Handler) importChat(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	input := models.ImportChatInput{
+		ChatID:  chatID,
+		UserID:  user.ID,
+		Mapping: make(map[string]int64),
+	}
+
+	if mapping := ctx.FormValue("mapping"); mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &input.Mapping); err != nil {
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid mapping"))
+		}
+	}
+	if dryRun := ctx.FormValue("dry_run"); dryRun != "" {
+		input.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid dry_run"))
+		}
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("file is required"))
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	defer file.Close()
+
+	input.Data, err = io.ReadAll(file)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	result, err := h.services.ChatImport.Import(ctx.Request().Context(), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrImportFileTooLarge):
+			return h.newErrorResponse(ctx, http.StatusRequestEntityTooLarge, err.Error())
+		case errors.Is(err, models.ErrInvalidImportFile), errors.Is(err, models.ErrImportMappingUser):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrPlaceholderTaken):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	status := http.StatusCreated
+	if result.DryRun {
+		status = http.StatusOK
+	}
+	ctx.JSON(status, result)
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type draftResponse struct {
+	Draft models.Draft `json:"draft"`
+}
+
+func (h *Handler) getDraft(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	draft, err := h.services.Draft.Get(ctx.Request().Context(), user.ID, chatID)
+	if err != nil {
+		return h.draftErrorResponse(ctx, err)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderLastModified, draft.UpdatedAt.UTC().Format(http.TimeFormat))
+	ctx.JSON(http.StatusOK, draftResponse{Draft: draft})
+
+	return nil
+}
+
+type saveDraftRequest struct {
+	Text string `json:"text"`
+}
+
+func (h *Handler) saveDraft(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	var req saveDraftRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewSaveDraftInput(user.ID, chatID, req.Text)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	draft, err := h.services.Draft.Save(ctx.Request().Context(), input)
+	if err != nil {
+		return h.draftErrorResponse(ctx, err)
+	}
+
+	ctx.Response().Header().Set(echo.HeaderLastModified, draft.UpdatedAt.UTC().Format(http.TimeFormat))
+	ctx.JSON(http.StatusOK, draftResponse{Draft: draft})
+
+	return nil
+}
+
+func (h *Handler) deleteDraft(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	if err := h.services.Draft.Delete(ctx.Request().Context(), user.ID, chatID); err != nil {
+		return h.draftErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) draftErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrDraftNotFound):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatNotJoined):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
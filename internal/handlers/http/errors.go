@@ -3,8 +3,10 @@ package http
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
 
 	"github.com/labstack/echo/v4"
 )
@@ -20,6 +22,8 @@ type httpError struct {
 	Type    string `json:"type"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// RetryAfter is set for rate limited requests, it is in seconds.
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 type errorResponse struct {
 	Error httpError `json:"error"`
@@ -78,3 +82,21 @@ func (h *Handler) newAuthErrorResponse(ctx echo.Context, code int, err error) er
 		},
 	})
 }
+
+// newRateLimitErrorResponse responds with 429, wait time of models.RateLimitError
+// is returned in Retry-After header and in the error.
+func (h *Handler) newRateLimitErrorResponse(ctx echo.Context, err error) error {
+	response := httpError{
+		Message: err.Error(),
+		Code:    http.StatusTooManyRequests,
+		Type:    baseErrorType,
+	}
+
+	var rateLimitErr models.RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		response.RetryAfter = rateLimitErr.RetryAfterSeconds()
+		ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(response.RetryAfter))
+	}
+
+	return ctx.JSON(http.StatusTooManyRequests, errorResponse{Error: response})
+}
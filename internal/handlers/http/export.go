@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getDataExportsResponse struct {
+	Exports []models.DataExport `json:"exports"`
+}
+
+func (h *Handler) requestDataExport(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	export, err := h.services.Export.Request(ctx.Request().Context(), user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrExportInProgress):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusAccepted, export)
+
+	return nil
+}
+
+func (h *Handler) getDataExports(ctx echo.Context) error {
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	exports, err := h.services.Export.GetAll(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getDataExportsResponse{Exports: exports})
+
+	return nil
+}
+
+func (h *Handler) downloadDataExport(ctx echo.Context) error {
+	exportID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid export id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	data, err := h.services.Export.Download(ctx.Request().Context(), user.ID, exportID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrExportNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrExportNotReady):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=\"export-%d.zip\"", exportID),
+	)
+	ctx.Blob(http.StatusOK, "application/zip", data)
+
+	return nil
+}
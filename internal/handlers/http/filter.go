@@ -0,0 +1,172 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getFilterRulesResponse struct {
+	Rules []models.FilterRule `json:"rules"`
+}
+
+func (h *Handler) getFilterRules(ctx echo.Context) error {
+	return h.listFilterRules(ctx, nil)
+}
+
+func (h *Handler) getChatFilterRules(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	return h.listFilterRules(ctx, &chatID)
+}
+
+func (h *Handler) listFilterRules(ctx echo.Context, chatID *int64) error {
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	rules, err := h.services.ContentFilter.GetRules(ctx.Request().Context(), user, chatID)
+	if err != nil {
+		return h.filterErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getFilterRulesResponse{Rules: rules})
+
+	return nil
+}
+
+type createFilterRuleRequest struct {
+	Kind    models.FilterRuleKind `json:"kind"`
+	Pattern string                `json:"pattern"`
+	Action  models.FilterAction   `json:"action"`
+}
+
+type createFilterRuleResponse struct {
+	Rule models.FilterRule `json:"rule"`
+}
+
+func (h *Handler) createFilterRule(ctx echo.Context) error {
+	return h.addFilterRule(ctx, nil)
+}
+
+func (h *Handler) createChatFilterRule(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	return h.addFilterRule(ctx, &chatID)
+}
+
+func (h *Handler) addFilterRule(ctx echo.Context, chatID *int64) error {
+	var req createFilterRuleRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewCreateFilterRuleInput(chatID, user.ID, req.Kind, req.Pattern, req.Action)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	rule, err := h.services.ContentFilter.CreateRule(ctx.Request().Context(), user, input)
+	if err != nil {
+		return h.filterErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusCreated, createFilterRuleResponse{Rule: rule})
+
+	return nil
+}
+
+func (h *Handler) deleteFilterRule(ctx echo.Context) error {
+	ruleID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid rule id"))
+	}
+
+	return h.removeFilterRule(ctx, nil, ruleID)
+}
+
+func (h *Handler) deleteChatFilterRule(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+	ruleID, err := strconv.ParseInt(ctx.Param("rule_id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid rule id"))
+	}
+
+	return h.removeFilterRule(ctx, &chatID, ruleID)
+}
+
+func (h *Handler) removeFilterRule(ctx echo.Context, chatID *int64, ruleID int64) error {
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	if err := h.services.ContentFilter.DeleteRule(ctx.Request().Context(), user, chatID, ruleID); err != nil {
+		return h.filterErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type getFilterHitsResponse struct {
+	Hits       []models.FilterHit    `json:"hits"`
+	Pagination models.FullPagination `json:"pagination"`
+}
+
+func (h *Handler) getFilterHits(ctx echo.Context) error {
+	var filters models.GetFilterHitsFilters
+
+	err := ctx.Bind(&filters)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+	if err := filters.Validate(); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	hits, pagination, err := h.services.ContentFilter.GetHits(ctx.Request().Context(), reqPagination, filters)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getFilterHitsResponse{Hits: hits, Pagination: pagination})
+
+	return nil
+}
+
+func (h *Handler) filterErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrFilterRuleNotFound):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatAccessDenied):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrFilterRuleExists):
+		return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
@@ -66,11 +66,25 @@ func (h *Handler) initRoutes() {
 		auth.POST("/refresh", h.refreshTokens)
 	}
 
+	v1.POST("/hooks/:token", h.postIncomingWebhook)
+
 	user := v1.Group("/users", h.Authorized())
 	{
 		user.GET("", h.getAllUsers, h.WithPagination(), h.RequireUserType(models.UserTypeAdmin))
+		user.GET("/search", h.searchUsers)
 		user.GET("/:id", h.getUserByID)
 		user.GET("/self", h.getSelfUser)
+		user.DELETE("/self", h.deleteSelfUser)
+		user.PUT("/self/presence", h.setPresenceVisibility)
+		user.POST("/self/export", h.requestDataExport)
+		user.GET("/self/exports", h.getDataExports)
+		user.GET("/self/exports/:id/download", h.downloadDataExport)
+		user.GET("/self/blocks", h.getBlockedUsers)
+		user.POST("/:id/block", h.blockUser)
+		user.DELETE("/:id/block", h.unblockUser)
+		user.POST("/:id/report", h.reportUser)
+		user.POST("/:id/suspend", h.suspendUser, h.RequireUserType(models.UserTypeAdmin))
+		user.POST("/:id/unsuspend", h.unsuspendUser, h.RequireUserType(models.UserTypeAdmin))
 	}
 
 	chat := v1.Group("/chats", h.Authorized())
@@ -78,14 +92,94 @@ func (h *Handler) initRoutes() {
 		chat.GET("", h.getAllChats, h.WithPagination())
 		chat.POST("", h.createChat, h.RequireUserType(models.UserTypeAdmin))
 		chat.GET("/:id", h.getChatByID)
+		chat.PATCH("/:id/settings", h.updateChatSettings)
+		chat.GET("/:id/draft", h.getDraft)
+		chat.PUT("/:id/draft", h.saveDraft)
+		chat.DELETE("/:id/draft", h.deleteDraft)
+		chat.GET("/:id/export", h.exportChat)
+		chat.POST("/:id/import", h.importChat)
 		chat.POST("/join", h.joinChat)
 		chat.POST("/leave", h.leaveChat)
+		chat.PUT("/:id/moderators/:user_id", h.addChatModerator)
+		chat.DELETE("/:id/moderators/:user_id", h.removeChatModerator)
+
+		chat.GET("/:id/typing", h.getTyping)
+		chat.POST("/:id/typing", h.sendTyping)
+
+		chat.GET("/:id/pins", h.getPinnedMessages)
+		chat.PUT("/:id/pins/:message_id", h.pinMessage)
+		chat.DELETE("/:id/pins/:message_id", h.unpinMessage)
+
+		chat.GET("/:id/webhooks", h.getAllWebhooks)
+		chat.POST("/:id/webhooks", h.createWebhook)
+		chat.DELETE("/:id/webhooks/:webhook_id", h.deleteWebhook)
+		chat.GET("/:id/webhooks/:webhook_id/deliveries", h.getWebhookDeliveries, h.WithPagination())
+
+		chat.GET("/:id/incoming-webhooks", h.getAllIncomingWebhooks)
+		chat.POST("/:id/incoming-webhooks", h.createIncomingWebhook)
+		chat.DELETE("/:id/incoming-webhooks/:webhook_id", h.revokeIncomingWebhook)
+
+		chat.PUT("/:id/categories", h.setChatCategories, h.RequireUserType(models.UserTypeAdmin))
+
+		chat.GET("/:id/filters", h.getChatFilterRules)
+		chat.POST("/:id/filters", h.createChatFilterRule)
+		chat.DELETE("/:id/filters/:rule_id", h.deleteChatFilterRule)
+	}
+	category := v1.Group("/categories", h.Authorized())
+	{
+		category.GET("", h.getAllCategories)
+		category.POST("", h.createCategory, h.RequireUserType(models.UserTypeAdmin))
+		category.DELETE("/:id", h.deleteCategory, h.RequireUserType(models.UserTypeAdmin))
 	}
 	message := v1.Group("/messages", h.Authorized())
 	{
 		message.GET("", h.getAllMessages, h.WithPagination())
 		message.POST("", h.SendMessage)
+		message.GET("/scheduled", h.getScheduledMessages)
+		message.POST("/scheduled", h.scheduleMessage)
+		message.PUT("/scheduled/:id", h.updateScheduledMessage)
+		message.DELETE("/scheduled/:id", h.cancelScheduledMessage)
 		message.DELETE("/:id", h.DeleteMessage)
+		message.GET("/deleted", h.getDeletedMessages, h.WithPagination(), h.RequireUserType(models.UserTypeAdmin))
+		message.POST("/:id/restore", h.restoreMessage, h.RequireUserType(models.UserTypeAdmin))
+		message.POST("/:id/report", h.reportMessage)
+		message.POST("/:id/forward", h.forwardMessage)
+	}
+	poll := v1.Group("/polls", h.Authorized())
+	{
+		poll.POST("", h.createPoll)
+		poll.GET("/:id", h.getPoll)
+		poll.POST("/:id/votes", h.votePoll)
+		poll.DELETE("/:id/votes", h.retractPollVote)
+		poll.POST("/:id/close", h.closePoll)
+	}
+	moderation := v1.Group("/moderation", h.Authorized(), h.RequireUserType(models.UserTypeAdmin))
+	{
+		moderation.GET("/reports", h.getReports, h.WithPagination())
+		moderation.POST("/reports/:id/resolve", h.resolveReport)
+		moderation.GET("/actions", h.getModerationActions, h.WithPagination())
+		moderation.GET("/filters", h.getFilterRules)
+		moderation.POST("/filters", h.createFilterRule)
+		moderation.DELETE("/filters/:id", h.deleteFilterRule)
+		moderation.GET("/filter-hits", h.getFilterHits, h.WithPagination())
+	}
+	mention := v1.Group("/mentions", h.Authorized())
+	{
+		mention.GET("", h.getAllMentions, h.WithPagination())
+		mention.POST("/read", h.readMentions)
+	}
+	bots := v1.Group("/bots", h.Authorized(), h.RequireUserType(models.UserTypeAdmin))
+	{
+		bots.POST("", h.createBot)
+		bots.POST("/:id/token", h.rotateBotToken)
+		bots.DELETE("/:id/token", h.revokeBotTokens)
+	}
+	bot := v1.Group("/bot", h.Authorized(), h.RequireUserType(models.UserTypeBot))
+	{
+		bot.GET("/updates", h.getBotUpdates)
+		bot.POST("/messages", h.SendMessage)
+		bot.GET("/commands", h.getBotCommands)
+		bot.PUT("/commands", h.setBotCommands)
 	}
 }
 
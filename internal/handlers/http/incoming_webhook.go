@@ -0,0 +1,152 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"spsu-chat/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createIncomingWebhookRequest struct {
+	Name string `json:"name"`
+}
+
+func (h *Handler) createIncomingWebhook(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	var req createIncomingWebhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewCreateIncomingWebhookInput(chatID, req.Name)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	credentials, err := h.services.IncomingWebhook.Create(ctx.Request().Context(), user, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, credentials)
+
+	return nil
+}
+
+type getAllIncomingWebhooksResponse struct {
+	Webhooks []models.IncomingWebhook `json:"webhooks"`
+}
+
+func (h *Handler) getAllIncomingWebhooks(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	webhooks, err := h.services.IncomingWebhook.GetAll(ctx.Request().Context(), user, chatID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getAllIncomingWebhooksResponse{Webhooks: webhooks})
+
+	return nil
+}
+
+func (h *Handler) revokeIncomingWebhook(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+	webhookID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid webhook id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	err = h.services.IncomingWebhook.Revoke(ctx.Request().Context(), user, chatID, webhookID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrIncomingWebhookNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type postIncomingWebhookRequest struct {
+	Text        string  `json:"text"`
+	DisplayName *string `json:"display_name,omitempty"`
+}
+
+func (h *Handler) postIncomingWebhook(ctx echo.Context) error {
+	var req postIncomingWebhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	input, err := models.NewIncomingWebhookMessageInput(req.Text, req.DisplayName)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	message, err := h.services.IncomingWebhook.Post(ctx.Request().Context(), ctx.Param("token"), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrIncomingWebhookNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrRateLimited):
+			return h.newRateLimitErrorResponse(ctx, err)
+		case errors.Is(err, models.ErrChatNotJoined), errors.Is(err, models.ErrMessageBlocked):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, sendMessageResponse{Message: message})
+
+	return nil
+}
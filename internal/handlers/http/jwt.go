@@ -8,10 +8,15 @@ import (
 
 	"spsu-chat/internal/jwt"
 	"spsu-chat/internal/models"
+	"spsu-chat/pkg/clock"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	botAuthScheme = "Bot"
+)
+
 type JWTValidator interface {
 	ValidateToken(token string) (jwt.Claims, error)
 }
@@ -25,15 +30,37 @@ func (h *Handler) Authorized() echo.MiddlewareFunc {
 				return h.newAuthErrorResponse(c, http.StatusUnauthorized, jwt.ErrInvalidToken)
 			}
 
-			claims, err := h.jwtValidator.ValidateToken(splitAuth[1])
-			if err != nil {
-				return h.newAuthErrorResponse(c, http.StatusUnauthorized, err)
+			var user models.User
+			if splitAuth[0] == botAuthScheme {
+				bot, err := h.services.Bot.Authenticate(c.Request().Context(), splitAuth[1])
+				if err != nil {
+					switch {
+					case errors.Is(err, models.ErrInvalidBotToken):
+						return h.newAuthErrorResponse(c, http.StatusUnauthorized, err)
+					default:
+						return h.newAppErrorResponse(c, err)
+					}
+				}
+				user = bot
+			} else {
+				claims, err := h.jwtValidator.ValidateToken(splitAuth[1])
+				if err != nil {
+					return h.newAuthErrorResponse(c, http.StatusUnauthorized, err)
+				}
+				user, err = h.services.User.GetByID(c.Request().Context(), claims.Subject)
+				if err != nil {
+					return h.newAppErrorResponse(c, errors.New("failed to get user type"))
+				}
 			}
-			user, err := h.services.User.GetByID(c.Request().Context(), claims.Subject)
-			if err != nil {
-				return h.newAppErrorResponse(c, errors.New("failed to get user type"))
+
+			if err := user.CheckStatus(clock.Now()); err != nil {
+				if errors.Is(err, models.ErrUserDeleted) {
+					return h.newAuthErrorResponse(c, http.StatusUnauthorized, err)
+				}
+				return h.newAuthErrorResponse(c, http.StatusForbidden, err)
 			}
 
+			h.services.Presence.Touch(user.ID)
 			c.Set("user", user)
 
 			if err := next(c); err != nil {
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getAllMentionsResponse struct {
+	Mentions    []models.Mention      `json:"mentions"`
+	UnreadCount uint64                `json:"unread_count"`
+	Pagination  models.FullPagination `json:"pagination"`
+}
+
+func (h *Handler) getAllMentions(ctx echo.Context) error {
+	var filters models.GetMentionsFilters
+
+	err := ctx.Bind(&filters)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	mentions, pagination, err := h.services.Mention.GetAll(ctx.Request().Context(), user.ID, reqPagination, filters)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	unread, err := h.services.Mention.CountUnread(ctx.Request().Context(), user.ID)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getAllMentionsResponse{
+		Mentions:    mentions,
+		UnreadCount: unread,
+		Pagination:  pagination,
+	})
+
+	return nil
+}
+
+type readMentionsRequest struct {
+	// IDs of mentions to mark as read, all mentions are marked if empty.
+	IDs []int64 `json:"ids"`
+}
+
+func (h *Handler) readMentions(ctx echo.Context) error {
+	var req readMentionsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	if err := h.services.Mention.MarkRead(ctx.Request().Context(), user.ID, req.IDs); err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
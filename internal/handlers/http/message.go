@@ -50,6 +50,12 @@ func (h *Handler) getAllMessages(ctx echo.Context) error {
 type sendMessageRequest struct {
 	Text   string `json:"text"`
 	ChatID int64  `json:"chat_id"`
+	// TTLSeconds makes message self-destructing.
+	TTLSeconds int64 `json:"ttl_seconds"`
+}
+
+type sendMessageResponse struct {
+	Message models.Message `json:"message"`
 }
 
 func (h *Handler) SendMessage(ctx echo.Context) error {
@@ -65,19 +71,41 @@ func (h *Handler) SendMessage(ctx echo.Context) error {
 	}
 
 	input := models.NewCreateMessageInput(message.ChatID, user.ID, message.Text)
+	input.TTL, err = models.NewMessageTTL(message.TTLSeconds)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
 
-	err = h.services.Message.Create(ctx.Request().Context(), input)
+	created, err := h.services.Message.Create(ctx.Request().Context(), input)
 	if err != nil {
 		switch {
 		case errors.Is(err, models.ErrChatNotJoined):
 			return h.newErrorResponse(ctx, http.StatusForbidden, models.ErrChatNotJoined.Error())
 		case errors.Is(err, models.ErrChatNotFound):
 			return h.newErrorResponse(ctx, http.StatusNotFound, models.ErrChatNotFound.Error())
+		case errors.Is(err, models.ErrUserNotFound), errors.Is(err, models.ErrChatMemberNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrUnknownCommand),
+			errors.Is(err, models.ErrInvalidCommandArgs),
+			errors.Is(err, models.ErrChatTopicTooLong):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrCommandAccessDenied),
+			errors.Is(err, models.ErrBlockedByUser),
+			errors.Is(err, models.ErrMessageBlocked):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrRateLimited):
+			return h.newRateLimitErrorResponse(ctx, err)
 		}
 		return h.newAppErrorResponse(ctx, err)
 	}
 
-	ctx.NoContent(http.StatusCreated)
+	// ephemeral command replies are not stored
+	if created.Ephemeral {
+		ctx.JSON(http.StatusOK, sendMessageResponse{Message: created})
+		return nil
+	}
+
+	ctx.JSON(http.StatusCreated, sendMessageResponse{Message: created})
 
 	return nil
 }
@@ -98,6 +126,8 @@ func (h *Handler) DeleteMessage(ctx echo.Context) error {
 		switch {
 		case errors.Is(err, models.ErrNotYourMessage):
 			return h.newErrorResponse(ctx, http.StatusForbidden, models.ErrNotYourMessage.Error())
+		case errors.Is(err, models.ErrMessageNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
 		}
 		return h.newAppErrorResponse(ctx, err)
 	}
@@ -106,3 +136,106 @@ func (h *Handler) DeleteMessage(ctx echo.Context) error {
 
 	return nil
 }
+
+type forwardMessageRequest struct {
+	ChatIDs []int64 `json:"chat_ids"`
+}
+
+type forwardMessageResponse struct {
+	Messages []models.Message `json:"messages"`
+}
+
+func (h *Handler) forwardMessage(ctx echo.Context) error {
+	messageID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid message id"))
+	}
+
+	var req forwardMessageRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	chatIDs, err := models.NewForwardTargets(req.ChatIDs)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	messages, err := h.services.Message.Forward(ctx.Request().Context(), user.ID, messageID, chatIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMessageNotFound), errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatNotJoined),
+			errors.Is(err, models.ErrForwardDisabled),
+			errors.Is(err, models.ErrMessageBlocked):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		case errors.Is(err, models.ErrCannotForwardPoll):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrRateLimited):
+			return h.newRateLimitErrorResponse(ctx, err)
+		}
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusCreated, forwardMessageResponse{Messages: messages})
+
+	return nil
+}
+
+type getDeletedMessagesResponse struct {
+	Messages   []models.Message      `json:"messages"`
+	Pagination models.FullPagination `json:"pagination"`
+}
+
+func (h *Handler) getDeletedMessages(ctx echo.Context) error {
+	var filters models.GetDeletedMessagesFilters
+
+	err := ctx.Bind(&filters)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	messages, pagination, err := h.services.Message.GetDeleted(ctx.Request().Context(), reqPagination, filters)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getDeletedMessagesResponse{
+		Messages:   messages,
+		Pagination: pagination,
+	})
+
+	return nil
+}
+
+func (h *Handler) restoreMessage(ctx echo.Context) error {
+	messageID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid message id"))
+	}
+
+	err = h.services.Message.Restore(ctx.Request().Context(), messageID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMessageNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrMessageNotDeleted):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		}
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getPinnedMessagesResponse struct {
+	Messages []models.Message `json:"messages"`
+}
+
+func (h *Handler) getPinnedMessages(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	messages, err := h.services.Message.GetPinned(ctx.Request().Context(), user.ID, chatID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatNotJoined):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getPinnedMessagesResponse{Messages: messages})
+
+	return nil
+}
+
+func (h *Handler) pinMessage(ctx echo.Context) error {
+	chatID, messageID, err := parsePinParams(ctx)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	err = h.services.Message.Pin(ctx.Request().Context(), user, chatID, messageID)
+	if err != nil {
+		return h.pinErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) unpinMessage(ctx echo.Context) error {
+	chatID, messageID, err := parsePinParams(ctx)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	err = h.services.Message.Unpin(ctx.Request().Context(), user, chatID, messageID)
+	if err != nil {
+		return h.pinErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func parsePinParams(ctx echo.Context) (int64, int64, error) {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid chat id")
+	}
+	messageID, err := strconv.ParseInt(ctx.Param("message_id"), 10, 64)
+	if err != nil {
+		return 0, 0, errors.New("invalid message id")
+	}
+
+	return chatID, messageID, nil
+}
+
+func (h *Handler) pinErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound),
+		errors.Is(err, models.ErrMessageNotFound),
+		errors.Is(err, models.ErrMessageNotPinned):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatAccessDenied):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrTooManyPinnedMessages):
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
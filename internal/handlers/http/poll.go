@@ -0,0 +1,168 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createPollRequest struct {
+	ChatID    int64      `json:"chat_id"`
+	Question  string     `json:"question"`
+	Options   []string   `json:"options"`
+	Multiple  bool       `json:"multiple"`
+	Anonymous bool       `json:"anonymous"`
+	ClosesAt  *time.Time `json:"closes_at"`
+}
+
+type votePollRequest struct {
+	OptionIDs []int64 `json:"option_ids"`
+}
+
+func (h *Handler) createPoll(ctx echo.Context) error {
+	var req createPollRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewCreatePollInput(
+		req.ChatID,
+		user.ID,
+		req.Question,
+		req.Options,
+		req.Multiple,
+		req.Anonymous,
+		req.ClosesAt,
+	)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	message, err := h.services.Poll.Create(ctx.Request().Context(), input)
+	if err != nil {
+		return h.pollErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusCreated, sendMessageResponse{Message: message})
+
+	return nil
+}
+
+func (h *Handler) getPoll(ctx echo.Context) error {
+	pollID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid poll id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	poll, err := h.services.Poll.Get(ctx.Request().Context(), user.ID, pollID)
+	if err != nil {
+		return h.pollErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, poll)
+
+	return nil
+}
+
+func (h *Handler) votePoll(ctx echo.Context) error {
+	pollID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid poll id"))
+	}
+
+	var req votePollRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	poll, err := h.services.Poll.Vote(ctx.Request().Context(), user.ID, pollID, req.OptionIDs)
+	if err != nil {
+		return h.pollErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, poll)
+
+	return nil
+}
+
+func (h *Handler) retractPollVote(ctx echo.Context) error {
+	pollID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid poll id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	poll, err := h.services.Poll.Retract(ctx.Request().Context(), user.ID, pollID)
+	if err != nil {
+		return h.pollErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, poll)
+
+	return nil
+}
+
+func (h *Handler) closePoll(ctx echo.Context) error {
+	pollID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid poll id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	poll, err := h.services.Poll.Close(ctx.Request().Context(), user, pollID)
+	if err != nil {
+		return h.pollErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, poll)
+
+	return nil
+}
+
+func (h *Handler) pollErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound),
+		errors.Is(err, models.ErrPollNotFound),
+		errors.Is(err, models.ErrPollNotVoted):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatNotJoined), errors.Is(err, models.ErrChatAccessDenied):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrPollClosed):
+		return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrRateLimited):
+		return h.newRateLimitErrorResponse(ctx, err)
+	case errors.Is(err, models.ErrInvalidPollCloseTime),
+		errors.Is(err, models.ErrInvalidPollVote),
+		errors.Is(err, models.ErrPollSingleChoice):
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
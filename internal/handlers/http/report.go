@@ -0,0 +1,167 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createReportRequest struct {
+	Reason  models.ReportReason `json:"reason"`
+	Comment string              `json:"comment"`
+}
+
+type createReportResponse struct {
+	Report models.Report `json:"report"`
+}
+
+func (h *Handler) reportMessage(ctx echo.Context) error {
+	return h.createReport(ctx, "invalid message id", h.services.Moderation.ReportMessage)
+}
+
+func (h *Handler) reportUser(ctx echo.Context) error {
+	return h.createReport(ctx, "invalid user id", h.services.Moderation.ReportUser)
+}
+
+func (h *Handler) createReport(
+	ctx echo.Context,
+	invalidIDMessage string,
+	report func(ctx context.Context, targetID int64, input models.CreateReportInput) (models.Report, error),
+) error {
+	targetID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New(invalidIDMessage))
+	}
+
+	var req createReportRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewCreateReportInput(user.ID, req.Reason, req.Comment)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	created, err := report(ctx.Request().Context(), targetID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrMessageNotFound), errors.Is(err, models.ErrUserNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrCannotReportSelf):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		case errors.Is(err, models.ErrReportExists):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, createReportResponse{Report: created})
+
+	return nil
+}
+
+type getReportsResponse struct {
+	Reports    []models.Report       `json:"reports"`
+	Pagination models.FullPagination `json:"pagination"`
+}
+
+func (h *Handler) getReports(ctx echo.Context) error {
+	var filters models.GetReportsFilters
+
+	err := ctx.Bind(&filters)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+	if err := filters.Validate(); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	reports, pagination, err := h.services.Moderation.GetReports(ctx.Request().Context(), reqPagination, filters)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getReportsResponse{Reports: reports, Pagination: pagination})
+
+	return nil
+}
+
+type resolveReportRequest struct {
+	Action  models.ModerationAction `json:"action"`
+	Comment string                  `json:"comment"`
+}
+
+func (h *Handler) resolveReport(ctx echo.Context) error {
+	reportID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid report id"))
+	}
+
+	var req resolveReportRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	admin, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewResolveReportInput(req.Action, req.Comment)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	err = h.services.Moderation.Resolve(ctx.Request().Context(), admin, reportID, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrReportNotFound), errors.Is(err, models.ErrUserNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrReportResolved):
+			return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+		case errors.Is(err, models.ErrInvalidModerationAction), errors.Is(err, models.ErrCannotBanAdmin):
+			return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type getModerationActionsResponse struct {
+	Actions    []models.ModerationActionRecord `json:"actions"`
+	Pagination models.FullPagination           `json:"pagination"`
+}
+
+func (h *Handler) getModerationActions(ctx echo.Context) error {
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+	actions, pagination, err := h.services.Moderation.GetActions(ctx.Request().Context(), reqPagination)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getModerationActionsResponse{Actions: actions, Pagination: pagination})
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+type scheduleMessageRequest struct {
+	ChatID int64     `json:"chat_id"`
+	Text   string    `json:"text"`
+	SendAt time.Time `json:"send_at"`
+}
+
+type updateScheduledMessageRequest struct {
+	Text   string    `json:"text"`
+	SendAt time.Time `json:"send_at"`
+}
+
+type getScheduledMessagesResponse struct {
+	Messages []models.ScheduledMessage `json:"messages"`
+}
+
+func (h *Handler) getScheduledMessages(ctx echo.Context) error {
+	var filters models.GetScheduledMessagesFilters
+	if err := ctx.Bind(&filters); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid filters"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	messages, err := h.services.ScheduledMessage.GetAll(ctx.Request().Context(), user.ID, filters)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getScheduledMessagesResponse{Messages: messages})
+
+	return nil
+}
+
+func (h *Handler) scheduleMessage(ctx echo.Context) error {
+	var request scheduleMessageRequest
+	if err := ctx.Bind(&request); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	input, err := models.NewCreateScheduledMessageInput(request.ChatID, user.ID, request.Text, request.SendAt)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	message, err := h.services.ScheduledMessage.Create(ctx.Request().Context(), input)
+	if err != nil {
+		return h.scheduledMessageErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusCreated, message)
+
+	return nil
+}
+
+func (h *Handler) updateScheduledMessage(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid scheduled message id"))
+	}
+
+	var request updateScheduledMessageRequest
+	if err := ctx.Bind(&request); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	input, err := models.NewUpdateScheduledMessageInput(request.Text, request.SendAt)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	message, err := h.services.ScheduledMessage.Update(ctx.Request().Context(), user.ID, id, input)
+	if err != nil {
+		return h.scheduledMessageErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, message)
+
+	return nil
+}
+
+func (h *Handler) cancelScheduledMessage(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid scheduled message id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	if err := h.services.ScheduledMessage.Cancel(ctx.Request().Context(), user.ID, id); err != nil {
+		return h.scheduledMessageErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) scheduledMessageErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrScheduledMessageNotFound):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatNotJoined):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrScheduledMessageNotPending):
+		return h.newErrorResponse(ctx, http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrSendAtInPast),
+		errors.Is(err, models.ErrSendAtTooFar),
+		errors.Is(err, models.ErrCannotScheduleCommand):
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
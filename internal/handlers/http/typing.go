@@ -0,0 +1,66 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"spsu-chat/internal/models"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type getTypingResponse struct {
+	UserIDs []int64 `json:"user_ids"`
+}
+
+func (h *Handler) getTyping(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	userIDs, err := h.services.Presence.GetTyping(ctx.Request().Context(), user.ID, chatID)
+	if err != nil {
+		return h.typingErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, getTypingResponse{UserIDs: userIDs})
+
+	return nil
+}
+
+func (h *Handler) sendTyping(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	if err := h.services.Presence.SetTyping(ctx.Request().Context(), user.ID, chatID); err != nil {
+		return h.typingErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) typingErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrChatNotFound):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChatNotJoined):
+		return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
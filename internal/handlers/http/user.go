@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"spsu-chat/internal/models"
 
@@ -20,26 +21,71 @@ func (h *Handler) getAllUsers(ctx echo.Context) error {
 	if err != nil {
 		return h.newAppErrorResponse(ctx, err)
 	}
+	viewer, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
 	users, pagination, err := h.services.User.GetAll(ctx.Request().Context(), reqPagination)
 	if err != nil {
 		return h.newAppErrorResponse(ctx, err)
 	}
+	for i := range users {
+		users[i] = h.services.Presence.Resolve(viewer.ID, users[i])
+	}
 
 	ctx.JSON(http.StatusOK, getAllUsersResponse{Users: users, Pagination: pagination})
 
 	return nil
 }
 
+type searchUsersRequest struct {
+	Query string `query:"q"`
+	Limit uint64 `query:"limit"`
+}
+
+type searchUsersResponse struct {
+	Users []models.PublicUser `json:"users"`
+}
+
+func (h *Handler) searchUsers(ctx echo.Context) error {
+	var req searchUsersRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	input, err := models.NewSearchUsersInput(req.Query, req.Limit)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	users, err := h.services.User.Search(ctx.Request().Context(), input)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.JSON(http.StatusOK, searchUsersResponse{Users: users})
+
+	return nil
+}
+
 type getUserResponse struct {
 	User models.User `json:"user"`
 }
 
+type getUserProfileResponse struct {
+	User models.UserProfile `json:"user"`
+}
+
 func (h *Handler) getUserByID(ctx echo.Context) error {
 	id := ctx.Param("id")
 	userID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
 	}
+	viewer, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
 
 	user, err := h.services.User.GetByID(ctx.Request().Context(), userID)
 	if err != nil {
@@ -51,6 +97,12 @@ func (h *Handler) getUserByID(ctx echo.Context) error {
 		}
 	}
 
+	user = h.services.Presence.Resolve(viewer.ID, user)
+	if viewer.ID != user.ID && viewer.Type != models.UserTypeAdmin {
+		ctx.JSON(http.StatusOK, getUserProfileResponse{User: user.Profile()})
+		return nil
+	}
+
 	ctx.JSON(http.StatusOK, getUserResponse{User: user})
 
 	return nil
@@ -72,7 +124,136 @@ func (h *Handler) getSelfUser(ctx echo.Context) error {
 		}
 	}
 
-	ctx.JSON(http.StatusOK, getUserResponse{User: user})
+	ctx.JSON(http.StatusOK, getUserResponse{User: h.services.Presence.Resolve(user.ID, user)})
 
 	return nil
 }
+
+type setPresenceVisibilityRequest struct {
+	Hidden bool `json:"hidden"`
+}
+
+func (h *Handler) setPresenceVisibility(ctx echo.Context) error {
+	var req setPresenceVisibilityRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	if err := h.services.Presence.SetHidden(ctx.Request().Context(), user.ID, req.Hidden); err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type deleteSelfUserRequest struct {
+	Password string `json:"password"`
+}
+
+func (h *Handler) deleteSelfUser(ctx echo.Context) error {
+	var req deleteSelfUserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	err := h.services.User.Delete(ctx.Request().Context(), user.ID, req.Password)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidCredentials):
+			return h.newAuthErrorResponse(ctx, http.StatusForbidden, err)
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type suspendUserRequest struct {
+	Until   time.Time `json:"until"`
+	Comment string    `json:"comment"`
+}
+
+func (h *Handler) suspendUser(ctx echo.Context) error {
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
+	}
+
+	var req suspendUserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	admin, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	err = h.services.Moderation.Suspend(ctx.Request().Context(), admin, userID, req.Until, req.Comment)
+	if err != nil {
+		return h.suspensionErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type unsuspendUserRequest struct {
+	Comment string `json:"comment"`
+}
+
+func (h *Handler) unsuspendUser(ctx echo.Context) error {
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid user id"))
+	}
+
+	var req unsuspendUserRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	admin, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("failed to get user from context"))
+	}
+
+	err = h.services.Moderation.Unsuspend(ctx.Request().Context(), admin, userID, req.Comment)
+	if err != nil {
+		return h.suspensionErrorResponse(ctx, err)
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+func (h *Handler) suspensionErrorResponse(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, models.ErrUserNotFound):
+		return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrInvalidSuspend),
+		errors.Is(err, models.ErrCannotBanAdmin),
+		errors.Is(err, models.ErrUserDeleted),
+		errors.Is(err, models.ErrNotSuspended):
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	default:
+		return h.newAppErrorResponse(ctx, err)
+	}
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"spsu-chat/internal/models"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createWebhookRequest struct {
+	URL string `json:"url"`
+}
+
+func (h *Handler) createWebhook(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	var req createWebhookRequest
+	if err := ctx.Bind(&req); err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid input"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	input, err := models.NewCreateWebhookInput(chatID, req.URL)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, err)
+	}
+
+	credentials, err := h.services.Webhook.Create(ctx.Request().Context(), user, input)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusCreated, credentials)
+
+	return nil
+}
+
+type getAllWebhooksResponse struct {
+	Webhooks []models.Webhook `json:"webhooks"`
+}
+
+func (h *Handler) getAllWebhooks(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	webhooks, err := h.services.Webhook.GetAll(ctx.Request().Context(), user, chatID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getAllWebhooksResponse{Webhooks: webhooks})
+
+	return nil
+}
+
+func (h *Handler) deleteWebhook(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+	webhookID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid webhook id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	err = h.services.Webhook.Delete(ctx.Request().Context(), user, chatID, webhookID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrWebhookNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.NoContent(http.StatusOK)
+
+	return nil
+}
+
+type getWebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Pagination models.FullPagination    `json:"pagination"`
+}
+
+func (h *Handler) getWebhookDeliveries(ctx echo.Context) error {
+	chatID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid chat id"))
+	}
+	webhookID, err := strconv.ParseInt(ctx.Param("webhook_id"), 10, 64)
+	if err != nil {
+		return h.newValidationErrorResponse(ctx, http.StatusBadRequest, errors.New("invalid webhook id"))
+	}
+
+	user, ok := ctx.Get("user").(models.User)
+	if !ok {
+		return h.newAppErrorResponse(ctx, errors.New("invalid user in context"))
+	}
+
+	reqPagination, err := getPaginationFromContext(ctx)
+	if err != nil {
+		return h.newAppErrorResponse(ctx, err)
+	}
+
+	deliveries, pagination, err := h.services.Webhook.GetDeliveries(ctx.Request().Context(), user, chatID, webhookID, reqPagination)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrChatNotFound), errors.Is(err, models.ErrWebhookNotFound):
+			return h.newErrorResponse(ctx, http.StatusNotFound, err.Error())
+		case errors.Is(err, models.ErrChatAccessDenied):
+			return h.newErrorResponse(ctx, http.StatusForbidden, err.Error())
+		default:
+			return h.newAppErrorResponse(ctx, err)
+		}
+	}
+
+	ctx.JSON(http.StatusOK, getWebhookDeliveriesResponse{Deliveries: deliveries, Pagination: pagination})
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Attachment struct {
+	ID        int64     `db:"id" json:"id"`
+	MessageID int64     `db:"message_id" json:"message_id"`
+	FileID    uuid.UUID `db:"file_id" json:"-"`
+	Filename  string    `db:"filename" json:"filename"`
+	URL       string    `db:"url" json:"url"`
+	Size      int64     `db:"size" json:"size"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type CreateAttachmentRecord struct {
+	FileID    uuid.UUID
+	Filename  string
+	URL       string
+	Size      int64
+	CreatedAt time.Time
+}
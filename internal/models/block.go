@@ -0,0 +1,18 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrCannotBlockSelf = errors.New("you can not block yourself")
+	ErrUserNotBlocked  = errors.New("user is not blocked")
+	ErrBlockedByUser   = errors.New("user has blocked you")
+)
+
+type CreateUserBlockRecord struct {
+	BlockerID int64
+	BlockedID int64
+	CreatedAt time.Time
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	MaxBotUpdatesLimit   = 100
+	MaxBotUpdatesTimeout = 50 * time.Second
+	// BotUpdatesPollInterval is how often waiting requests check for messages
+	// created by other instances, local ones wake them up immediately.
+	BotUpdatesPollInterval = 10 * time.Second
+
+	maxBotUsernameLength    = 32
+	maxBotDisplayNameLength = 64
+)
+
+var (
+	ErrInvalidBotToken       = errors.New("invalid bot token")
+	ErrBotNotFound           = errors.New("bot not found")
+	ErrInvalidUpdatesLimit   = errors.New("updates limit must be between 1 and 100")
+	ErrInvalidUpdatesTimeout = errors.New("updates timeout must be between 0 and 50 seconds")
+	ErrInvalidBotUsername    = errors.New("bot username must be from 4 to 32 latin letters, digits or underscores")
+	ErrInvalidBotDisplayName = errors.New("bot display name must be from 1 to 64 characters")
+)
+
+var botUsernameRe = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+type BotToken struct {
+	ID        int64      `db:"id" json:"id"`
+	BotID     int64      `db:"bot_id" json:"bot_id"`
+	TokenHash []byte     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// BotCredentials is returned only once, when bot is created or its token is rotated.
+type BotCredentials struct {
+	Bot   User   `json:"bot"`
+	Token string `json:"token"`
+}
+
+type CreateBotInput struct {
+	Username    string
+	DisplayName *string
+}
+
+func NewCreateBotInput(username string, displayName *string) (CreateBotInput, error) {
+	if len(username) < minUsernameLength || len(username) > maxBotUsernameLength || !botUsernameRe.MatchString(username) {
+		return CreateBotInput{}, ErrInvalidBotUsername
+	}
+	if displayName != nil {
+		name := strings.TrimSpace(*displayName)
+		if name == "" || utf8.RuneCountInString(name) > maxBotDisplayNameLength {
+			return CreateBotInput{}, ErrInvalidBotDisplayName
+		}
+		displayName = &name
+	}
+
+	return CreateBotInput{
+		Username:    username,
+		DisplayName: displayName,
+	}, nil
+}
+
+type CreateBotTokenRecord struct {
+	BotID     int64
+	TokenHash []byte
+	CreatedAt time.Time
+}
+
+// BotUpdates are new messages from the bot chats, events are pushed only while
+// the bot waits for updates.
+type BotUpdates struct {
+	Messages []Message   `json:"messages"`
+	Events   []ChatEvent `json:"events"`
+}
+
+type GetBotUpdatesInput struct {
+	Offset  int64
+	Limit   uint64
+	Timeout time.Duration
+}
+
+func NewGetBotUpdatesInput(offset int64, limit uint64, timeout time.Duration) (GetBotUpdatesInput, error) {
+	if limit == 0 || limit > MaxBotUpdatesLimit {
+		return GetBotUpdatesInput{}, ErrInvalidUpdatesLimit
+	}
+	if timeout < 0 || timeout > MaxBotUpdatesTimeout {
+		return GetBotUpdatesInput{}, ErrInvalidUpdatesTimeout
+	}
+
+	return GetBotUpdatesInput{
+		Offset:  offset,
+		Limit:   limit,
+		Timeout: timeout,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"spsu-chat/pkg/slug"
+	"strings"
+	"time"
+)
+
+const (
+	MaxCategoryNameLength = 64
+	MaxChatCategories     = 5
+)
+
+var (
+	ErrCategoryNotFound      = errors.New("category not found")
+	ErrCategoryExists        = errors.New("category already exists")
+	ErrInvalidCategoryName   = errors.New("category name is empty or too long")
+	ErrTooManyChatCategories = errors.New("chat can have at most 5 categories")
+)
+
+// Category is a tag assigned to chats by admins to group them in discovery.
+type Category struct {
+	ID        int64     `db:"id" json:"id"`
+	Name      string    `db:"name" json:"name"`
+	Slug      string    `db:"slug" json:"slug"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type ChatCategory struct {
+	ChatID int64 `db:"chat_id"`
+	Category
+}
+
+// INPUT MODELS
+type CreateCategoryInput struct {
+	Name string
+	Slug string
+}
+
+func NewCreateCategoryInput(name string) (CreateCategoryInput, error) {
+	name = strings.TrimSpace(name)
+	if len([]rune(name)) > MaxCategoryNameLength {
+		return CreateCategoryInput{}, ErrInvalidCategoryName
+	}
+	categorySlug := slug.GenerateSlug(name)
+	if categorySlug == "" {
+		return CreateCategoryInput{}, ErrInvalidCategoryName
+	}
+
+	return CreateCategoryInput{
+		Name: name,
+		Slug: categorySlug,
+	}, nil
+}
+
+// NewChatCategoryIDs validates categories of the chat, empty list removes all categories.
+func NewChatCategoryIDs(ids []int64) ([]int64, error) {
+	unique := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if !slices.Contains(unique, id) {
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxChatCategories {
+		return nil, ErrTooManyChatCategories
+	}
+
+	return unique, nil
+}
+
+// RECORD MODELS
+type CreateCategoryRecord struct {
+	Name      string
+	Slug      string
+	CreatedAt time.Time
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -9,20 +11,62 @@ const (
 	ChatTypePublic = iota
 	ChatTypePrivate
 
-	MinChatNameLength = 5
+	MinChatNameLength  = 5
+	MaxChatTopicLength = 256
+	MaxRetentionDays   = 3650
+	MaxSlowModeSeconds = 3600
+	// MaxChatSearchLength limits length of the chats search query.
+	MaxChatSearchLength = 100
+)
+
+const (
+	ChatRoleMember = iota
+	ChatRoleModerator
 )
 
 var (
-	ErrChatNotFound      = errors.New("chat not found")
-	ErrChatNameTooShort  = errors.New("chat name is too short")
-	ErrChatNotPrivate    = errors.New("chat is not private")
-	ErrChatWrongPassword = errors.New("wrong chat password")
-	ErrChatAlreadyJoined = errors.New("you are already joined this chat")
-	ErrChatNotJoined     = errors.New("you are not joined this chat")
+	ErrChatNotFound       = errors.New("chat not found")
+	ErrChatNameTooShort   = errors.New("chat name is too short")
+	ErrChatNotPrivate     = errors.New("chat is not private")
+	ErrChatWrongPassword  = errors.New("wrong chat password")
+	ErrChatAlreadyJoined  = errors.New("you are already joined this chat")
+	ErrChatNotJoined      = errors.New("you are not joined this chat")
+	ErrChatTopicTooLong   = errors.New("chat topic is too long")
+	ErrChatMemberNotFound = errors.New("user is not a member of this chat")
+	ErrInvalidRetention   = errors.New("retention period is out of range")
+	ErrInvalidSlowMode    = errors.New("slow mode interval is out of range")
+	ErrChatSearchTooLong  = errors.New("search query is too long")
+	ErrInvalidChatType    = errors.New("invalid chat type")
+	ErrInvalidChatSort    = errors.New("invalid chat sort")
+)
+
+const (
+	// ChatSortCreated returns the newest chats first.
+	ChatSortCreated ChatSort = "created"
+	// ChatSortActivity returns chats with the latest messages first.
+	ChatSortActivity ChatSort = "activity"
+	// ChatSortMembers returns chats with the most members first.
+	ChatSortMembers ChatSort = "members"
+)
+
+var (
+	chatSorts = []ChatSort{
+		ChatSortCreated,
+		ChatSortActivity,
+		ChatSortMembers,
+	}
+	chatTypeNames = map[string]ChatType{
+		"public":  ChatTypePublic,
+		"private": ChatTypePrivate,
+	}
 )
 
 type ChatType int8
 
+type ChatRole int8
+
+type ChatSort string
+
 type Chat struct {
 	ID           int64     `db:"id" json:"id"`
 	Name         string    `db:"name" json:"name"`
@@ -30,6 +74,45 @@ type Chat struct {
 	Type         ChatType  `db:"type" json:"type"`
 	PasswordHash []byte    `db:"password_hash" json:"-"`
 	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	Topic        string    `db:"topic" json:"topic"`
+	// RetentionDays is how long messages are kept in the chat, they are kept forever if nil.
+	RetentionDays *int `db:"retention_days" json:"retention_days"`
+	// ForwardingDisabled forbids forwarding messages out of the private chat.
+	ForwardingDisabled bool `db:"forwarding_disabled" json:"forwarding_disabled"`
+	// SlowModeSeconds is the minimum interval between messages of a member, zero disables slow mode.
+	SlowModeSeconds int `db:"slow_mode_seconds" json:"slow_mode_seconds"`
+	// MemberCount and LastMessageAt are set when chats are listed.
+	MemberCount   *uint64    `db:"member_count" json:"member_count,omitempty"`
+	LastMessageAt *time.Time `db:"last_message_at" json:"last_message_at,omitempty"`
+	Categories    []Category `db:"-" json:"categories,omitempty"`
+	// Draft of the user chats are returned to.
+	Draft *Draft `db:"-" json:"draft,omitempty"`
+}
+
+// RetentionCutoff returns the time messages created before are expired by now.
+func (c Chat) RetentionCutoff(now time.Time) *time.Time {
+	if c.RetentionDays == nil {
+		return nil
+	}
+
+	cutoff := now.AddDate(0, 0, -*c.RetentionDays)
+	return &cutoff
+}
+
+type ChatMember struct {
+	ChatID int64    `db:"chat_id" json:"chat_id"`
+	UserID int64    `db:"user_id" json:"user_id"`
+	Role   ChatRole `db:"role" json:"role"`
+}
+
+// CanModerateChat reports whether user can moderate the chat: global admins, chat creator
+// and members with moderator role can. Member is nil if user has not joined the chat.
+func CanModerateChat(user User, chat Chat, member *ChatMember) bool {
+	if user.Type == UserTypeAdmin || chat.CreatorID == user.ID {
+		return true
+	}
+
+	return member != nil && member.Role == ChatRoleModerator
 }
 
 type CreateChatInput struct {
@@ -56,3 +139,66 @@ type CreateChatRecord struct {
 	PasswordHash []byte
 	CreatedAt    time.Time
 }
+
+// UPDATE MODELS
+
+// UpdateChatSettingsInput changes chat settings, nil fields are left unchanged.
+type UpdateChatSettingsInput struct {
+	// RetentionDays equal to zero disables retention.
+	RetentionDays      *int
+	ForwardingDisabled *bool
+	// SlowModeSeconds equal to zero disables slow mode.
+	SlowModeSeconds *int
+}
+
+func NewUpdateChatSettingsInput(retentionDays *int, forwardingDisabled *bool, slowModeSeconds *int) (UpdateChatSettingsInput, error) {
+	if retentionDays != nil && (*retentionDays < 0 || *retentionDays > MaxRetentionDays) {
+		return UpdateChatSettingsInput{}, ErrInvalidRetention
+	}
+	if slowModeSeconds != nil && (*slowModeSeconds < 0 || *slowModeSeconds > MaxSlowModeSeconds) {
+		return UpdateChatSettingsInput{}, ErrInvalidSlowMode
+	}
+
+	return UpdateChatSettingsInput{
+		RetentionDays:      retentionDays,
+		ForwardingDisabled: forwardingDisabled,
+		SlowModeSeconds:    slowModeSeconds,
+	}, nil
+}
+
+// FILTER MODELS
+type GetChatsFilters struct {
+	// Query searches chats by name ignoring case.
+	Query string `query:"q"`
+	// Type is public or private, chats of all types are returned if empty.
+	Type string `query:"type"`
+	// Joined returns only chats the user is a member of.
+	Joined bool `query:"joined"`
+	// Category is slug of the category.
+	Category string `query:"category"`
+	// Sort orders chats, search results are ordered by similarity to the query
+	// and other chats by id if it is empty.
+	Sort ChatSort `query:"sort"`
+	// UserID is set by service, it is not bound from request.
+	UserID int64
+}
+
+func (f GetChatsFilters) Validate() error {
+	if len([]rune(strings.TrimSpace(f.Query))) > MaxChatSearchLength {
+		return ErrChatSearchTooLong
+	}
+	if _, ok := chatTypeNames[f.Type]; f.Type != "" && !ok {
+		return ErrInvalidChatType
+	}
+	if f.Sort != "" && !slices.Contains(chatSorts, f.Sort) {
+		return ErrInvalidChatSort
+	}
+
+	return nil
+}
+
+// ChatType returns type to filter chats by, ok is false if chats of all types are requested.
+func (f GetChatsFilters) ChatType() (ChatType, bool) {
+	chatType, ok := chatTypeNames[f.Type]
+	return chatType, ok
+}
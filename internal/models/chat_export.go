@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	ChatExportFormatJSON ChatExportFormat = "json"
+	ChatExportFormatHTML ChatExportFormat = "html"
+	ChatExportFormatText ChatExportFormat = "txt"
+)
+
+var (
+	ErrInvalidExportFormat = errors.New("invalid export format")
+)
+
+type ChatExportFormat string
+
+// NewChatExportFormat validates requested format, JSON is used if it is empty.
+func NewChatExportFormat(format string) (ChatExportFormat, error) {
+	switch ChatExportFormat(format) {
+	case "":
+		return ChatExportFormatJSON, nil
+	case ChatExportFormatJSON, ChatExportFormatHTML, ChatExportFormatText:
+		return ChatExportFormat(format), nil
+	default:
+		return "", ErrInvalidExportFormat
+	}
+}
+
+// ChatExportMessage is a message of the chat history export.
+type ChatExportMessage struct {
+	ID         int64     `db:"id" json:"id"`
+	SenderID   int64     `db:"user_id" json:"sender_id"`
+	SenderName string    `db:"sender_name" json:"sender_name"`
+	Text       string    `db:"text" json:"text"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+)
+
+const (
+	MaxBotCommands                 = 100
+	MaxBotCommandDescriptionLength = 256
+)
+
+var (
+	ErrUnknownCommand       = errors.New("unknown command, see /help")
+	ErrInvalidCommandArgs   = errors.New("invalid command arguments")
+	ErrCommandAccessDenied  = errors.New("you have no rights to use this command")
+	ErrInvalidBotCommand    = errors.New("command name must match ^[a-z][a-z0-9_]{0,31}$ and description must be at most 256 characters")
+	ErrTooManyBotCommands   = errors.New("bot can have at most 100 commands")
+	ErrDuplicatedBotCommand = errors.New("duplicated bot command")
+
+	botCommandNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+)
+
+// BotCommand is a command registered by bot. When it is used in chat the bot has joined,
+// the message is posted as is, and bot handles it by itself.
+type BotCommand struct {
+	BotID       int64  `db:"bot_id" json:"-"`
+	Name        string `db:"name" json:"name"`
+	Description string `db:"description" json:"description"`
+}
+
+func NewSetBotCommandsInput(botID int64, commands []BotCommand) ([]BotCommand, error) {
+	if len(commands) > MaxBotCommands {
+		return nil, ErrTooManyBotCommands
+	}
+
+	names := make(map[string]struct{}, len(commands))
+	input := make([]BotCommand, 0, len(commands))
+	for _, command := range commands {
+		if !botCommandNameRegexp.MatchString(command.Name) ||
+			len([]rune(command.Description)) > MaxBotCommandDescriptionLength {
+			return nil, ErrInvalidBotCommand
+		}
+		if _, ok := names[command.Name]; ok {
+			return nil, ErrDuplicatedBotCommand
+		}
+		names[command.Name] = struct{}{}
+
+		input = append(input, BotCommand{
+			BotID:       botID,
+			Name:        command.Name,
+			Description: command.Description,
+		})
+	}
+
+	return input, nil
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const MaxDraftLength = 4096
+
+var (
+	ErrDraftNotFound = errors.New("draft not found")
+	ErrEmptyDraft    = errors.New("draft text is empty")
+	ErrDraftTooLong  = errors.New("draft text is too long")
+)
+
+// Draft is unsent message text of the user in the chat, it is shared by all
+// user devices. UpdatedAt lets clients pick the latest draft.
+type Draft struct {
+	UserID    int64     `db:"user_id" json:"-"`
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Text      string    `db:"text" json:"text"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// INPUT MODELS
+type SaveDraftInput struct {
+	UserID int64
+	ChatID int64
+	Text   string
+}
+
+func NewSaveDraftInput(userID, chatID int64, text string) (SaveDraftInput, error) {
+	if strings.TrimSpace(text) == "" {
+		return SaveDraftInput{}, ErrEmptyDraft
+	}
+	if utf8.RuneCountInString(text) > MaxDraftLength {
+		return SaveDraftInput{}, ErrDraftTooLong
+	}
+
+	return SaveDraftInput{
+		UserID: userID,
+		ChatID: chatID,
+		Text:   text,
+	}, nil
+}
+
+// RECORD MODELS
+type SaveDraftRecord struct {
+	UserID    int64
+	ChatID    int64
+	Text      string
+	UpdatedAt time.Time
+}
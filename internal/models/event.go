@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+type ChatEventType string
+
+const (
+	ChatEventMessageCreated ChatEventType = "message.created"
+	ChatEventMessageDeleted ChatEventType = "message.deleted"
+	ChatEventMessageUpdated ChatEventType = "message.updated"
+	ChatEventMemberJoined   ChatEventType = "member.joined"
+	ChatEventMemberLeft     ChatEventType = "member.left"
+	// ChatEventTyping is pushed to waiting clients only, it is not stored or
+	// delivered to webhooks.
+	ChatEventTyping ChatEventType = "typing"
+)
+
+// ChatEvent is something that happened in a chat and may be interesting for subscribers.
+type ChatEvent struct {
+	Type      ChatEventType `json:"event"`
+	ChatID    int64         `json:"chat_id"`
+	CreatedAt time.Time     `json:"created_at"`
+	Data      interface{}   `json:"data"`
+}
+
+type MemberEventData struct {
+	UserID int64 `json:"user_id"`
+}
+
+type TypingEventData struct {
+	UserID int64 `json:"user_id"`
+	// ExpiresAt is when the user stops being shown as typing unless the signal is repeated.
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func NewMessageEvent(eventType ChatEventType, message Message, createdAt time.Time) ChatEvent {
+	return ChatEvent{
+		Type:      eventType,
+		ChatID:    message.ChatID,
+		CreatedAt: createdAt,
+		Data:      message,
+	}
+}
+
+func NewMemberEvent(eventType ChatEventType, chatID int64, userID int64, createdAt time.Time) ChatEvent {
+	return ChatEvent{
+		Type:      eventType,
+		ChatID:    chatID,
+		CreatedAt: createdAt,
+		Data:      MemberEventData{UserID: userID},
+	}
+}
+
+func NewTypingEvent(chatID int64, userID int64, expiresAt time.Time, createdAt time.Time) ChatEvent {
+	return ChatEvent{
+		Type:      ChatEventTyping,
+		ChatID:    chatID,
+		CreatedAt: createdAt,
+		Data:      TypingEventData{UserID: userID, ExpiresAt: expiresAt},
+	}
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DataExportPending = iota
+	DataExportReady
+	DataExportFailed
+	DataExportExpired
+)
+
+var (
+	ErrExportNotFound   = errors.New("export not found")
+	ErrExportInProgress = errors.New("export is already in progress")
+	ErrExportNotReady   = errors.New("export is not ready")
+)
+
+type DataExportStatus int8
+
+// DataExport is an archive of all user data built in background.
+type DataExport struct {
+	ID          int64            `db:"id" json:"id"`
+	UserID      int64            `db:"user_id" json:"user_id"`
+	Status      DataExportStatus `db:"status" json:"status"`
+	FileID      *uuid.UUID       `db:"file_id" json:"-"`
+	Error       *string          `db:"error" json:"error,omitempty"`
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+	CompletedAt *time.Time       `db:"completed_at" json:"completed_at"`
+	ExpiresAt   *time.Time       `db:"expires_at" json:"expires_at"`
+}
+
+type UpdateDataExportRecord struct {
+	ID          int64
+	Status      DataExportStatus
+	FileID      *uuid.UUID
+	Error       *string
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// ChatMembership is a chat joined by the user.
+type ChatMembership struct {
+	ChatID   int64    `db:"chat_id" json:"chat_id"`
+	ChatName string   `db:"chat_name" json:"chat_name"`
+	Role     ChatRole `db:"role" json:"role"`
+}
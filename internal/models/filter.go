@@ -0,0 +1,236 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"spsu-chat/pkg/wordfilter"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// FilterRuleWord bans a word with all its forms, pattern ending with
+	// wordfilter.PrefixWildcard bans all words starting with it.
+	FilterRuleWord FilterRuleKind = "word"
+	// FilterRuleLinkDeny bans links to the domain and its subdomains, "*" bans all links.
+	FilterRuleLinkDeny FilterRuleKind = "link_deny"
+	// FilterRuleLinkAllow allows links to the domain and its subdomains even if they are denied.
+	FilterRuleLinkAllow FilterRuleKind = "link_allow"
+
+	// spam heuristics, they have no stored rules and are configured globally
+
+	FilterRuleRepeat FilterRuleKind = "repeat"
+	FilterRuleFlood  FilterRuleKind = "flood"
+)
+
+const (
+	FilterActionBlock FilterAction = "block"
+	// FilterActionMask replaces matched text with asterisks, masked links lose their formatting.
+	FilterActionMask FilterAction = "mask"
+	// FilterActionFlag posts the message as is, the hit is only logged for review.
+	FilterActionFlag FilterAction = "flag"
+)
+
+const (
+	MaxFilterPatternLength = 64
+	// LinkPatternAny matches links to any domain.
+	LinkPatternAny = "*"
+)
+
+var (
+	ErrMessageBlocked        = errors.New("message is blocked by content filter")
+	ErrFilterRuleNotFound    = errors.New("filter rule not found")
+	ErrFilterRuleExists      = errors.New("filter rule already exists")
+	ErrInvalidFilterRuleKind = errors.New("invalid filter rule kind")
+	ErrInvalidFilterAction   = errors.New("invalid filter action")
+	ErrInvalidFilterPattern  = errors.New("filter pattern must be a single word or a domain")
+)
+
+var (
+	filterRuleKinds = []FilterRuleKind{
+		FilterRuleWord,
+		FilterRuleLinkDeny,
+		FilterRuleLinkAllow,
+	}
+	filterActions = []FilterAction{
+		FilterActionBlock,
+		FilterActionMask,
+		FilterActionFlag,
+	}
+)
+
+var domainRe = regexp.MustCompile(`^[\p{L}\p{N}-]+(\.[\p{L}\p{N}-]+)*$`)
+
+type FilterRuleKind string
+
+type FilterAction string
+
+func (a FilterAction) IsValid() bool {
+	return slices.Contains(filterActions, a)
+}
+
+type FilterRule struct {
+	ID int64 `db:"id" json:"id"`
+	// ChatID is nil for global rules.
+	ChatID    *int64         `db:"chat_id" json:"chat_id"`
+	Kind      FilterRuleKind `db:"kind" json:"kind"`
+	Pattern   string         `db:"pattern" json:"pattern"`
+	Action    FilterAction   `db:"action" json:"action"`
+	CreatedBy *int64         `db:"created_by" json:"created_by"`
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+}
+
+// MatchesHost reports whether link rule covers the host or its parent domain.
+func (r FilterRule) MatchesHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return r.Pattern == LinkPatternAny || host == r.Pattern || strings.HasSuffix(host, "."+r.Pattern)
+}
+
+// FilterHit is a log entry of a message matched by the filter rule or spam heuristic.
+type FilterHit struct {
+	ID     int64  `db:"id" json:"id"`
+	RuleID *int64 `db:"rule_id" json:"rule_id"`
+	ChatID int64  `db:"chat_id" json:"chat_id"`
+	UserID int64  `db:"user_id" json:"user_id"`
+	// MessageID is nil if the message was blocked.
+	MessageID *int64         `db:"message_id" json:"message_id"`
+	Kind      FilterRuleKind `db:"kind" json:"kind"`
+	Action    FilterAction   `db:"action" json:"action"`
+	// Match is the matched word or link.
+	Match string `db:"match" json:"match"`
+	// Text is the message text before masking.
+	Text      string    `db:"text" json:"text"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// FilterMatch is a rule matched by the message being sent.
+type FilterMatch struct {
+	RuleID *int64
+	Kind   FilterRuleKind
+	Action FilterAction
+	Match  string
+}
+
+// FilterResult is the message text after applying filter rules.
+type FilterResult struct {
+	ChatID   int64
+	SenderID int64
+	// Source is the text before masking.
+	Source   string
+	Text     string
+	Entities MessageEntities
+	Matches  []FilterMatch
+	// Blocked is set if any matched rule blocks the message.
+	Blocked bool
+}
+
+func (r *FilterResult) Add(match FilterMatch) {
+	r.Matches = append(r.Matches, match)
+	if match.Action == FilterActionBlock {
+		r.Blocked = true
+	}
+}
+
+// RecentMessagesStats counts recent messages of the user in the chat for spam heuristics.
+type RecentMessagesStats struct {
+	// Messages is how many messages were sent since MessagesSince.
+	Messages uint64 `db:"messages"`
+	// Repeated is how many messages with the same text were sent since RepeatedSince.
+	Repeated uint64 `db:"repeated"`
+}
+
+// INPUT MODELS
+type CreateFilterRuleInput struct {
+	ChatID    *int64
+	Kind      FilterRuleKind
+	Pattern   string
+	Action    FilterAction
+	CreatedBy int64
+}
+
+// NewCreateFilterRuleInput validates the rule, patterns are stored in lower case.
+func NewCreateFilterRuleInput(chatID *int64, createdBy int64, kind FilterRuleKind, pattern string, action FilterAction) (CreateFilterRuleInput, error) {
+	if !slices.Contains(filterRuleKinds, kind) {
+		return CreateFilterRuleInput{}, ErrInvalidFilterRuleKind
+	}
+
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if pattern == "" || len([]rune(pattern)) > MaxFilterPatternLength {
+		return CreateFilterRuleInput{}, ErrInvalidFilterPattern
+	}
+
+	switch kind {
+	case FilterRuleWord:
+		word := strings.TrimSuffix(pattern, wordfilter.PrefixWildcard)
+		if strings.IndexFunc(word, unicode.IsSpace) >= 0 || wordfilter.Normalize(word) == "" {
+			return CreateFilterRuleInput{}, ErrInvalidFilterPattern
+		}
+	default:
+		pattern = strings.TrimSuffix(pattern, ".")
+		if pattern != LinkPatternAny && !domainRe.MatchString(pattern) {
+			return CreateFilterRuleInput{}, ErrInvalidFilterPattern
+		}
+	}
+
+	// allowed links are not filtered, so they have no action
+	if kind == FilterRuleLinkAllow {
+		action = ""
+	} else if !action.IsValid() {
+		return CreateFilterRuleInput{}, ErrInvalidFilterAction
+	}
+
+	return CreateFilterRuleInput{
+		ChatID:    chatID,
+		Kind:      kind,
+		Pattern:   pattern,
+		Action:    action,
+		CreatedBy: createdBy,
+	}, nil
+}
+
+// RECORD MODELS
+type CreateFilterRuleRecord struct {
+	ChatID    *int64
+	Kind      FilterRuleKind
+	Pattern   string
+	Action    FilterAction
+	CreatedBy int64
+	CreatedAt time.Time
+}
+
+type CreateFilterHitRecord struct {
+	RuleID    *int64
+	ChatID    int64
+	UserID    int64
+	MessageID *int64
+	Kind      FilterRuleKind
+	Action    FilterAction
+	Match     string
+	Text      string
+	CreatedAt time.Time
+}
+
+type GetRecentMessagesStatsRecord struct {
+	ChatID        int64
+	SenderID      int64
+	Text          string
+	MessagesSince time.Time
+	RepeatedSince time.Time
+}
+
+// FILTER MODELS
+type GetFilterHitsFilters struct {
+	// ChatID filters hits by chat, hits of all chats are returned if zero.
+	ChatID int64        `query:"chat_id"`
+	Action FilterAction `query:"action"`
+}
+
+func (f GetFilterHitsFilters) Validate() error {
+	if f.Action != "" && !f.Action.IsValid() {
+		return ErrInvalidFilterAction
+	}
+
+	return nil
+}
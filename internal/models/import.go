@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// PlaceholderUsernamePrefix is prepended to Telegram user id to get username of placeholder account.
+	PlaceholderUsernamePrefix = "tg-"
+)
+
+var (
+	ErrInvalidImportFile  = errors.New("invalid import file")
+	ErrImportFileTooLarge = errors.New("import file is too large")
+	ErrImportMappingUser  = errors.New("mapped user not found")
+	ErrPlaceholderTaken   = errors.New("placeholder username is taken by another account")
+)
+
+type ImportChatInput struct {
+	ChatID int64
+	UserID int64
+	// Mapping maps Telegram sender ids (e.g. "user123") to ids of existing users.
+	Mapping map[string]int64
+	DryRun  bool
+	// Data is either result.json or zip archive of the export directory.
+	Data []byte
+}
+
+// ImportSender describes how Telegram sender is mapped.
+type ImportSender struct {
+	TelegramID string `json:"telegram_id"`
+	Name       string `json:"name"`
+	// UserID is nil if placeholder account is going to be created.
+	UserID      *int64 `json:"user_id"`
+	Placeholder bool   `json:"placeholder"`
+	Messages    int    `json:"messages"`
+}
+
+type ImportChatResult struct {
+	DryRun      bool           `json:"dry_run"`
+	Messages    int            `json:"messages"`
+	Attachments int            `json:"attachments"`
+	Skipped     int            `json:"skipped"`
+	Senders     []ImportSender `json:"senders"`
+}
+
+type ImportMessageRecord struct {
+	// SenderKey is Telegram id of the sender, it is resolved with ImportChatRecord.Senders.
+	SenderKey   string
+	Text        string
+	CreatedAt   time.Time
+	Attachments []CreateAttachmentRecord
+}
+
+type ImportChatRecord struct {
+	ChatID int64
+	// Senders maps Telegram ids to existing users.
+	Senders map[string]int64
+	// Placeholders are accounts created within import transaction for unmapped senders.
+	Placeholders map[string]CreateUserRecord
+	Messages     []ImportMessageRecord
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	MaxIncomingWebhookNameLength = 64
+)
+
+var (
+	ErrIncomingWebhookNotFound    = errors.New("incoming webhook not found")
+	ErrInvalidIncomingWebhookName = errors.New("incoming webhook name must be from 1 to 64 characters")
+)
+
+type IncomingWebhook struct {
+	ID        int64 `db:"id" json:"id"`
+	ChatID    int64 `db:"chat_id" json:"chat_id"`
+	CreatorID int64 `db:"creator_id" json:"creator_id"`
+	// UserID is integration identity which messages are sent from.
+	UserID    int64      `db:"user_id" json:"user_id"`
+	Name      string     `db:"name" json:"name"`
+	TokenHash []byte     `db:"token_hash" json:"-"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revoked_at"`
+}
+
+// IncomingWebhookCredentials is returned only once, when webhook is created.
+type IncomingWebhookCredentials struct {
+	Webhook IncomingWebhook `json:"webhook"`
+	Token   string          `json:"token"`
+}
+
+type CreateIncomingWebhookInput struct {
+	ChatID int64
+	Name   string
+}
+
+func NewCreateIncomingWebhookInput(chatID int64, name string) (CreateIncomingWebhookInput, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxIncomingWebhookNameLength {
+		return CreateIncomingWebhookInput{}, ErrInvalidIncomingWebhookName
+	}
+
+	return CreateIncomingWebhookInput{
+		ChatID: chatID,
+		Name:   name,
+	}, nil
+}
+
+type CreateIncomingWebhookRecord struct {
+	ChatID    int64
+	CreatorID int64
+	// Integration is the identity webhook posts as, it is created and joined
+	// to the chat together with the webhook.
+	Integration CreateUserRecord
+	Name        string
+	TokenHash   []byte
+	CreatedAt   time.Time
+}
+
+type IncomingWebhookMessageInput struct {
+	Text        string
+	DisplayName *string
+}
+
+func NewIncomingWebhookMessageInput(text string, displayName *string) (IncomingWebhookMessageInput, error) {
+	if strings.TrimSpace(text) == "" {
+		return IncomingWebhookMessageInput{}, ErrEmptyMessageText
+	}
+	if displayName != nil && len([]rune(*displayName)) > MaxSenderNameLength {
+		return IncomingWebhookMessageInput{}, ErrSenderNameTooLong
+	}
+
+	return IncomingWebhookMessageInput{
+		Text:        text,
+		DisplayName: displayName,
+	}, nil
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// LinkPreview is OpenGraph metadata of the first link in the message, it is
+// cached per URL.
+type LinkPreview struct {
+	URL         string    `db:"url" json:"url"`
+	Title       string    `db:"title" json:"title,omitempty"`
+	Description string    `db:"description" json:"description,omitempty"`
+	ImageURL    string    `db:"image_url" json:"image_url,omitempty"`
+	SiteName    string    `db:"site_name" json:"site_name,omitempty"`
+	Failed      bool      `db:"failed" json:"-"`
+	FetchedAt   time.Time `db:"fetched_at" json:"-"`
+}
+
+// IsFresh reports whether cached preview can be used instead of fetching the page again.
+func (p LinkPreview) IsFresh(now time.Time, ttl time.Duration) bool {
+	return now.Before(p.FetchedAt.Add(ttl))
+}
+
+type SaveLinkPreviewRecord struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+	Failed      bool
+	FetchedAt   time.Time
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	MentionKindUser = iota
+	MentionKindAll
+	MentionKindHere
+)
+
+type MentionKind int8
+
+// Mention is an inbox entry of the mentioned user.
+type Mention struct {
+	ID        int64       `db:"id" json:"id"`
+	MessageID int64       `db:"message_id" json:"message_id"`
+	ChatID    int64       `db:"chat_id" json:"chat_id"`
+	UserID    int64       `db:"user_id" json:"-"`
+	Kind      MentionKind `db:"kind" json:"kind"`
+	CreatedAt time.Time   `db:"created_at" json:"created_at"`
+	ReadAt    *time.Time  `db:"read_at" json:"read_at"`
+	// message fields
+	SenderID int64  `db:"sender_id" json:"sender_id"`
+	Text     string `db:"text" json:"text"`
+}
+
+type CreateMentionRecord struct {
+	MessageID int64
+	ChatID    int64
+	UserID    int64
+	Kind      MentionKind
+	CreatedAt time.Time
+}
+
+// FILTER MODELS
+type GetMentionsFilters struct {
+	Unread bool `query:"unread"`
+	// VisibleAt is set by service, it excludes mentions in messages expired by
+	// the time or older than retention period of their chat.
+	VisibleAt time.Time
+}
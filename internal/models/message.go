@@ -1,15 +1,45 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"spsu-chat/pkg/markdown"
 	"time"
 )
 
+const (
+	MessageKindText MessageKind = iota
+	MessageKindPoll
+)
+
+const (
+	MaxSenderNameLength = 64
+
+	// MaxForwardTargets limits how many chats message can be forwarded to at once.
+	MaxForwardTargets = 10
+
+	MinMessageTTL = 5 * time.Second
+	MaxMessageTTL = 7 * 24 * time.Hour
+)
+
 var (
-	ErrNotYourMessage  = errors.New("message is not your")
-	ErrMessageNotFound = errors.New("message not found")
+	ErrNotYourMessage    = errors.New("message is not your")
+	ErrMessageNotFound   = errors.New("message not found")
+	ErrEmptyMessageText  = errors.New("message text is empty")
+	ErrSenderNameTooLong = errors.New("sender name is too long")
+	ErrRateLimited       = errors.New("too many requests")
+	ErrMessageNotDeleted = errors.New("message is not deleted")
+	ErrInvalidMessageTTL = errors.New("message ttl is out of range")
+	ErrInvalidForward    = errors.New("message must be forwarded to from 1 to 10 chats")
+	ErrForwardDisabled   = errors.New("forwarding from this chat is disabled")
+	ErrCannotForwardPoll = errors.New("polls can not be forwarded")
 )
 
+type MessageKind int8
+
 // BASE MODEL
 type Message struct {
 	ID        int64     `db:"id" json:"id"`
@@ -17,13 +47,130 @@ type Message struct {
 	SenderID  int64     `db:"user_id" json:"sender_id"`
 	Text      string    `db:"text" json:"text"`
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// SenderName overrides sender display name (used by integrations).
+	SenderName *string `db:"sender_name" json:"sender_name,omitempty"`
+	// Ephemeral messages are not stored and visible only to the user they are returned to.
+	Ephemeral bool       `db:"-" json:"ephemeral,omitempty"`
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	DeletedBy *int64     `db:"deleted_by" json:"deleted_by,omitempty"`
+	// SenderBlocked is set if the sender is blocked by the user messages are returned to.
+	SenderBlocked bool `db:"-" json:"sender_blocked,omitempty"`
+	// ExpiresAt is set for self-destructing messages.
+	ExpiresAt   *time.Time   `db:"expires_at" json:"expires_at,omitempty"`
+	Attachments []Attachment `db:"-" json:"attachments,omitempty"`
+	Kind        MessageKind  `db:"kind" json:"kind"`
+	// Poll is set for poll messages.
+	Poll *Poll `db:"-" json:"poll,omitempty"`
+	// Forward metadata is set for forwarded messages, it points to the original message.
+	ForwardedFromChatID    *int64     `db:"forwarded_from_chat_id" json:"forwarded_from_chat_id,omitempty"`
+	ForwardedFromUserID    *int64     `db:"forwarded_from_user_id" json:"forwarded_from_user_id,omitempty"`
+	ForwardedFromMessageID *int64     `db:"forwarded_from_message_id" json:"forwarded_from_message_id,omitempty"`
+	ForwardedSentAt        *time.Time `db:"forwarded_sent_at" json:"forwarded_sent_at,omitempty"`
+	// Entities format the text, offsets and lengths are in runes.
+	Entities MessageEntities `db:"entities" json:"entities,omitempty"`
+	// HTML is the text rendered with entities, it is set on request.
+	HTML string `db:"-" json:"html,omitempty"`
+	// LinkPreviewURL is set when preview of the link in the text is fetched.
+	LinkPreviewURL *string      `db:"link_preview_url" json:"-"`
+	LinkPreview    *LinkPreview `db:"-" json:"link_preview,omitempty"`
+}
+
+// MessageEntities are stored as JSONB.
+type MessageEntities []markdown.Entity
+
+func (e MessageEntities) Value() (driver.Value, error) {
+	if len(e) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(e)
+}
+
+func (e *MessageEntities) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		*e = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(src, e)
+	case string:
+		return json.Unmarshal([]byte(src), e)
+	}
+	return fmt.Errorf("unsupported message entities type %T", src)
+}
+
+func (m Message) IsForwarded() bool {
+	return m.ForwardedFromMessageID != nil
+}
+
+// ForwardOrigin returns the original message, forwarded message points to the
+// message it was forwarded from, so forwarding chains are not built.
+func (m Message) ForwardOrigin() MessageForward {
+	if m.IsForwarded() {
+		origin := MessageForward{
+			MessageID: *m.ForwardedFromMessageID,
+			SentAt:    *m.ForwardedSentAt,
+		}
+		if m.ForwardedFromChatID != nil {
+			origin.ChatID = *m.ForwardedFromChatID
+		}
+		if m.ForwardedFromUserID != nil {
+			origin.SenderID = *m.ForwardedFromUserID
+		}
+		return origin
+	}
+
+	return MessageForward{
+		ChatID:    m.ChatID,
+		SenderID:  m.SenderID,
+		MessageID: m.ID,
+		SentAt:    m.CreatedAt,
+	}
+}
+
+// MessageForward is the original message forwarded message is copied from.
+type MessageForward struct {
+	ChatID    int64
+	SenderID  int64
+	MessageID int64
+	SentAt    time.Time
+}
+
+func (m Message) IsDeleted() bool {
+	return m.DeletedAt != nil
+}
+
+// IsExpired reports whether self-destructing message is expired at the given time.
+func (m Message) IsExpired(now time.Time) bool {
+	return m.ExpiresAt != nil && !now.Before(*m.ExpiresAt)
+}
+
+// Tombstone returns deleted message without its content, so clients can show
+// that the message was deleted.
+func (m Message) Tombstone() Message {
+	m.Text = ""
+	m.Entities = nil
+	m.LinkPreviewURL = nil
+	return m
+}
+
+// NewEphemeralMessage creates system message visible only to the caller, e.g. command reply.
+func NewEphemeralMessage(chatID int64, text string, createdAt time.Time) Message {
+	return Message{
+		ChatID:    chatID,
+		Text:      text,
+		CreatedAt: createdAt,
+		Ephemeral: true,
+	}
 }
 
 // CREATE MODELS
 type CreateMessageInput struct {
-	ChatID   int64
-	SenderID int64
-	Text     string
+	ChatID     int64
+	SenderID   int64
+	Text       string
+	SenderName *string
+	// TTL is how long message lives after it is sent, zero means forever.
+	TTL time.Duration
 }
 
 func NewCreateMessageInput(chatID, senderID int64, text string) CreateMessageInput {
@@ -34,14 +181,59 @@ func NewCreateMessageInput(chatID, senderID int64, text string) CreateMessageInp
 	}
 }
 
+// NewForwardTargets validates chats message is forwarded to.
+func NewForwardTargets(chatIDs []int64) ([]int64, error) {
+	targets := slices.Clone(chatIDs)
+	slices.Sort(targets)
+	targets = slices.Compact(targets)
+	if len(targets) == 0 || len(targets) > MaxForwardTargets {
+		return nil, ErrInvalidForward
+	}
+
+	return targets, nil
+}
+
+// NewMessageTTL converts ttl in seconds, zero means message does not expire.
+func NewMessageTTL(seconds int64) (time.Duration, error) {
+	if seconds == 0 {
+		return 0, nil
+	}
+
+	ttl := time.Duration(seconds) * time.Second
+	if ttl < MinMessageTTL || ttl > MaxMessageTTL {
+		return 0, ErrInvalidMessageTTL
+	}
+
+	return ttl, nil
+}
+
 type CreateMessageRecord struct {
-	ChatID    int64
-	SenderID  int64
-	Text      string
-	CreatedAt time.Time
+	ChatID     int64
+	SenderID   int64
+	Text       string
+	SenderName *string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	Forward    *MessageForward
+	Entities   MessageEntities
 }
 
 // FILTER MODELS
 type GetMessagesFilters struct {
 	ChatID int64 `query:"chat_id"`
+	// HideBlocked excludes messages of users blocked by the caller instead of flagging them.
+	HideBlocked bool `query:"hide_blocked"`
+	// HTML renders formatted messages text as HTML.
+	HTML bool `query:"html"`
+	// ExcludeSenderIDs, VisibleAt and CreatedAfter are set by service, they are not bound from request.
+	ExcludeSenderIDs []int64
+	// VisibleAt excludes messages expired by the time.
+	VisibleAt time.Time
+	// CreatedAfter excludes messages older than chat retention period.
+	CreatedAfter *time.Time
+}
+
+type GetDeletedMessagesFilters struct {
+	// ChatID limits messages to the chat, messages of all chats are returned if empty.
+	ChatID int64 `query:"chat_id"`
 }
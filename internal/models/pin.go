@@ -0,0 +1,22 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	MaxPinnedMessages = 50
+)
+
+var (
+	ErrTooManyPinnedMessages = errors.New("too many pinned messages")
+	ErrMessageNotPinned      = errors.New("message is not pinned")
+)
+
+type CreatePinRecord struct {
+	ChatID    int64
+	MessageID int64
+	PinnedBy  int64
+	PinnedAt  time.Time
+}
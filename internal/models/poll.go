@@ -0,0 +1,156 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	MaxPollQuestionLength = 300
+	MaxPollOptionLength   = 100
+	MinPollOptions        = 2
+	MaxPollOptions        = 10
+)
+
+var (
+	ErrPollNotFound         = errors.New("poll not found")
+	ErrPollClosed           = errors.New("poll is closed")
+	ErrEmptyPollQuestion    = errors.New("poll question is empty")
+	ErrPollQuestionTooLong  = errors.New("poll question is too long")
+	ErrInvalidPollOptions   = errors.New("poll must have from 2 to 10 unique non-empty options")
+	ErrPollOptionTooLong    = errors.New("poll option is too long")
+	ErrInvalidPollCloseTime = errors.New("poll close time must be in the future")
+	ErrInvalidPollVote      = errors.New("invalid poll options chosen")
+	ErrPollSingleChoice     = errors.New("only one option can be chosen in this poll")
+	ErrPollNotVoted         = errors.New("you have not voted in this poll")
+)
+
+type Poll struct {
+	ID        int64      `db:"id" json:"id"`
+	MessageID int64      `db:"message_id" json:"message_id"`
+	Question  string     `db:"question" json:"question"`
+	Multiple  bool       `db:"multiple" json:"multiple"`
+	Anonymous bool       `db:"anonymous" json:"anonymous"`
+	ClosesAt  *time.Time `db:"closes_at" json:"closes_at"`
+	ClosedAt  *time.Time `db:"closed_at" json:"closed_at"`
+	CreatedAt time.Time  `db:"created_at" json:"created_at"`
+	// Results are filled for the user poll is returned to.
+	Closed  bool         `db:"-" json:"closed"`
+	Options []PollOption `db:"-" json:"options"`
+	Voters  int          `db:"-" json:"voters"`
+	// Voted are ids of options chosen by the user poll is returned to.
+	Voted []int64 `db:"-" json:"voted"`
+}
+
+// IsClosed reports whether poll was closed manually or its close time has come.
+func (p Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !now.Before(*p.ClosesAt))
+}
+
+type PollOption struct {
+	ID       int64  `db:"id" json:"id"`
+	PollID   int64  `db:"poll_id" json:"-"`
+	Position int16  `db:"position" json:"position"`
+	Text     string `db:"text" json:"text"`
+	Votes    int    `db:"-" json:"votes"`
+	// VoterIDs are shown for public polls only.
+	VoterIDs []int64 `db:"-" json:"voter_ids,omitempty"`
+}
+
+type PollVote struct {
+	PollID    int64     `db:"poll_id"`
+	OptionID  int64     `db:"option_id"`
+	UserID    int64     `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SetResults counts votes and hides voters of anonymous poll, viewer votes are
+// always shown to the viewer.
+func (p *Poll) SetResults(options []PollOption, votes []PollVote, viewerID int64, now time.Time) {
+	p.Closed = p.IsClosed(now)
+	p.Options = make([]PollOption, 0, len(options))
+	p.Voted = make([]int64, 0)
+
+	voters := make(map[int64]struct{})
+	for _, option := range options {
+		for _, vote := range votes {
+			if vote.OptionID != option.ID {
+				continue
+			}
+			option.Votes++
+			if !p.Anonymous {
+				option.VoterIDs = append(option.VoterIDs, vote.UserID)
+			}
+			if vote.UserID == viewerID {
+				p.Voted = append(p.Voted, option.ID)
+			}
+			voters[vote.UserID] = struct{}{}
+		}
+		p.Options = append(p.Options, option)
+	}
+	p.Voters = len(voters)
+}
+
+// CREATE MODELS
+type CreatePollInput struct {
+	ChatID    int64
+	SenderID  int64
+	Question  string
+	Options   []string
+	Multiple  bool
+	Anonymous bool
+	ClosesAt  *time.Time
+}
+
+func NewCreatePollInput(
+	chatID, senderID int64,
+	question string,
+	options []string,
+	multiple, anonymous bool,
+	closesAt *time.Time,
+) (CreatePollInput, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return CreatePollInput{}, ErrEmptyPollQuestion
+	}
+	if len([]rune(question)) > MaxPollQuestionLength {
+		return CreatePollInput{}, ErrPollQuestionTooLong
+	}
+
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return CreatePollInput{}, ErrInvalidPollOptions
+	}
+	trimmed := make([]string, 0, len(options))
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" || slices.Contains(trimmed, option) {
+			return CreatePollInput{}, ErrInvalidPollOptions
+		}
+		if len([]rune(option)) > MaxPollOptionLength {
+			return CreatePollInput{}, ErrPollOptionTooLong
+		}
+		trimmed = append(trimmed, option)
+	}
+
+	return CreatePollInput{
+		ChatID:    chatID,
+		SenderID:  senderID,
+		Question:  question,
+		Options:   trimmed,
+		Multiple:  multiple,
+		Anonymous: anonymous,
+		ClosesAt:  closesAt,
+	}, nil
+}
+
+// CreatePollRecord creates poll together with the message it is posted as.
+type CreatePollRecord struct {
+	Message   CreateMessageRecord
+	Question  string
+	Options   []string
+	Multiple  bool
+	Anonymous bool
+	ClosesAt  *time.Time
+}
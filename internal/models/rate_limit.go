@@ -0,0 +1,31 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// RateLimitError is returned when user sends messages too often, it matches ErrRateLimited.
+type RateLimitError struct {
+	// RetryAfter is how long to wait before the next message is allowed.
+	RetryAfter time.Duration
+	// SlowMode is set if the limit is the chat slow mode.
+	SlowMode bool
+}
+
+func (e RateLimitError) Error() string {
+	if e.SlowMode {
+		return fmt.Sprintf("slow mode is enabled in this chat, wait %d seconds", e.RetryAfterSeconds())
+	}
+	return fmt.Sprintf("too many messages, wait %d seconds", e.RetryAfterSeconds())
+}
+
+func (e RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RetryAfterSeconds rounds the wait time up, so retrying after it always succeeds.
+func (e RateLimitError) RetryAfterSeconds() int {
+	return int(math.Ceil(e.RetryAfter.Seconds()))
+}
@@ -0,0 +1,186 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+const (
+	ReportTargetMessage ReportTarget = "message"
+	ReportTargetUser    ReportTarget = "user"
+)
+
+const (
+	ReportReasonSpam       ReportReason = "spam"
+	ReportReasonHarassment ReportReason = "harassment"
+	ReportReasonHate       ReportReason = "hate"
+	ReportReasonViolence   ReportReason = "violence"
+	ReportReasonSexual     ReportReason = "sexual"
+	ReportReasonOther      ReportReason = "other"
+)
+
+const (
+	ReportStatusOpen      ReportStatus = "open"
+	ReportStatusResolved  ReportStatus = "resolved"
+	ReportStatusDismissed ReportStatus = "dismissed"
+)
+
+const (
+	ModerationActionDismiss       ModerationAction = "dismiss"
+	ModerationActionDeleteMessage ModerationAction = "delete_message"
+	ModerationActionBanUser       ModerationAction = "ban_user"
+	ModerationActionSuspendUser   ModerationAction = "suspend_user"
+	ModerationActionUnsuspendUser ModerationAction = "unsuspend_user"
+)
+
+const (
+	MaxReportCommentLength = 1000
+)
+
+var (
+	ErrInvalidReportReason     = errors.New("invalid report reason")
+	ErrInvalidReportStatus     = errors.New("invalid report status")
+	ErrReportCommentTooLong    = errors.New("report comment is too long")
+	ErrCannotReportSelf        = errors.New("you can not report yourself")
+	ErrReportExists            = errors.New("you have already reported it")
+	ErrReportNotFound          = errors.New("report not found")
+	ErrReportResolved          = errors.New("report is already resolved")
+	ErrInvalidModerationAction = errors.New("invalid moderation action")
+	ErrCannotBanAdmin          = errors.New("admins can not be banned or suspended")
+)
+
+var (
+	reportReasons = []ReportReason{
+		ReportReasonSpam,
+		ReportReasonHarassment,
+		ReportReasonHate,
+		ReportReasonViolence,
+		ReportReasonSexual,
+		ReportReasonOther,
+	}
+	reportStatuses = []ReportStatus{
+		ReportStatusOpen,
+		ReportStatusResolved,
+		ReportStatusDismissed,
+	}
+)
+
+type ReportTarget string
+
+type ReportReason string
+
+type ReportStatus string
+
+type ModerationAction string
+
+type Report struct {
+	ID         int64        `db:"id" json:"id"`
+	ReporterID int64        `db:"reporter_id" json:"reporter_id"`
+	TargetType ReportTarget `db:"target_type" json:"target_type"`
+	// MessageID is set for message reports, UserID is the reported user or message sender.
+	MessageID  *int64       `db:"message_id" json:"message_id"`
+	UserID     int64        `db:"user_id" json:"user_id"`
+	Reason     ReportReason `db:"reason" json:"reason"`
+	Comment    string       `db:"comment" json:"comment"`
+	Status     ReportStatus `db:"status" json:"status"`
+	CreatedAt  time.Time    `db:"created_at" json:"created_at"`
+	ResolvedAt *time.Time   `db:"resolved_at" json:"resolved_at"`
+	ResolvedBy *int64       `db:"resolved_by" json:"resolved_by"`
+}
+
+// ModerationActionRecord is an audit log entry of an admin action.
+type ModerationActionRecord struct {
+	ID              int64            `db:"id" json:"id"`
+	AdminID         int64            `db:"admin_id" json:"admin_id"`
+	Action          ModerationAction `db:"action" json:"action"`
+	ReportID        *int64           `db:"report_id" json:"report_id"`
+	TargetUserID    *int64           `db:"target_user_id" json:"target_user_id"`
+	TargetMessageID *int64           `db:"target_message_id" json:"target_message_id"`
+	Comment         string           `db:"comment" json:"comment"`
+	CreatedAt       time.Time        `db:"created_at" json:"created_at"`
+}
+
+// CREATE MODELS
+type CreateReportInput struct {
+	ReporterID int64
+	Reason     ReportReason
+	Comment    string
+}
+
+func NewCreateReportInput(reporterID int64, reason ReportReason, comment string) (CreateReportInput, error) {
+	if !slices.Contains(reportReasons, reason) {
+		return CreateReportInput{}, ErrInvalidReportReason
+	}
+	if len([]rune(comment)) > MaxReportCommentLength {
+		return CreateReportInput{}, ErrReportCommentTooLong
+	}
+
+	return CreateReportInput{
+		ReporterID: reporterID,
+		Reason:     reason,
+		Comment:    comment,
+	}, nil
+}
+
+type CreateReportRecord struct {
+	ReporterID int64
+	TargetType ReportTarget
+	MessageID  *int64
+	UserID     int64
+	Reason     ReportReason
+	Comment    string
+	CreatedAt  time.Time
+}
+
+type ResolveReportInput struct {
+	Action  ModerationAction
+	Comment string
+}
+
+func NewResolveReportInput(action ModerationAction, comment string) (ResolveReportInput, error) {
+	switch action {
+	case ModerationActionDismiss, ModerationActionDeleteMessage, ModerationActionBanUser:
+	default:
+		return ResolveReportInput{}, ErrInvalidModerationAction
+	}
+	if len([]rune(comment)) > MaxReportCommentLength {
+		return ResolveReportInput{}, ErrReportCommentTooLong
+	}
+
+	return ResolveReportInput{
+		Action:  action,
+		Comment: comment,
+	}, nil
+}
+
+type ResolveReportRecord struct {
+	ID         int64
+	Status     ReportStatus
+	ResolvedBy int64
+	ResolvedAt time.Time
+}
+
+type CreateModerationActionRecord struct {
+	AdminID         int64
+	Action          ModerationAction
+	ReportID        *int64
+	TargetUserID    *int64
+	TargetMessageID *int64
+	Comment         string
+	CreatedAt       time.Time
+}
+
+// FILTER MODELS
+type GetReportsFilters struct {
+	// Status filters reports by status, all reports are returned if empty.
+	Status ReportStatus `query:"status"`
+}
+
+func (f GetReportsFilters) Validate() error {
+	if f.Status != "" && !slices.Contains(reportStatuses, f.Status) {
+		return ErrInvalidReportStatus
+	}
+
+	return nil
+}
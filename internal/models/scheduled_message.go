@@ -0,0 +1,125 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	ScheduledMessagePending ScheduledMessageStatus = iota
+	ScheduledMessageSent
+	ScheduledMessageCanceled
+	ScheduledMessageFailed
+	// ScheduledMessageSending is set while scheduler sends the message, so other
+	// instances skip it.
+	ScheduledMessageSending
+)
+
+const (
+	// MaxScheduleAhead limits how far in the future message can be scheduled.
+	MaxScheduleAhead = 365 * 24 * time.Hour
+)
+
+var (
+	ErrScheduledMessageNotFound   = errors.New("scheduled message not found")
+	ErrScheduledMessageNotPending = errors.New("scheduled message is already sent or canceled")
+	ErrSendAtInPast               = errors.New("send time must be in the future")
+	ErrSendAtTooFar               = errors.New("send time is too far in the future")
+	ErrCannotScheduleCommand      = errors.New("commands can not be scheduled")
+)
+
+type ScheduledMessageStatus int8
+
+type ScheduledMessage struct {
+	ID       int64                  `db:"id" json:"id"`
+	ChatID   int64                  `db:"chat_id" json:"chat_id"`
+	SenderID int64                  `db:"user_id" json:"sender_id"`
+	Text     string                 `db:"text" json:"text"`
+	SendAt   time.Time              `db:"send_at" json:"send_at"`
+	Status   ScheduledMessageStatus `db:"status" json:"status"`
+	// MessageID is set once the message is sent, Error if sending failed.
+	MessageID *int64    `db:"message_id" json:"message_id"`
+	Error     *string   `db:"error" json:"error"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// CREATE MODELS
+type CreateScheduledMessageInput struct {
+	ChatID   int64
+	SenderID int64
+	Text     string
+	SendAt   time.Time
+}
+
+func NewCreateScheduledMessageInput(chatID, senderID int64, text string, sendAt time.Time) (CreateScheduledMessageInput, error) {
+	if strings.TrimSpace(text) == "" {
+		return CreateScheduledMessageInput{}, ErrEmptyMessageText
+	}
+
+	return CreateScheduledMessageInput{
+		ChatID:   chatID,
+		SenderID: senderID,
+		Text:     text,
+		SendAt:   sendAt,
+	}, nil
+}
+
+type CreateScheduledMessageRecord struct {
+	ChatID    int64
+	SenderID  int64
+	Text      string
+	SendAt    time.Time
+	CreatedAt time.Time
+}
+
+// UPDATE MODELS
+type UpdateScheduledMessageInput struct {
+	Text   string
+	SendAt time.Time
+}
+
+func NewUpdateScheduledMessageInput(text string, sendAt time.Time) (UpdateScheduledMessageInput, error) {
+	if strings.TrimSpace(text) == "" {
+		return UpdateScheduledMessageInput{}, ErrEmptyMessageText
+	}
+
+	return UpdateScheduledMessageInput{
+		Text:   text,
+		SendAt: sendAt,
+	}, nil
+}
+
+type UpdateScheduledMessageRecord struct {
+	ID        int64
+	Text      string
+	SendAt    time.Time
+	UpdatedAt time.Time
+}
+
+// SetScheduledMessageStatusRecord moves claimed message to the final status or
+// back to pending.
+type SetScheduledMessageStatusRecord struct {
+	ID        int64
+	Status    ScheduledMessageStatus
+	MessageID *int64
+	Error     *string
+	UpdatedAt time.Time
+}
+
+type GetScheduledMessagesFilters struct {
+	ChatID int64 `query:"chat_id"`
+}
+
+// ValidateSendAt checks that send time is in the future, but not too far.
+func ValidateSendAt(sendAt time.Time, now time.Time) error {
+	if !sendAt.After(now) {
+		return ErrSendAtInPast
+	}
+	if sendAt.After(now.Add(MaxScheduleAhead)) {
+		return ErrSendAtTooFar
+	}
+
+	return nil
+}
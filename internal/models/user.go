@@ -2,12 +2,28 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 )
 
 const (
 	UserTypeUser = iota
 	UserTypeAdmin
+	UserTypeBot
+	UserTypeIntegration
+	// UserTypePlaceholder is an account created for imported messages, it can not log in.
+	UserTypePlaceholder
+)
+
+const (
+	UserStatusActive = iota
+	UserStatusBanned
+	UserStatusSuspended
+	UserStatusDeleted
+)
+
+const (
+	DeletedUserDisplayName = "Deleted user"
 )
 
 const (
@@ -15,22 +31,95 @@ const (
 	minUsernameLength = 4
 )
 
+const (
+	MaxUserSearchLength    = 64
+	DefaultUserSearchLimit = 20
+	MaxUserSearchLimit     = 50
+)
+
 var (
 	ErrInvalidPassword = errors.New("invalid password")
 	ErrInvalidUsername = errors.New("invalid username")
 	ErrUsernameExists  = errors.New("username already exists")
 	ErrUserNotFound    = errors.New("user not found")
+	ErrUserBanned      = errors.New("account is banned")
+	ErrUserSuspended   = errors.New("account is suspended")
+	ErrUserDeleted     = errors.New("account is deleted")
+	ErrInvalidSuspend  = errors.New("suspension end must be in the future")
+	ErrNotSuspended    = errors.New("account is not suspended")
+	ErrInvalidSearch   = errors.New("search query must be from 1 to 64 characters")
 )
 
 type UserType int8
 
+type UserStatus int8
+
 type User struct {
-	ID           int64     `db:"id" json:"id"`
-	Username     string    `db:"username" json:"username"`
-	PasswordHash []byte    `db:"password_hash" json:"-"`
-	DisplayName  string    `db:"display_name" json:"display_name"`
-	Type         UserType  `db:"type" json:"type"`
-	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	ID           int64      `db:"id" json:"id"`
+	Username     string     `db:"username" json:"username"`
+	PasswordHash []byte     `db:"password_hash" json:"-"`
+	DisplayName  string     `db:"display_name" json:"display_name"`
+	Type         UserType   `db:"type" json:"type"`
+	Status       UserStatus `db:"status" json:"status"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	// LastSeenAt and Online are hidden from other users if HidePresence is set.
+	LastSeenAt   *time.Time `db:"last_seen_at" json:"last_seen_at,omitempty"`
+	HidePresence bool       `db:"hide_presence" json:"hide_presence,omitempty"`
+	Online       *bool      `db:"-" json:"online,omitempty"`
+	// SuspendedUntil is set for suspended accounts.
+	SuspendedUntil *time.Time `db:"suspended_until" json:"suspended_until,omitempty"`
+	DeletedAt      *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+// CheckStatus returns error if the account can not be used at the given time.
+// Suspension is over once its end has come, even if status is not updated yet.
+func (u User) CheckStatus(now time.Time) error {
+	switch u.Status {
+	case UserStatusBanned:
+		return ErrUserBanned
+	case UserStatusDeleted:
+		return ErrUserDeleted
+	case UserStatusSuspended:
+		if u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil) {
+			return ErrUserSuspended
+		}
+	}
+
+	return nil
+}
+
+// UserProfile is the user as seen by other users, moderation state of the
+// account is visible only to admins and the user.
+type UserProfile struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	DisplayName  string     `json:"display_name"`
+	Type         UserType   `json:"type"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastSeenAt   *time.Time `json:"last_seen_at,omitempty"`
+	HidePresence bool       `json:"hide_presence,omitempty"`
+	Online       *bool      `json:"online,omitempty"`
+}
+
+func (u User) Profile() UserProfile {
+	return UserProfile{
+		ID:           u.ID,
+		Username:     u.Username,
+		DisplayName:  u.DisplayName,
+		Type:         u.Type,
+		CreatedAt:    u.CreatedAt,
+		LastSeenAt:   u.LastSeenAt,
+		HidePresence: u.HidePresence,
+		Online:       u.Online,
+	}
+}
+
+// PublicUser is a projection of the user visible to anyone.
+type PublicUser struct {
+	ID          int64    `db:"id" json:"id"`
+	Username    string   `db:"username" json:"username"`
+	DisplayName string   `db:"display_name" json:"display_name"`
+	Type        UserType `db:"type" json:"type"`
 }
 
 type CreateUserInput struct {
@@ -62,3 +151,40 @@ type CreateUserRecord struct {
 	Type         int8
 	CreatedAt    time.Time
 }
+
+// AnonymizeUserRecord replaces personal data of the deleted user.
+type AnonymizeUserRecord struct {
+	ID          int64
+	Username    string
+	DisplayName string
+	DeletedAt   time.Time
+}
+
+type SearchUsersInput struct {
+	Query string
+	Limit uint64
+}
+
+// NewSearchUsersInput validates the query, leading "@" of the mention is ignored.
+func NewSearchUsersInput(query string, limit uint64) (SearchUsersInput, error) {
+	query = strings.TrimPrefix(strings.TrimSpace(query), "@")
+	if query == "" || len([]rune(query)) > MaxUserSearchLength {
+		return SearchUsersInput{}, ErrInvalidSearch
+	}
+	if limit == 0 {
+		limit = DefaultUserSearchLimit
+	}
+	limit = min(limit, MaxUserSearchLimit)
+
+	return SearchUsersInput{
+		Query: query,
+		Limit: limit,
+	}, nil
+}
+
+type SearchUsersRecord struct {
+	Query string
+	Limit uint64
+	// Now is the time suspensions are checked at.
+	Now time.Time
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"spsu-chat/pkg/netguard"
+	"strings"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending = iota
+	WebhookDeliveryDelivered
+	WebhookDeliveryFailed
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrInvalidWebhookURL   = errors.New("webhook url must be absolute http or https url")
+	ErrForbiddenWebhookURL = errors.New("webhook url must not point to a private address")
+	ErrChatAccessDenied    = errors.New("you have no rights to manage this chat")
+)
+
+type WebhookDeliveryStatus int8
+
+type Webhook struct {
+	ID           int64      `db:"id" json:"id"`
+	ChatID       int64      `db:"chat_id" json:"chat_id"`
+	CreatorID    int64      `db:"creator_id" json:"creator_id"`
+	URL          string     `db:"url" json:"url"`
+	Secret       string     `db:"secret" json:"-"`
+	IsActive     bool       `db:"is_active" json:"is_active"`
+	FailureCount int        `db:"failure_count" json:"failure_count"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	DisabledAt   *time.Time `db:"disabled_at" json:"disabled_at"`
+}
+
+// WebhookCredentials is returned only once, when webhook is created.
+type WebhookCredentials struct {
+	Webhook Webhook `json:"webhook"`
+	Secret  string  `json:"secret"`
+}
+
+type CreateWebhookInput struct {
+	ChatID int64
+	URL    string
+}
+
+func NewCreateWebhookInput(chatID int64, rawURL string) (CreateWebhookInput, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || !parsedURL.IsAbs() || parsedURL.Host == "" {
+		return CreateWebhookInput{}, ErrInvalidWebhookURL
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return CreateWebhookInput{}, ErrInvalidWebhookURL
+	}
+	// host names are checked when delivery connects, literal addresses are rejected early
+	host := parsedURL.Hostname()
+	if addr, err := netip.ParseAddr(host); strings.EqualFold(host, "localhost") || (err == nil && !netguard.IsPublicAddr(addr)) {
+		return CreateWebhookInput{}, ErrForbiddenWebhookURL
+	}
+
+	return CreateWebhookInput{
+		ChatID: chatID,
+		URL:    parsedURL.String(),
+	}, nil
+}
+
+type CreateWebhookRecord struct {
+	ChatID    int64
+	CreatorID int64
+	URL       string
+	Secret    string
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID            int64                 `db:"id" json:"id"`
+	WebhookID     int64                 `db:"webhook_id" json:"webhook_id"`
+	Event         ChatEventType         `db:"event" json:"event"`
+	Payload       json.RawMessage       `db:"payload" json:"payload"`
+	Status        WebhookDeliveryStatus `db:"status" json:"status"`
+	Attempts      int                   `db:"attempts" json:"attempts"`
+	NextAttemptAt time.Time             `db:"next_attempt_at" json:"next_attempt_at"`
+	ResponseCode  *int                  `db:"response_code" json:"response_code"`
+	LastError     *string               `db:"last_error" json:"last_error"`
+	CreatedAt     time.Time             `db:"created_at" json:"created_at"`
+	DeliveredAt   *time.Time            `db:"delivered_at" json:"delivered_at"`
+}
+
+type CreateWebhookDeliveryRecord struct {
+	WebhookID int64
+	Event     ChatEventType
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// UpdateWebhookDeliveryRecord stores result of the delivery attempt.
+type UpdateWebhookDeliveryRecord struct {
+	ID            int64
+	Status        WebhookDeliveryStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	ResponseCode  *int
+	LastError     *string
+	DeliveredAt   *time.Time
+}
@@ -0,0 +1,110 @@
+package presence
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu          sync.Mutex
+	activity    map[int64]time.Time
+	dirty       map[int64]time.Time
+	connections map[int64]int
+	// typing is chat id -> user id -> signal expiration time
+	typing map[int64]map[int64]time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		activity:    make(map[int64]time.Time),
+		dirty:       make(map[int64]time.Time),
+		connections: make(map[int64]int),
+		typing:      make(map[int64]map[int64]time.Time),
+	}
+}
+
+func (s *MemoryStore) Touch(userID int64, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.activity[userID]; ok && last.After(at) {
+		return
+	}
+	s.activity[userID] = at
+	s.dirty[userID] = at
+}
+
+func (s *MemoryStore) LastActive(userID int64) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.activity[userID]
+	return at, ok
+}
+
+func (s *MemoryStore) Flush() map[int64]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirty := s.dirty
+	s.dirty = make(map[int64]time.Time)
+
+	return dirty
+}
+
+func (s *MemoryStore) Connect(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.connections[userID]++
+}
+
+func (s *MemoryStore) Disconnect(userID int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.connections[userID] <= 1 {
+		delete(s.connections, userID)
+		return
+	}
+	s.connections[userID]--
+}
+
+func (s *MemoryStore) IsConnected(userID int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.connections[userID] > 0
+}
+
+func (s *MemoryStore) SetTyping(chatID, userID int64, expiresAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, ok := s.typing[chatID]
+	if !ok {
+		users = make(map[int64]time.Time)
+		s.typing[chatID] = users
+	}
+	users[userID] = expiresAt
+}
+
+func (s *MemoryStore) GetTyping(chatID int64, now time.Time) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	userIDs := make([]int64, 0)
+	for userID, expiresAt := range s.typing[chatID] {
+		// expired signals are removed lazily
+		if !expiresAt.After(now) {
+			delete(s.typing[chatID], userID)
+			continue
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if len(s.typing[chatID]) == 0 {
+		delete(s.typing, chatID)
+	}
+
+	return userIDs
+}
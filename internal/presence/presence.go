@@ -0,0 +1,27 @@
+package presence
+
+import (
+	"time"
+)
+
+// Store keeps ephemeral presence state: user activity, realtime connections
+// and typing signals. Memory implementation is local to the process,
+// a shared one is required to run several instances.
+type Store interface {
+	// Touch records user activity.
+	Touch(userID int64, at time.Time)
+	// LastActive returns time of the last recorded user activity.
+	LastActive(userID int64) (time.Time, bool)
+	// Flush returns activity recorded since the previous flush.
+	Flush() map[int64]time.Time
+
+	// Connect registers realtime connection of the user, user is online
+	// until every connection is disconnected.
+	Connect(userID int64)
+	Disconnect(userID int64)
+	IsConnected(userID int64) bool
+
+	SetTyping(chatID, userID int64, expiresAt time.Time)
+	// GetTyping returns users typing in the chat at the given time.
+	GetTyping(chatID int64, now time.Time) []int64
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pruneInterval is how often keys with passed arrival time are removed.
+const pruneInterval = time.Minute
+
+type MemoryLimiter struct {
+	mu       sync.Mutex
+	tats     map[string]time.Time
+	prunedAt time.Time
+}
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		tats: make(map[string]time.Time),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit, now time.Time) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	next, allowedAt := Next(l.tats[key], limit, now)
+	if now.Before(allowedAt) {
+		return allowedAt.Sub(now), nil
+	}
+	l.tats[key] = next
+
+	return 0, nil
+}
+
+func (l *MemoryLimiter) Release(_ context.Context, key string, limit Limit) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tat, ok := l.tats[key]; ok {
+		l.tats[key] = tat.Add(-limit.Interval())
+	}
+
+	return nil
+}
+
+// prune removes keys which don't limit anything anymore.
+func (l *MemoryLimiter) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < pruneInterval {
+		return
+	}
+	l.prunedAt = now
+
+	for key, tat := range l.tats {
+		if !tat.After(now) {
+			delete(l.tats, key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"spsu-chat/internal/ratelimit"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLimiterAllow(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		limiter = ratelimit.NewMemoryLimiter()
+		limit   = ratelimit.Limit{Count: 3, Period: 3 * time.Second}
+		now     = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	// burst of Count events is allowed
+	for i := 0; i < 3; i++ {
+		wait, err := limiter.Allow(ctx, "user", limit, now)
+		require.NoError(t, err)
+		require.Zero(t, wait)
+	}
+
+	wait, err := limiter.Allow(ctx, "user", limit, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+
+	// other keys are limited separately
+	wait, err = limiter.Allow(ctx, "other", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	now = now.Add(time.Second)
+	wait, err = limiter.Allow(ctx, "user", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = limiter.Allow(ctx, "user", limit, now.Add(500*time.Millisecond))
+	require.NoError(t, err)
+	require.Equal(t, 500*time.Millisecond, wait)
+}
+
+func TestMemoryLimiterEvery(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		limiter = ratelimit.NewMemoryLimiter()
+		limit   = ratelimit.Every(10 * time.Second)
+		now     = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	wait, err := limiter.Allow(ctx, "chat:1:user:1", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = limiter.Allow(ctx, "chat:1:user:1", limit, now.Add(4*time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 6*time.Second, wait)
+
+	wait, err = limiter.Allow(ctx, "chat:1:user:1", limit, now.Add(10*time.Second))
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestMemoryLimiterRelease(t *testing.T) {
+	var (
+		ctx     = context.Background()
+		limiter = ratelimit.NewMemoryLimiter()
+		limit   = ratelimit.Every(time.Second)
+		now     = time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	)
+
+	wait, err := limiter.Allow(ctx, "user", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	// released event does not limit the next one
+	require.NoError(t, limiter.Release(ctx, "user", limit))
+	wait, err = limiter.Allow(ctx, "user", limit, now)
+	require.NoError(t, err)
+	require.Zero(t, wait)
+
+	wait, err = limiter.Allow(ctx, "user", limit, now)
+	require.NoError(t, err)
+	require.Equal(t, time.Second, wait)
+}
@@ -0,0 +1,45 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Count events per Period, all of them may happen at once.
+type Limit struct {
+	Count  int
+	Period time.Duration
+}
+
+// Interval is the time between events when they are evenly spread.
+func (l Limit) Interval() time.Duration {
+	return l.Period / time.Duration(max(l.Count, 1))
+}
+
+// Every allows a single event per interval.
+func Every(interval time.Duration) Limit {
+	return Limit{Count: 1, Period: interval}
+}
+
+// Limiter counts events by key using GCRA, so only the theoretical arrival
+// time of the next event is stored per key. Memory implementation is local to
+// the process, PostgreSQL one is shared by all instances.
+type Limiter interface {
+	// Allow records the event if the limit is not exceeded, otherwise it returns
+	// how long to wait until the event is allowed.
+	Allow(ctx context.Context, key string, limit Limit, now time.Time) (time.Duration, error)
+	// Release gives back the event recorded by Allow, e.g. when the limited
+	// action has not happened.
+	Release(ctx context.Context, key string, limit Limit) error
+}
+
+// Next returns theoretical arrival time after the event and the time event is
+// allowed at given the stored arrival time tat.
+func Next(tat time.Time, limit Limit, now time.Time) (next time.Time, allowedAt time.Time) {
+	if tat.Before(now) {
+		tat = now
+	}
+
+	next = tat.Add(limit.Interval())
+	return next, next.Add(-limit.Period)
+}
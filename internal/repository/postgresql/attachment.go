@@ -0,0 +1,79 @@
+package postgresql
+
+import (
+	"context"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type AttachmentPosgresql struct {
+	db DB
+}
+
+func NewAttachment(db DB) *AttachmentPosgresql {
+	return &AttachmentPosgresql{
+		db: db,
+	}
+}
+
+func (p *AttachmentPosgresql) GetByMessageIDs(ctx context.Context, messageIDs []int64) ([]models.Attachment, error) {
+	attachments := make([]models.Attachment, 0)
+	if len(messageIDs) == 0 {
+		return attachments, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(MessageAttachmentsTable).
+		Where(squirrel.Eq{"message_id": messageIDs}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &attachments, query, args...); err != nil {
+		return attachments, apperror.NewDBError(
+			err,
+			"Attachment",
+			"GetByMessageIDs",
+			query,
+			args,
+		)
+	}
+
+	return attachments, nil
+}
+
+func (p *AttachmentPosgresql) Create(ctx context.Context, messageID int64, attachments []models.CreateAttachmentRecord) error {
+	if len(attachments) == 0 {
+		return nil
+	}
+
+	insert := squirrel.
+		Insert(MessageAttachmentsTable).
+		Columns("message_id", "file_id", "filename", "url", "size", "created_at")
+	for _, attachment := range attachments {
+		insert = insert.Values(
+			messageID,
+			attachment.FileID,
+			attachment.Filename,
+			attachment.URL,
+			attachment.Size,
+			attachment.CreatedAt,
+		)
+	}
+	query, args, _ := insert.PlaceholderFormat(squirrel.Dollar).ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Attachment",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
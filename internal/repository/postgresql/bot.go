@@ -0,0 +1,206 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type BotPosgresql struct {
+	db DB
+}
+
+func NewBot(db DB) *BotPosgresql {
+	return &BotPosgresql{
+		db: db,
+	}
+}
+
+func (p *BotPosgresql) CreateToken(ctx context.Context, token models.CreateBotTokenRecord) error {
+	query, args, _ := squirrel.
+		Insert(BotTokensTable).
+		Columns(
+			"bot_id",
+			"token_hash",
+			"created_at",
+		).
+		Values(
+			token.BotID,
+			token.TokenHash,
+			token.CreatedAt,
+		).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Bot",
+			"CreateToken",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// GetActiveToken returns not revoked token by its hash.
+func (p *BotPosgresql) GetActiveToken(ctx context.Context, tokenHash []byte) (models.BotToken, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(BotTokensTable).
+		Where(squirrel.Eq{"token_hash": tokenHash, "revoked_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var token models.BotToken
+	if err := p.db.GetContext(ctx, &token, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return token, apperror.ErrNotFound
+		default:
+			return token, apperror.NewDBError(
+				err,
+				"Bot",
+				"GetActiveToken",
+				query,
+				args,
+			)
+		}
+	}
+
+	return token, nil
+}
+
+func (p *BotPosgresql) RevokeTokens(ctx context.Context, botID int64, revokedAt time.Time) error {
+	query, args, _ := squirrel.
+		Update(BotTokensTable).
+		Set("revoked_at", revokedAt).
+		Where(squirrel.Eq{"bot_id": botID, "revoked_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Bot",
+			"RevokeTokens",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// SetCommands replaces all commands of the bot.
+func (p *BotPosgresql) SetCommands(ctx context.Context, botID int64, commands []models.BotCommand) error {
+	query, args, _ := squirrel.
+		Delete(BotCommandsTable).
+		Where(squirrel.Eq{"bot_id": botID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Bot",
+			"SetCommands",
+			query,
+			args,
+		)
+	}
+	if len(commands) == 0 {
+		return nil
+	}
+
+	insert := squirrel.
+		Insert(BotCommandsTable).
+		Columns(
+			"bot_id",
+			"name",
+			"description",
+		)
+	for _, command := range commands {
+		insert = insert.Values(
+			botID,
+			command.Name,
+			command.Description,
+		)
+	}
+
+	query, args, _ = insert.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Bot",
+			"SetCommands",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *BotPosgresql) GetCommands(ctx context.Context, botID int64) ([]models.BotCommand, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(BotCommandsTable).
+		Where(squirrel.Eq{"bot_id": botID}).
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var commands = make([]models.BotCommand, 0)
+	if err := p.db.SelectContext(ctx, &commands, query, args...); err != nil {
+		return commands, apperror.NewDBError(
+			err,
+			"Bot",
+			"GetCommands",
+			query,
+			args,
+		)
+	}
+
+	return commands, nil
+}
+
+// GetChatCommands returns commands of all bots joined to the chat.
+func (p *BotPosgresql) GetChatCommands(ctx context.Context, chatID int64) ([]models.BotCommand, error) {
+	joinedUsers := squirrel.
+		Select("user_id").
+		From(ChatUsersTable).
+		Where(squirrel.Eq{"chat_id": chatID})
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(BotCommandsTable).
+		Where(squirrel.Expr("bot_id IN (?)", joinedUsers)).
+		OrderBy("name", "bot_id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var commands = make([]models.BotCommand, 0)
+	if err := p.db.SelectContext(ctx, &commands, query, args...); err != nil {
+		return commands, apperror.NewDBError(
+			err,
+			"Bot",
+			"GetChatCommands",
+			query,
+			args,
+		)
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,182 @@
+package postgresql
+
+import (
+	"context"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
+)
+
+// CategoryPosgresql needs transactions, since categories of the chat are replaced at once.
+type CategoryPosgresql struct {
+	db *sqlx.DB
+}
+
+func NewCategory(psql PostgresqlRepository) *CategoryPosgresql {
+	return &CategoryPosgresql{
+		db: psql.db,
+	}
+}
+
+func (p *CategoryPosgresql) Create(ctx context.Context, category models.CreateCategoryRecord) (models.Category, error) {
+	query, args, _ := squirrel.
+		Insert(CategoriesTable).
+		Columns(
+			"name",
+			"slug",
+			"created_at",
+		).
+		Values(
+			category.Name,
+			category.Slug,
+			category.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.Category
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		pgErr := GetPgError(err)
+		if pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return created, models.ErrCategoryExists
+		}
+
+		return created, apperror.NewDBError(
+			err,
+			"Category",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *CategoryPosgresql) GetAll(ctx context.Context) ([]models.Category, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(CategoriesTable).
+		OrderBy("name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	categories := make([]models.Category, 0)
+	if err := p.db.SelectContext(ctx, &categories, query, args...); err != nil {
+		return categories, apperror.NewDBError(
+			err,
+			"Category",
+			"GetAll",
+			query,
+			args,
+		)
+	}
+
+	return categories, nil
+}
+
+func (p *CategoryPosgresql) Delete(ctx context.Context, id int64) error {
+	query, args, _ := squirrel.
+		Delete(CategoriesTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Category",
+			"Delete",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *CategoryPosgresql) GetByChatIDs(ctx context.Context, chatIDs []int64) ([]models.ChatCategory, error) {
+	categories := make([]models.ChatCategory, 0)
+	if len(chatIDs) == 0 {
+		return categories, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("cc.chat_id", "c.*").
+		From(ChatCategoriesTable + " cc").
+		Join(CategoriesTable + " c ON c.id = cc.category_id").
+		Where(squirrel.Eq{"cc.chat_id": chatIDs}).
+		OrderBy("c.name").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &categories, query, args...); err != nil {
+		return categories, apperror.NewDBError(
+			err,
+			"Category",
+			"GetByChatIDs",
+			query,
+			args,
+		)
+	}
+
+	return categories, nil
+}
+
+// SetChatCategories replaces categories of the chat, models.ErrCategoryNotFound
+// is returned if any of categories does not exist.
+func (p *CategoryPosgresql) SetChatCategories(ctx context.Context, chatID int64, categoryIDs []int64) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "Category", "SetChatCategories", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Delete(ChatCategoriesTable).
+		Where(squirrel.Eq{"chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "Category", "SetChatCategories", query, args)
+	}
+
+	if len(categoryIDs) > 0 {
+		builder := squirrel.
+			Insert(ChatCategoriesTable).
+			Columns("chat_id", "category_id")
+		for _, categoryID := range categoryIDs {
+			builder = builder.Values(chatID, categoryID)
+		}
+		query, args, _ = builder.
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			pgErr := GetPgError(err)
+			if pgErr != nil && pgErr.Code == pgerrcode.ForeignKeyViolation {
+				return models.ErrCategoryNotFound
+			}
+			return apperror.NewDBError(err, "Category", "SetChatCategories", query, args)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "Category", "SetChatCategories", "COMMIT", nil)
+	}
+
+	return nil
+}
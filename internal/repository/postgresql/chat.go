@@ -6,6 +6,7 @@ import (
 	"errors"
 	"spsu-chat/internal/apperror"
 	"spsu-chat/internal/models"
+	"strings"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgerrcode"
@@ -140,11 +141,52 @@ func (p *ChatPosgresql) LeaveUser(ctx context.Context, chatID int64, userID int6
 	return nil
 }
 
-func (p *ChatPosgresql) GetAll(ctx context.Context, pagination models.DBPagination) ([]models.Chat, uint64, error) {
+// GetAll returns chats matching the filters with their member count and time of the last message.
+func (p *ChatPosgresql) GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetChatsFilters) ([]models.Chat, uint64, error) {
+	where := squirrel.And{}
+	search := strings.TrimSpace(filters.Query)
+	if search != "" {
+		// ILIKE is served by trigram index
+		where = append(where, squirrel.Expr("chats.name ILIKE ?", "%"+escapeLike(search)+"%"))
+	}
+	if chatType, ok := filters.ChatType(); ok {
+		where = append(where, squirrel.Eq{"chats.type": chatType})
+	}
+	if filters.Joined {
+		where = append(where, squirrel.Expr(
+			"chats.id IN (SELECT chat_id FROM "+ChatUsersTable+" WHERE user_id = ?)",
+			filters.UserID,
+		))
+	}
+	if filters.Category != "" {
+		where = append(where, squirrel.Expr(
+			"chats.id IN (SELECT cc.chat_id FROM "+ChatCategoriesTable+" cc JOIN "+CategoriesTable+" c ON c.id = cc.category_id WHERE c.slug = ?)",
+			filters.Category,
+		))
+	}
+
 	// getting chats
 	query := squirrel.
-		Select("*").
-		From(ChatsTable)
+		Select(
+			"chats.*",
+			"(SELECT COUNT(*) FROM "+ChatUsersTable+" WHERE chat_id = chats.id) AS member_count",
+			"(SELECT MAX(created_at) FROM "+MessagesTable+" WHERE chat_id = chats.id AND deleted_at IS NULL) AS last_message_at",
+		).
+		From(ChatsTable).
+		Where(where)
+
+	switch {
+	case filters.Sort == models.ChatSortCreated:
+		query = query.OrderBy("chats.created_at DESC", "chats.id DESC")
+	case filters.Sort == models.ChatSortActivity:
+		query = query.OrderBy("last_message_at DESC NULLS LAST", "chats.id DESC")
+	case filters.Sort == models.ChatSortMembers:
+		query = query.OrderBy("member_count DESC", "chats.id DESC")
+	case search != "":
+		query = query.OrderByClause("similarity(chats.name, ?) DESC", search).OrderBy("chats.id")
+	default:
+		query = query.OrderBy("chats.id")
+	}
 
 	queryString, args, _ := query.Limit(pagination.Limit).
 		Offset(pagination.Offset).
@@ -165,7 +207,8 @@ func (p *ChatPosgresql) GetAll(ctx context.Context, pagination models.DBPaginati
 	// counting chats
 	query = squirrel.
 		Select("COUNT(*)").
-		From(ChatsTable)
+		From(ChatsTable).
+		Where(where)
 
 	queryString, args, _ = query.
 		PlaceholderFormat(squirrel.Dollar).
@@ -210,3 +253,170 @@ func (p *ChatPosgresql) IsUserInChat(ctx context.Context, chatID, userID int64)
 
 	return true, nil
 }
+
+func (p *ChatPosgresql) GetMember(ctx context.Context, chatID, userID int64) (models.ChatMember, error) {
+	query, args, _ := squirrel.
+		Select("chat_id", "user_id", "role").
+		From(ChatUsersTable).
+		Where(squirrel.Eq{"user_id": userID, "chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var member models.ChatMember
+	if err := p.db.GetContext(ctx, &member, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return member, apperror.ErrNotFound
+		default:
+			return member, apperror.NewDBError(
+				err,
+				"Chat",
+				"GetMember",
+				query,
+				args,
+			)
+		}
+	}
+
+	return member, nil
+}
+
+func (p *ChatPosgresql) SetMemberRole(ctx context.Context, chatID, userID int64, role models.ChatRole) error {
+	query, args, _ := squirrel.
+		Update(ChatUsersTable).
+		Set("role", role).
+		Where(squirrel.Eq{"user_id": userID, "chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Chat",
+			"SetMemberRole",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *ChatPosgresql) UpdateTopic(ctx context.Context, chatID int64, topic string) error {
+	query, args, _ := squirrel.
+		Update(ChatsTable).
+		Set("topic", topic).
+		Where(squirrel.Eq{"id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Chat",
+			"UpdateTopic",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// UpdateSettings changes the settings which are set in the input, zero retention disables it.
+func (p *ChatPosgresql) UpdateSettings(ctx context.Context, chatID int64, settings models.UpdateChatSettingsInput) error {
+	update := squirrel.
+		Update(ChatsTable).
+		Where(squirrel.Eq{"id": chatID})
+
+	changed := false
+	if settings.RetentionDays != nil {
+		var retentionDays *int
+		if *settings.RetentionDays > 0 {
+			retentionDays = settings.RetentionDays
+		}
+		update = update.Set("retention_days", retentionDays)
+		changed = true
+	}
+	if settings.ForwardingDisabled != nil {
+		update = update.Set("forwarding_disabled", *settings.ForwardingDisabled)
+		changed = true
+	}
+	if settings.SlowModeSeconds != nil {
+		update = update.Set("slow_mode_seconds", *settings.SlowModeSeconds)
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	query, args, _ := update.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Chat",
+			"UpdateSettings",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *ChatPosgresql) GetMemberIDs(ctx context.Context, chatID int64) ([]int64, error) {
+	query, args, _ := squirrel.
+		Select("user_id").
+		From(ChatUsersTable).
+		Where(squirrel.Eq{"chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var ids = make([]int64, 0)
+	if err := p.db.SelectContext(ctx, &ids, query, args...); err != nil {
+		return ids, apperror.NewDBError(
+			err,
+			"Chat",
+			"GetMemberIDs",
+			query,
+			args,
+		)
+	}
+
+	return ids, nil
+}
+
+func (p *ChatPosgresql) GetMemberships(ctx context.Context, userID int64) ([]models.ChatMembership, error) {
+	query, args, _ := squirrel.
+		Select(
+			ChatUsersTable+".chat_id",
+			ChatsTable+".name AS chat_name",
+			ChatUsersTable+".role",
+		).
+		From(ChatUsersTable).
+		Join(ChatsTable + " ON " + ChatsTable + ".id = " + ChatUsersTable + ".chat_id").
+		Where(squirrel.Eq{ChatUsersTable + ".user_id": userID}).
+		OrderBy(ChatUsersTable + ".chat_id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var memberships = make([]models.ChatMembership, 0)
+	if err := p.db.SelectContext(ctx, &memberships, query, args...); err != nil {
+		return memberships, apperror.NewDBError(
+			err,
+			"Chat",
+			"GetMemberships",
+			query,
+			args,
+		)
+	}
+
+	return memberships, nil
+}
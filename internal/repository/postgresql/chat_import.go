@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"context"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
+)
+
+// ChatImportPosgresql writes imported history, all of it is written in a single
+// transaction, so failed import leaves no partial history.
+type ChatImportPosgresql struct {
+	db *sqlx.DB
+}
+
+func NewChatImport(psql PostgresqlRepository) *ChatImportPosgresql {
+	return &ChatImportPosgresql{
+		db: psql.db,
+	}
+}
+
+func (p *ChatImportPosgresql) Import(ctx context.Context, record models.ImportChatRecord, batchSize int) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "ChatImport", "Import", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	senders := make(map[string]int64, len(record.Senders)+len(record.Placeholders))
+	for key, userID := range record.Senders {
+		senders[key] = userID
+	}
+	for key, placeholder := range record.Placeholders {
+		userID, err := p.createPlaceholder(ctx, tx, placeholder)
+		if err != nil {
+			return err
+		}
+		senders[key] = userID
+	}
+
+	if batchSize <= 0 {
+		batchSize = len(record.Messages)
+	}
+	for start := 0; start < len(record.Messages); start += batchSize {
+		batch := record.Messages[start:min(start+batchSize, len(record.Messages))]
+		if err := p.insertBatch(ctx, tx, record.ChatID, senders, batch); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "ChatImport", "Import", "COMMIT", nil)
+	}
+
+	return nil
+}
+
+func (p *ChatImportPosgresql) createPlaceholder(ctx context.Context, tx *sqlx.Tx, user models.CreateUserRecord) (int64, error) {
+	query, args, _ := squirrel.
+		Insert(UsersTable).
+		Columns(
+			"username",
+			"display_name",
+			"password_hash",
+			"type",
+			"created_at",
+		).
+		Values(
+			user.Username,
+			user.DisplayName,
+			user.PasswordHash,
+			user.Type,
+			user.CreatedAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var id int64
+	if err := tx.GetContext(ctx, &id, query, args...); err != nil {
+		if pgErr := GetPgError(err); pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return 0, models.ErrPlaceholderTaken
+		}
+		return 0, apperror.NewDBError(
+			err,
+			"ChatImport",
+			"createPlaceholder",
+			query,
+			args,
+		)
+	}
+
+	return id, nil
+}
+
+// insertBatch inserts messages with ids reserved in advance, so attachments can
+// reference them without relying on order of the returned rows.
+func (p *ChatImportPosgresql) insertBatch(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	chatID int64,
+	senders map[string]int64,
+	messages []models.ImportMessageRecord,
+) error {
+	idsQuery := "SELECT nextval(pg_get_serial_sequence($1, 'id')) FROM generate_series(1, $2)"
+	idsArgs := []any{MessagesTable, len(messages)}
+
+	var ids []int64
+	if err := tx.SelectContext(ctx, &ids, idsQuery, idsArgs...); err != nil {
+		return apperror.NewDBError(err, "ChatImport", "insertBatch", idsQuery, idsArgs)
+	}
+
+	messagesQuery := squirrel.
+		Insert(MessagesTable).
+		Columns("id", "chat_id", "user_id", "text", "created_at")
+	attachmentsQuery := squirrel.
+		Insert(MessageAttachmentsTable).
+		Columns("message_id", "file_id", "filename", "url", "size", "created_at")
+	attachmentsCount := 0
+
+	for i, message := range messages {
+		messagesQuery = messagesQuery.Values(ids[i], chatID, senders[message.SenderKey], message.Text, message.CreatedAt)
+		for _, attachment := range message.Attachments {
+			attachmentsQuery = attachmentsQuery.Values(
+				ids[i],
+				attachment.FileID,
+				attachment.Filename,
+				attachment.URL,
+				attachment.Size,
+				attachment.CreatedAt,
+			)
+			attachmentsCount++
+		}
+	}
+
+	query, args, _ := messagesQuery.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "ChatImport", "insertBatch", query, args)
+	}
+
+	if attachmentsCount == 0 {
+		return nil
+	}
+	query, args, _ = attachmentsQuery.PlaceholderFormat(squirrel.Dollar).ToSql()
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "ChatImport", "insertBatch", query, args)
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+)
+
+type DataExportPosgresql struct {
+	db DB
+}
+
+func NewDataExport(db DB) *DataExportPosgresql {
+	return &DataExportPosgresql{
+		db: db,
+	}
+}
+
+// Create adds pending export, only one pending export per user is allowed.
+func (p *DataExportPosgresql) Create(ctx context.Context, userID int64, createdAt time.Time) (models.DataExport, error) {
+	query, args, _ := squirrel.
+		Insert(DataExportsTable).
+		Columns(
+			"user_id",
+			"status",
+			"created_at",
+		).
+		Values(
+			userID,
+			models.DataExportPending,
+			createdAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.DataExport
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		pgErr := GetPgError(err)
+		if pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return created, models.ErrExportInProgress
+		}
+
+		return created, apperror.NewDBError(
+			err,
+			"DataExport",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *DataExportPosgresql) GetByID(ctx context.Context, id int64) (models.DataExport, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(DataExportsTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var export models.DataExport
+	if err := p.db.GetContext(ctx, &export, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return export, apperror.ErrNotFound
+		default:
+			return export, apperror.NewDBError(
+				err,
+				"DataExport",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return export, nil
+}
+
+func (p *DataExportPosgresql) GetAllByUser(ctx context.Context, userID int64) ([]models.DataExport, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(DataExportsTable).
+		Where(squirrel.Eq{"user_id": userID}).
+		OrderBy("id DESC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var exports = make([]models.DataExport, 0)
+	if err := p.db.SelectContext(ctx, &exports, query, args...); err != nil {
+		return exports, apperror.NewDBError(
+			err,
+			"DataExport",
+			"GetAllByUser",
+			query,
+			args,
+		)
+	}
+
+	return exports, nil
+}
+
+func (p *DataExportPosgresql) GetPending(ctx context.Context, limit uint64) ([]models.DataExport, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(DataExportsTable).
+		Where(squirrel.Eq{"status": models.DataExportPending}).
+		OrderBy("id").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var exports = make([]models.DataExport, 0)
+	if err := p.db.SelectContext(ctx, &exports, query, args...); err != nil {
+		return exports, apperror.NewDBError(
+			err,
+			"DataExport",
+			"GetPending",
+			query,
+			args,
+		)
+	}
+
+	return exports, nil
+}
+
+// GetExpired returns ready exports which archives should be removed.
+func (p *DataExportPosgresql) GetExpired(ctx context.Context, now time.Time, limit uint64) ([]models.DataExport, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(DataExportsTable).
+		Where(squirrel.Eq{"status": models.DataExportReady}).
+		Where(squirrel.LtOrEq{"expires_at": now}).
+		OrderBy("expires_at").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var exports = make([]models.DataExport, 0)
+	if err := p.db.SelectContext(ctx, &exports, query, args...); err != nil {
+		return exports, apperror.NewDBError(
+			err,
+			"DataExport",
+			"GetExpired",
+			query,
+			args,
+		)
+	}
+
+	return exports, nil
+}
+
+func (p *DataExportPosgresql) Update(ctx context.Context, export models.UpdateDataExportRecord) error {
+	query, args, _ := squirrel.
+		Update(DataExportsTable).
+		Set("status", export.Status).
+		Set("file_id", export.FileID).
+		Set("error", export.Error).
+		Set("completed_at", export.CompletedAt).
+		Set("expires_at", export.ExpiresAt).
+		Where(squirrel.Eq{"id": export.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"DataExport",
+			"Update",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
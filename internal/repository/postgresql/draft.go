@@ -0,0 +1,131 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type DraftPosgresql struct {
+	db DB
+}
+
+func NewDraft(db DB) *DraftPosgresql {
+	return &DraftPosgresql{
+		db: db,
+	}
+}
+
+func (p *DraftPosgresql) Get(ctx context.Context, userID, chatID int64) (models.Draft, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(DraftsTable).
+		Where(squirrel.Eq{"user_id": userID, "chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var draft models.Draft
+	if err := p.db.GetContext(ctx, &draft, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return draft, apperror.ErrNotFound
+		default:
+			return draft, apperror.NewDBError(
+				err,
+				"Draft",
+				"Get",
+				query,
+				args,
+			)
+		}
+	}
+
+	return draft, nil
+}
+
+func (p *DraftPosgresql) GetByChatIDs(ctx context.Context, userID int64, chatIDs []int64) ([]models.Draft, error) {
+	drafts := make([]models.Draft, 0)
+	if len(chatIDs) == 0 {
+		return drafts, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(DraftsTable).
+		Where(squirrel.Eq{"user_id": userID, "chat_id": chatIDs}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &drafts, query, args...); err != nil {
+		return drafts, apperror.NewDBError(
+			err,
+			"Draft",
+			"GetByChatIDs",
+			query,
+			args,
+		)
+	}
+
+	return drafts, nil
+}
+
+func (p *DraftPosgresql) Save(ctx context.Context, draft models.SaveDraftRecord) (models.Draft, error) {
+	query, args, _ := squirrel.
+		Insert(DraftsTable).
+		Columns(
+			"user_id",
+			"chat_id",
+			"text",
+			"updated_at",
+		).
+		Values(
+			draft.UserID,
+			draft.ChatID,
+			draft.Text,
+			draft.UpdatedAt,
+		).
+		Suffix("ON CONFLICT (user_id, chat_id) DO UPDATE SET text = EXCLUDED.text, updated_at = EXCLUDED.updated_at RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var saved models.Draft
+	if err := p.db.GetContext(ctx, &saved, query, args...); err != nil {
+		return saved, apperror.NewDBError(
+			err,
+			"Draft",
+			"Save",
+			query,
+			args,
+		)
+	}
+
+	return saved, nil
+}
+
+func (p *DraftPosgresql) Delete(ctx context.Context, userID, chatID int64) error {
+	query, args, _ := squirrel.
+		Delete(DraftsTable).
+		Where(squirrel.Eq{"user_id": userID, "chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Draft",
+			"Delete",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
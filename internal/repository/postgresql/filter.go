@@ -0,0 +1,277 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+)
+
+type FilterRulePosgresql struct {
+	db DB
+}
+
+func NewFilterRule(db DB) *FilterRulePosgresql {
+	return &FilterRulePosgresql{
+		db: db,
+	}
+}
+
+func (p *FilterRulePosgresql) Create(ctx context.Context, rule models.CreateFilterRuleRecord) (models.FilterRule, error) {
+	query, args, _ := squirrel.
+		Insert(FilterRulesTable).
+		Columns(
+			"chat_id",
+			"kind",
+			"pattern",
+			"action",
+			"created_by",
+			"created_at",
+		).
+		Values(
+			rule.ChatID,
+			rule.Kind,
+			rule.Pattern,
+			rule.Action,
+			rule.CreatedBy,
+			rule.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.FilterRule
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		pgErr := GetPgError(err)
+		if pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return created, models.ErrFilterRuleExists
+		}
+
+		return created, apperror.NewDBError(
+			err,
+			"FilterRule",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *FilterRulePosgresql) GetByID(ctx context.Context, id int64) (models.FilterRule, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(FilterRulesTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var rule models.FilterRule
+	if err := p.db.GetContext(ctx, &rule, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return rule, apperror.ErrNotFound
+		default:
+			return rule, apperror.NewDBError(
+				err,
+				"FilterRule",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return rule, nil
+}
+
+// GetAll returns rules of the chat or global rules if chatID is nil.
+func (p *FilterRulePosgresql) GetAll(ctx context.Context, chatID *int64) ([]models.FilterRule, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(FilterRulesTable).
+		Where(squirrel.Eq{"chat_id": chatID}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	rules := make([]models.FilterRule, 0)
+	if err := p.db.SelectContext(ctx, &rules, query, args...); err != nil {
+		return rules, apperror.NewDBError(
+			err,
+			"FilterRule",
+			"GetAll",
+			query,
+			args,
+		)
+	}
+
+	return rules, nil
+}
+
+// GetForChat returns global rules and rules of the chat applied to its messages.
+func (p *FilterRulePosgresql) GetForChat(ctx context.Context, chatID int64) ([]models.FilterRule, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(FilterRulesTable).
+		Where(squirrel.Or{
+			squirrel.Eq{"chat_id": nil},
+			squirrel.Eq{"chat_id": chatID},
+		}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	rules := make([]models.FilterRule, 0)
+	if err := p.db.SelectContext(ctx, &rules, query, args...); err != nil {
+		return rules, apperror.NewDBError(
+			err,
+			"FilterRule",
+			"GetForChat",
+			query,
+			args,
+		)
+	}
+
+	return rules, nil
+}
+
+func (p *FilterRulePosgresql) Delete(ctx context.Context, id int64) error {
+	query, args, _ := squirrel.
+		Delete(FilterRulesTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"FilterRule",
+			"Delete",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+type FilterHitPosgresql struct {
+	db DB
+}
+
+func NewFilterHit(db DB) *FilterHitPosgresql {
+	return &FilterHitPosgresql{
+		db: db,
+	}
+}
+
+func (p *FilterHitPosgresql) Create(ctx context.Context, hits []models.CreateFilterHitRecord) error {
+	if len(hits) == 0 {
+		return nil
+	}
+
+	builder := squirrel.
+		Insert(FilterHitsTable).
+		Columns(
+			"rule_id",
+			"chat_id",
+			"user_id",
+			"message_id",
+			"kind",
+			"action",
+			"match",
+			"text",
+			"created_at",
+		)
+	for _, hit := range hits {
+		builder = builder.Values(
+			hit.RuleID,
+			hit.ChatID,
+			hit.UserID,
+			hit.MessageID,
+			hit.Kind,
+			hit.Action,
+			hit.Match,
+			hit.Text,
+			hit.CreatedAt,
+		)
+	}
+	query, args, _ := builder.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"FilterHit",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *FilterHitPosgresql) GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetFilterHitsFilters) ([]models.FilterHit, uint64, error) {
+	where := squirrel.And{}
+	if filters.ChatID != 0 {
+		where = append(where, squirrel.Eq{"chat_id": filters.ChatID})
+	}
+	if filters.Action != "" {
+		where = append(where, squirrel.Eq{"action": filters.Action})
+	}
+
+	// getting hits, the newest first
+	queryString, args, _ := squirrel.
+		Select("*").
+		From(FilterHitsTable).
+		Where(where).
+		OrderBy("id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var hits = make([]models.FilterHit, 0)
+	if err := p.db.SelectContext(ctx, &hits, queryString, args...); err != nil {
+		return hits, count, apperror.NewDBError(
+			err,
+			"FilterHit",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	// counting hits
+	queryString, args, _ = squirrel.
+		Select("COUNT(*)").
+		From(FilterHitsTable).
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return hits, count, apperror.NewDBError(
+			err,
+			"FilterHit",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	return hits, count, nil
+}
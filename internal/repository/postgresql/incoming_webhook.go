@@ -0,0 +1,243 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
+)
+
+// IncomingWebhookPosgresql needs transactions, since webhook is created
+// together with its integration user and chat membership.
+type IncomingWebhookPosgresql struct {
+	db *sqlx.DB
+}
+
+func NewIncomingWebhook(psql PostgresqlRepository) *IncomingWebhookPosgresql {
+	return &IncomingWebhookPosgresql{
+		db: psql.db,
+	}
+}
+
+func (p *IncomingWebhookPosgresql) Create(ctx context.Context, webhook models.CreateIncomingWebhookRecord) (created models.IncomingWebhook, err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return created, apperror.NewDBError(err, "IncomingWebhook", "Create", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Insert(UsersTable).
+		Columns(
+			"username",
+			"display_name",
+			"password_hash",
+			"type",
+			"created_at",
+		).
+		Values(
+			webhook.Integration.Username,
+			webhook.Integration.DisplayName,
+			webhook.Integration.PasswordHash,
+			webhook.Integration.Type,
+			webhook.Integration.CreatedAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var userID int64
+	if err := tx.GetContext(ctx, &userID, query, args...); err != nil {
+		pgErr := GetPgError(err)
+		if pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return created, models.ErrUsernameExists
+		}
+
+		return created, apperror.NewDBError(err, "IncomingWebhook", "Create", query, args)
+	}
+
+	query, args, _ = squirrel.
+		Insert(ChatUsersTable).
+		Columns(
+			"chat_id",
+			"user_id",
+		).
+		Values(
+			webhook.ChatID,
+			userID,
+		).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return created, apperror.NewDBError(err, "IncomingWebhook", "Create", query, args)
+	}
+
+	query, args, _ = squirrel.
+		Insert(IncomingWebhooksTable).
+		Columns(
+			"chat_id",
+			"creator_id",
+			"user_id",
+			"name",
+			"token_hash",
+			"created_at",
+		).
+		Values(
+			webhook.ChatID,
+			webhook.CreatorID,
+			userID,
+			webhook.Name,
+			webhook.TokenHash,
+			webhook.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := tx.GetContext(ctx, &created, query, args...); err != nil {
+		return created, apperror.NewDBError(err, "IncomingWebhook", "Create", query, args)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return created, apperror.NewDBError(err, "IncomingWebhook", "Create", "COMMIT", nil)
+	}
+
+	return created, nil
+}
+
+func (p *IncomingWebhookPosgresql) GetByID(ctx context.Context, id int64) (models.IncomingWebhook, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(IncomingWebhooksTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhook models.IncomingWebhook
+	if err := p.db.GetContext(ctx, &webhook, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return webhook, apperror.ErrNotFound
+		default:
+			return webhook, apperror.NewDBError(
+				err,
+				"IncomingWebhook",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return webhook, nil
+}
+
+// GetActiveByTokenHash returns not revoked webhook by its token hash.
+func (p *IncomingWebhookPosgresql) GetActiveByTokenHash(ctx context.Context, tokenHash []byte) (models.IncomingWebhook, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(IncomingWebhooksTable).
+		Where(squirrel.Eq{"token_hash": tokenHash, "revoked_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhook models.IncomingWebhook
+	if err := p.db.GetContext(ctx, &webhook, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return webhook, apperror.ErrNotFound
+		default:
+			return webhook, apperror.NewDBError(
+				err,
+				"IncomingWebhook",
+				"GetActiveByTokenHash",
+				query,
+				args,
+			)
+		}
+	}
+
+	return webhook, nil
+}
+
+func (p *IncomingWebhookPosgresql) GetAllByChat(ctx context.Context, chatID int64) ([]models.IncomingWebhook, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(IncomingWebhooksTable).
+		Where(squirrel.Eq{"chat_id": chatID}).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhooks = make([]models.IncomingWebhook, 0)
+	if err := p.db.SelectContext(ctx, &webhooks, query, args...); err != nil {
+		return webhooks, apperror.NewDBError(
+			err,
+			"IncomingWebhook",
+			"GetAllByChat",
+			query,
+			args,
+		)
+	}
+
+	return webhooks, nil
+}
+
+// Revoke disables webhook token and removes its integration user from the chat,
+// revoked webhook is left as is.
+func (p *IncomingWebhookPosgresql) Revoke(ctx context.Context, id int64, revokedAt time.Time) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "IncomingWebhook", "Revoke", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Update(IncomingWebhooksTable).
+		Set("revoked_at", revokedAt).
+		Where(squirrel.Eq{"id": id, "revoked_at": nil}).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhook models.IncomingWebhook
+	if err := tx.GetContext(ctx, &webhook, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			tx.Rollback()
+			return nil
+		}
+		return apperror.NewDBError(err, "IncomingWebhook", "Revoke", query, args)
+	}
+
+	query, args, _ = squirrel.
+		Delete(ChatUsersTable).
+		Where(squirrel.Eq{"chat_id": webhook.ChatID, "user_id": webhook.UserID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "IncomingWebhook", "Revoke", query, args)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "IncomingWebhook", "Revoke", "COMMIT", nil)
+	}
+
+	return nil
+}
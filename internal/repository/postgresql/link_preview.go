@@ -0,0 +1,145 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type LinkPreviewPosgresql struct {
+	db DB
+}
+
+func NewLinkPreview(db DB) *LinkPreviewPosgresql {
+	return &LinkPreviewPosgresql{
+		db: db,
+	}
+}
+
+func (p *LinkPreviewPosgresql) GetByURL(ctx context.Context, url string) (models.LinkPreview, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(LinkPreviewsTable).
+		Where(squirrel.Eq{"url": url}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var preview models.LinkPreview
+	if err := p.db.GetContext(ctx, &preview, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return preview, apperror.ErrNotFound
+		default:
+			return preview, apperror.NewDBError(
+				err,
+				"LinkPreview",
+				"GetByURL",
+				query,
+				args,
+			)
+		}
+	}
+
+	return preview, nil
+}
+
+func (p *LinkPreviewPosgresql) GetByURLs(ctx context.Context, urls []string) ([]models.LinkPreview, error) {
+	previews := make([]models.LinkPreview, 0)
+	if len(urls) == 0 {
+		return previews, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(LinkPreviewsTable).
+		Where(squirrel.Eq{"url": urls, "failed": false}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &previews, query, args...); err != nil {
+		return previews, apperror.NewDBError(
+			err,
+			"LinkPreview",
+			"GetByURLs",
+			query,
+			args,
+		)
+	}
+
+	return previews, nil
+}
+
+func (p *LinkPreviewPosgresql) Save(ctx context.Context, preview models.SaveLinkPreviewRecord) (models.LinkPreview, error) {
+	query, args, _ := squirrel.
+		Insert(LinkPreviewsTable).
+		Columns(
+			"url",
+			"title",
+			"description",
+			"image_url",
+			"site_name",
+			"failed",
+			"fetched_at",
+		).
+		Values(
+			preview.URL,
+			preview.Title,
+			preview.Description,
+			preview.ImageURL,
+			preview.SiteName,
+			preview.Failed,
+			preview.FetchedAt,
+		).
+		Suffix(`ON CONFLICT (url) DO UPDATE SET
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			image_url = EXCLUDED.image_url,
+			site_name = EXCLUDED.site_name,
+			failed = EXCLUDED.failed,
+			fetched_at = EXCLUDED.fetched_at
+			RETURNING *`).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var saved models.LinkPreview
+	if err := p.db.GetContext(ctx, &saved, query, args...); err != nil {
+		return saved, apperror.NewDBError(
+			err,
+			"LinkPreview",
+			"Save",
+			query,
+			args,
+		)
+	}
+
+	return saved, nil
+}
+
+func (p *LinkPreviewPosgresql) Attach(ctx context.Context, messageID int64, url string) error {
+	query, args, _ := squirrel.
+		Update(MessagesTable).
+		Set("link_preview_url", url).
+		Where(squirrel.Eq{"id": messageID, "deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"LinkPreview",
+			"Attach",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package postgresql
+
+import (
+	"context"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+// mentionsBatchSize keeps insert below PostgreSQL bind parameters limit.
+const mentionsBatchSize = 1000
+
+type MentionPosgresql struct {
+	db DB
+}
+
+func NewMention(db DB) *MentionPosgresql {
+	return &MentionPosgresql{
+		db: db,
+	}
+}
+
+func (p *MentionPosgresql) Create(ctx context.Context, mentions []models.CreateMentionRecord) error {
+	for start := 0; start < len(mentions); start += mentionsBatchSize {
+		end := min(start+mentionsBatchSize, len(mentions))
+
+		insert := squirrel.
+			Insert(MentionsTable).
+			Columns(
+				"message_id",
+				"chat_id",
+				"user_id",
+				"kind",
+				"created_at",
+			)
+		for _, mention := range mentions[start:end] {
+			insert = insert.Values(
+				mention.MessageID,
+				mention.ChatID,
+				mention.UserID,
+				mention.Kind,
+				mention.CreatedAt,
+			)
+		}
+
+		query, args, _ := insert.
+			Suffix("ON CONFLICT (message_id, user_id) DO NOTHING").
+			PlaceholderFormat(squirrel.Dollar).
+			ToSql()
+
+		if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+			return apperror.NewDBError(
+				err,
+				"Mention",
+				"Create",
+				query,
+				args,
+			)
+		}
+	}
+
+	return nil
+}
+
+func (p *MentionPosgresql) GetAll(ctx context.Context, userID int64, pagination models.DBPagination, filters models.GetMentionsFilters) ([]models.Mention, uint64, error) {
+	where := squirrel.And{
+		squirrel.Eq{MentionsTable + ".user_id": userID},
+		squirrel.Eq{MessagesTable + ".deleted_at": nil},
+	}
+	if filters.Unread {
+		where = append(where, squirrel.Eq{MentionsTable + ".read_at": nil})
+	}
+	if !filters.VisibleAt.IsZero() {
+		where = append(where, visibleMessages(filters.VisibleAt))
+	}
+
+	// getting mentions
+	queryString, args, _ := squirrel.
+		Select(
+			MentionsTable+".*",
+			MessagesTable+".user_id AS sender_id",
+			MessagesTable+".text",
+		).
+		From(MentionsTable).
+		Join(MessagesTable + " ON " + MessagesTable + ".id = " + MentionsTable + ".message_id").
+		Join(ChatsTable + " ON " + ChatsTable + ".id = " + MessagesTable + ".chat_id").
+		Where(where).
+		OrderBy(MentionsTable + ".id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var mentions = make([]models.Mention, 0)
+	if err := p.db.SelectContext(ctx, &mentions, queryString, args...); err != nil {
+		return mentions, count, apperror.NewDBError(
+			err,
+			"Mention",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	// counting mentions
+	queryString, args, _ = squirrel.
+		Select("COUNT(*)").
+		From(MentionsTable).
+		Join(MessagesTable + " ON " + MessagesTable + ".id = " + MentionsTable + ".message_id").
+		Join(ChatsTable + " ON " + ChatsTable + ".id = " + MessagesTable + ".chat_id").
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return mentions, count, apperror.NewDBError(
+			err,
+			"Mention",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	return mentions, count, nil
+}
+
+// CountUnread counts unread mentions in messages visible at now.
+func (p *MentionPosgresql) CountUnread(ctx context.Context, userID int64, now time.Time) (uint64, error) {
+	query, args, _ := squirrel.
+		Select("COUNT(*)").
+		From(MentionsTable).
+		Join(MessagesTable + " ON " + MessagesTable + ".id = " + MentionsTable + ".message_id").
+		Join(ChatsTable + " ON " + ChatsTable + ".id = " + MessagesTable + ".chat_id").
+		Where(squirrel.Eq{
+			MentionsTable + ".user_id":    userID,
+			MentionsTable + ".read_at":    nil,
+			MessagesTable + ".deleted_at": nil,
+		}).
+		Where(visibleMessages(now)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	if err := p.db.GetContext(ctx, &count, query, args...); err != nil {
+		return count, apperror.NewDBError(
+			err,
+			"Mention",
+			"CountUnread",
+			query,
+			args,
+		)
+	}
+
+	return count, nil
+}
+
+// MarkRead marks user mentions with given ids as read, all unread mentions if ids are empty.
+func (p *MentionPosgresql) MarkRead(ctx context.Context, userID int64, ids []int64, readAt time.Time) error {
+	update := squirrel.
+		Update(MentionsTable).
+		Set("read_at", readAt).
+		Where(squirrel.Eq{"user_id": userID, "read_at": nil})
+	if len(ids) > 0 {
+		update = update.Where(squirrel.Eq{"id": ids})
+	}
+
+	query, args, _ := update.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Mention",
+			"MarkRead",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
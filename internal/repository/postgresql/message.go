@@ -6,40 +6,76 @@ import (
 	"errors"
 	"spsu-chat/internal/apperror"
 	"spsu-chat/internal/models"
+	"time"
 
 	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
 )
 
 type MessagesPosgresql struct {
-	db DB
+	db *sqlx.DB
 }
 
-func NewMessages(db DB) *MessagesPosgresql {
+func NewMessages(psql PostgresqlRepository) *MessagesPosgresql {
 	return &MessagesPosgresql{
-		db: db,
+		db: psql.db,
 	}
 }
 
-func (m *MessagesPosgresql) Create(ctx context.Context, message models.CreateMessageRecord) error {
+func (m *MessagesPosgresql) Create(ctx context.Context, message models.CreateMessageRecord) (models.Message, error) {
+	var forward struct {
+		chatID    *int64
+		senderID  *int64
+		messageID *int64
+		sentAt    *time.Time
+	}
+	if message.Forward != nil {
+		// Origin chat or sender may be already deleted.
+		if message.Forward.ChatID != 0 {
+			forward.chatID = &message.Forward.ChatID
+		}
+		if message.Forward.SenderID != 0 {
+			forward.senderID = &message.Forward.SenderID
+		}
+		forward.messageID = &message.Forward.MessageID
+		forward.sentAt = &message.Forward.SentAt
+	}
+
 	query, args, _ := squirrel.
 		Insert(MessagesTable).
 		Columns(
 			"chat_id",
 			"user_id",
 			"text",
+			"sender_name",
 			"created_at",
+			"expires_at",
+			"forwarded_from_chat_id",
+			"forwarded_from_user_id",
+			"forwarded_from_message_id",
+			"forwarded_sent_at",
+			"entities",
 		).
 		Values(
 			message.ChatID,
 			message.SenderID,
 			message.Text,
+			message.SenderName,
 			message.CreatedAt,
+			message.ExpiresAt,
+			forward.chatID,
+			forward.senderID,
+			forward.messageID,
+			forward.sentAt,
+			message.Entities,
 		).
+		Suffix("RETURNING *").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
-	if _, err := m.db.ExecContext(ctx, query, args...); err != nil {
-		return apperror.NewDBError(
+	var created models.Message
+	if err := m.db.GetContext(ctx, &created, query, args...); err != nil {
+		return created, apperror.NewDBError(
 			err,
 			"Message",
 			"Create",
@@ -48,7 +84,7 @@ func (m *MessagesPosgresql) Create(ctx context.Context, message models.CreateMes
 		)
 	}
 
-	return nil
+	return created, nil
 }
 func (m *MessagesPosgresql) GetByID(ctx context.Context, id int64) (models.Message, error) {
 	query, args, _ := squirrel.
@@ -77,11 +113,25 @@ func (m *MessagesPosgresql) GetByID(ctx context.Context, id int64) (models.Messa
 	return message, nil
 }
 func (m *MessagesPosgresql) GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetMessagesFilters) ([]models.Message, uint64, error) {
+	where := squirrel.And{squirrel.Eq{"chat_id": filters.ChatID}}
+	if len(filters.ExcludeSenderIDs) > 0 {
+		where = append(where, squirrel.NotEq{"user_id": filters.ExcludeSenderIDs})
+	}
+	if !filters.VisibleAt.IsZero() {
+		where = append(where, squirrel.Or{
+			squirrel.Eq{"expires_at": nil},
+			squirrel.Gt{"expires_at": filters.VisibleAt},
+		})
+	}
+	if filters.CreatedAfter != nil {
+		where = append(where, squirrel.Gt{"created_at": *filters.CreatedAfter})
+	}
+
 	// getting messages
 	query := squirrel.
 		Select("*").
 		From(MessagesTable).
-		Where(squirrel.Eq{"chat_id": filters.ChatID})
+		Where(where)
 
 	queryString, args, _ := query.
 		Limit(pagination.Limit).
@@ -105,7 +155,7 @@ func (m *MessagesPosgresql) GetAll(ctx context.Context, pagination models.DBPagi
 	query = squirrel.
 		Select("COUNT(*)").
 		From(MessagesTable).
-		Where(squirrel.Eq{"chat_id": filters.ChatID})
+		Where(where)
 
 	queryString, args, _ = query.
 		PlaceholderFormat(squirrel.Dollar).
@@ -123,10 +173,373 @@ func (m *MessagesPosgresql) GetAll(ctx context.Context, pagination models.DBPagi
 
 	return messages, count, nil
 }
-func (m *MessagesPosgresql) Delete(ctx context.Context, id int64) error {
+
+// GetUpdates returns messages newer than offset from all chats the user has joined,
+// except the ones sent by the user itself and the ones not visible at now.
+func (m *MessagesPosgresql) GetUpdates(ctx context.Context, userID int64, offset int64, limit uint64, now time.Time) ([]models.Message, error) {
+	joinedChats := squirrel.
+		Select("chat_id").
+		From(ChatUsersTable).
+		Where(squirrel.Eq{"user_id": userID})
+
 	query, args, _ := squirrel.
-		Delete(MessagesTable).
+		Select(MessagesTable + ".*").
+		From(MessagesTable).
+		Join(ChatsTable + " ON " + ChatsTable + ".id = " + MessagesTable + ".chat_id").
+		Where(squirrel.Gt{MessagesTable + ".id": offset}).
+		Where(squirrel.NotEq{MessagesTable + ".user_id": userID}).
+		Where(squirrel.Eq{MessagesTable + ".deleted_at": nil}).
+		Where(squirrel.Expr(MessagesTable+".chat_id IN (?)", joinedChats)).
+		Where(visibleMessages(now)).
+		OrderBy(MessagesTable + ".id").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var messages = make([]models.Message, 0)
+	if err := m.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"Message",
+			"GetUpdates",
+			query,
+			args,
+		)
+	}
+
+	return messages, nil
+}
+
+// GetBySender returns messages of the sender with id greater than afterID, including deleted ones.
+func (m *MessagesPosgresql) GetBySender(ctx context.Context, senderID int64, afterID int64, limit uint64) ([]models.Message, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(MessagesTable).
+		Where(squirrel.Eq{"user_id": senderID}).
+		Where(squirrel.Gt{"id": afterID}).
+		OrderBy("id").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var messages = make([]models.Message, 0)
+	if err := m.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"Message",
+			"GetBySender",
+			query,
+			args,
+		)
+	}
+
+	return messages, nil
+}
+
+// Iterate calls fn for every not deleted message of the chat in order they were sent,
+// expired messages are skipped the same as in GetAll. Rows are read one by one,
+// so the whole history is never loaded into memory.
+func (m *MessagesPosgresql) Iterate(ctx context.Context, filters models.GetMessagesFilters, fn func(models.ChatExportMessage) error) error {
+	where := squirrel.And{squirrel.Eq{"m.chat_id": filters.ChatID, "m.deleted_at": nil}}
+	if !filters.VisibleAt.IsZero() {
+		where = append(where, squirrel.Or{
+			squirrel.Eq{"m.expires_at": nil},
+			squirrel.Gt{"m.expires_at": filters.VisibleAt},
+		})
+	}
+	if filters.CreatedAfter != nil {
+		where = append(where, squirrel.Gt{"m.created_at": *filters.CreatedAfter})
+	}
+
+	query, args, _ := squirrel.
+		Select(
+			"m.id",
+			"m.user_id",
+			"COALESCE(m.sender_name, u.display_name) AS sender_name",
+			"m.text",
+			"m.created_at",
+		).
+		From(MessagesTable + " m").
+		Join(UsersTable + " u ON u.id = m.user_id").
+		Where(where).
+		OrderBy("m.id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	rows, err := m.db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Iterate",
+			query,
+			args,
+		)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var message models.ChatExportMessage
+		if err := rows.StructScan(&message); err != nil {
+			return apperror.NewDBError(
+				err,
+				"Message",
+				"Iterate",
+				query,
+				args,
+			)
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Iterate",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// GetExpired returns not deleted messages which are past their TTL or retention
+// period of their chat by now.
+func (m *MessagesPosgresql) GetExpired(ctx context.Context, now time.Time, limit uint64) ([]models.Message, error) {
+	query, args, _ := squirrel.
+		Select("m.*").
+		From(MessagesTable + " m").
+		Join(ChatsTable + " c ON c.id = m.chat_id").
+		Where(squirrel.Eq{"m.deleted_at": nil}).
+		Where(squirrel.Or{
+			squirrel.LtOrEq{"m.expires_at": now},
+			squirrel.Expr("m.created_at <= ?::timestamptz - make_interval(days => c.retention_days)", now),
+		}).
+		OrderBy("m.id").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var messages = make([]models.Message, 0)
+	if err := m.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"Message",
+			"GetExpired",
+			query,
+			args,
+		)
+	}
+
+	return messages, nil
+}
+
+// Expire soft-deletes expired messages and unpins them. Messages are deleted by
+// the system, so deleted_by is not set.
+func (m *MessagesPosgresql) Expire(ctx context.Context, ids []int64, deletedAt time.Time) (err error) {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "Message", "Expire", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Delete(PinnedMessagesTable).
+		Where(squirrel.Eq{"message_id": ids}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Expire",
+			query,
+			args,
+		)
+	}
+
+	query, args, _ = squirrel.
+		Update(MessagesTable).
+		Set("deleted_at", deletedAt).
+		Where(squirrel.Eq{"id": ids, "deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err = tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Expire",
+			query,
+			args,
+		)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "Message", "Expire", "COMMIT", nil)
+	}
+
+	return nil
+}
+
+// Delete marks the message as deleted, its content is kept until it is purged.
+func (m *MessagesPosgresql) Delete(ctx context.Context, id int64, deletedBy int64, deletedAt time.Time) error {
+	query, args, _ := squirrel.
+		Update(MessagesTable).
+		Set("deleted_at", deletedAt).
+		Set("deleted_by", deletedBy).
+		Where(squirrel.Eq{"id": id, "deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := m.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Delete",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (m *MessagesPosgresql) Restore(ctx context.Context, id int64) error {
+	query, args, _ := squirrel.
+		Update(MessagesTable).
+		Set("deleted_at", nil).
+		Set("deleted_by", nil).
 		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.NotEq{"deleted_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := m.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Restore",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (m *MessagesPosgresql) GetDeleted(ctx context.Context, pagination models.DBPagination, filters models.GetDeletedMessagesFilters) ([]models.Message, uint64, error) {
+	where := squirrel.And{squirrel.NotEq{"deleted_at": nil}}
+	if filters.ChatID != 0 {
+		where = append(where, squirrel.Eq{"chat_id": filters.ChatID})
+	}
+
+	// getting messages
+	queryString, args, _ := squirrel.
+		Select("*").
+		From(MessagesTable).
+		Where(where).
+		OrderBy("deleted_at DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var messages = make([]models.Message, 0)
+	if err := m.db.SelectContext(ctx, &messages, queryString, args...); err != nil {
+		return messages, count, apperror.NewDBError(
+			err,
+			"Message",
+			"GetDeleted",
+			queryString,
+			args,
+		)
+	}
+
+	// counting messages
+	queryString, args, _ = squirrel.
+		Select("COUNT(*)").
+		From(MessagesTable).
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := m.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return messages, count, apperror.NewDBError(
+			err,
+			"Message",
+			"GetDeleted",
+			queryString,
+			args,
+		)
+	}
+
+	return messages, count, nil
+}
+
+// Purge permanently removes at most limit messages deleted before the given time
+// and returns how many messages were removed.
+func (m *MessagesPosgresql) Purge(ctx context.Context, deletedBefore time.Time, limit uint64) (int64, error) {
+	batch := squirrel.
+		Select("id").
+		From(MessagesTable).
+		Where(squirrel.Lt{"deleted_at": deletedBefore}).
+		Limit(limit)
+
+	query, args, _ := squirrel.
+		Delete(MessagesTable).
+		Where(squirrel.Expr("id IN (?)", batch)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := m.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, apperror.NewDBError(
+			err,
+			"Message",
+			"Purge",
+			query,
+			args,
+		)
+	}
+
+	return result.RowsAffected()
+}
+
+// Pin pins the message, pinning already pinned message is no-op.
+func (m *MessagesPosgresql) Pin(ctx context.Context, pin models.CreatePinRecord) error {
+	query, args, _ := squirrel.
+		Insert(PinnedMessagesTable).
+		Columns(
+			"chat_id",
+			"message_id",
+			"pinned_by",
+			"pinned_at",
+		).
+		Values(
+			pin.ChatID,
+			pin.MessageID,
+			pin.PinnedBy,
+			pin.PinnedAt,
+		).
+		Suffix("ON CONFLICT (chat_id, message_id) DO NOTHING").
 		PlaceholderFormat(squirrel.Dollar).
 		ToSql()
 
@@ -134,11 +547,139 @@ func (m *MessagesPosgresql) Delete(ctx context.Context, id int64) error {
 		return apperror.NewDBError(
 			err,
 			"Message",
-			"GetByID",
+			"Pin",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (m *MessagesPosgresql) Unpin(ctx context.Context, chatID, messageID int64) error {
+	query, args, _ := squirrel.
+		Delete(PinnedMessagesTable).
+		Where(squirrel.Eq{"chat_id": chatID, "message_id": messageID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := m.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Message",
+			"Unpin",
 			query,
 			args,
 		)
 	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
 
 	return nil
 }
+
+// GetPinned returns pinned messages of the chat, recently pinned first. Messages
+// expired by visibleAt or created before createdAfter are excluded.
+func (m *MessagesPosgresql) GetPinned(ctx context.Context, chatID int64, visibleAt time.Time, createdAfter *time.Time) ([]models.Message, error) {
+	where := squirrel.And{
+		squirrel.Eq{PinnedMessagesTable + ".chat_id": chatID},
+		squirrel.Or{
+			squirrel.Eq{MessagesTable + ".expires_at": nil},
+			squirrel.Gt{MessagesTable + ".expires_at": visibleAt},
+		},
+	}
+	if createdAfter != nil {
+		where = append(where, squirrel.Gt{MessagesTable + ".created_at": *createdAfter})
+	}
+
+	query, args, _ := squirrel.
+		Select(MessagesTable + ".*").
+		From(MessagesTable).
+		Join(PinnedMessagesTable + " ON " + PinnedMessagesTable + ".message_id = " + MessagesTable + ".id").
+		Where(where).
+		OrderBy(PinnedMessagesTable + ".pinned_at DESC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var messages = make([]models.Message, 0)
+	if err := m.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"Message",
+			"GetPinned",
+			query,
+			args,
+		)
+	}
+
+	return messages, nil
+}
+
+func (m *MessagesPosgresql) CountPinned(ctx context.Context, chatID int64) (uint64, error) {
+	query, args, _ := squirrel.
+		Select("COUNT(*)").
+		From(PinnedMessagesTable).
+		Where(squirrel.Eq{"chat_id": chatID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	if err := m.db.GetContext(ctx, &count, query, args...); err != nil {
+		return count, apperror.NewDBError(
+			err,
+			"Message",
+			"CountPinned",
+			query,
+			args,
+		)
+	}
+
+	return count, nil
+}
+
+// GetRecentStats counts not deleted messages of the sender in the chat for spam heuristics.
+func (m *MessagesPosgresql) GetRecentStats(ctx context.Context, record models.GetRecentMessagesStatsRecord) (models.RecentMessagesStats, error) {
+	query, args, _ := squirrel.
+		Select().
+		Column(squirrel.Expr("COUNT(*) FILTER (WHERE created_at >= ?) AS messages", record.MessagesSince)).
+		Column(squirrel.Expr("COUNT(*) FILTER (WHERE created_at >= ? AND text = ?) AS repeated", record.RepeatedSince, record.Text)).
+		From(MessagesTable).
+		Where(squirrel.Eq{
+			"chat_id":    record.ChatID,
+			"user_id":    record.SenderID,
+			"deleted_at": nil,
+		}).
+		Where(squirrel.Expr("created_at >= LEAST(?::timestamptz, ?::timestamptz)", record.MessagesSince, record.RepeatedSince)).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var stats models.RecentMessagesStats
+	if err := m.db.GetContext(ctx, &stats, query, args...); err != nil {
+		return stats, apperror.NewDBError(
+			err,
+			"Message",
+			"GetRecentStats",
+			query,
+			args,
+		)
+	}
+
+	return stats, nil
+}
+
+// visibleMessages excludes messages past their TTL or retention period of their
+// chat, the same as expiration worker does. The query must join chats table.
+func visibleMessages(now time.Time) squirrel.And {
+	return squirrel.And{
+		squirrel.Or{
+			squirrel.Eq{MessagesTable + ".expires_at": nil},
+			squirrel.Gt{MessagesTable + ".expires_at": now},
+		},
+		squirrel.Or{
+			squirrel.Eq{ChatsTable + ".retention_days": nil},
+			squirrel.Expr(MessagesTable+".created_at > ?::timestamptz - make_interval(days => "+ChatsTable+".retention_days)", now),
+		},
+	}
+}
@@ -0,0 +1,307 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+)
+
+// PollPosgresql needs transactions, since poll is created together with its
+// message and options and vote replaces previous votes of the user.
+type PollPosgresql struct {
+	db *sqlx.DB
+}
+
+func NewPoll(psql PostgresqlRepository) *PollPosgresql {
+	return &PollPosgresql{
+		db: psql.db,
+	}
+}
+
+func (p *PollPosgresql) Create(ctx context.Context, poll models.CreatePollRecord) (created models.Message, err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return created, apperror.NewDBError(err, "Poll", "Create", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Insert(MessagesTable).
+		Columns(
+			"chat_id",
+			"user_id",
+			"text",
+			"created_at",
+			"expires_at",
+			"kind",
+		).
+		Values(
+			poll.Message.ChatID,
+			poll.Message.SenderID,
+			poll.Message.Text,
+			poll.Message.CreatedAt,
+			poll.Message.ExpiresAt,
+			models.MessageKindPoll,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := tx.GetContext(ctx, &created, query, args...); err != nil {
+		return created, apperror.NewDBError(err, "Poll", "Create", query, args)
+	}
+
+	query, args, _ = squirrel.
+		Insert(PollsTable).
+		Columns(
+			"message_id",
+			"question",
+			"multiple",
+			"anonymous",
+			"closes_at",
+			"created_at",
+		).
+		Values(
+			created.ID,
+			poll.Question,
+			poll.Multiple,
+			poll.Anonymous,
+			poll.ClosesAt,
+			poll.Message.CreatedAt,
+		).
+		Suffix("RETURNING id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var pollID int64
+	if err := tx.GetContext(ctx, &pollID, query, args...); err != nil {
+		return created, apperror.NewDBError(err, "Poll", "Create", query, args)
+	}
+
+	options := squirrel.
+		Insert(PollOptionsTable).
+		Columns("poll_id", "position", "text")
+	for i, option := range poll.Options {
+		options = options.Values(pollID, i, option)
+	}
+	query, args, _ = options.PlaceholderFormat(squirrel.Dollar).ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return created, apperror.NewDBError(err, "Poll", "Create", query, args)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return created, apperror.NewDBError(err, "Poll", "Create", "COMMIT", nil)
+	}
+
+	return created, nil
+}
+
+func (p *PollPosgresql) GetByID(ctx context.Context, id int64) (models.Poll, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(PollsTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var poll models.Poll
+	if err := p.db.GetContext(ctx, &poll, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return poll, apperror.ErrNotFound
+		default:
+			return poll, apperror.NewDBError(
+				err,
+				"Poll",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return poll, nil
+}
+
+func (p *PollPosgresql) GetByMessageIDs(ctx context.Context, messageIDs []int64) ([]models.Poll, error) {
+	polls := make([]models.Poll, 0)
+	if len(messageIDs) == 0 {
+		return polls, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(PollsTable).
+		Where(squirrel.Eq{"message_id": messageIDs}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &polls, query, args...); err != nil {
+		return polls, apperror.NewDBError(
+			err,
+			"Poll",
+			"GetByMessageIDs",
+			query,
+			args,
+		)
+	}
+
+	return polls, nil
+}
+
+func (p *PollPosgresql) GetOptions(ctx context.Context, pollIDs []int64) ([]models.PollOption, error) {
+	options := make([]models.PollOption, 0)
+	if len(pollIDs) == 0 {
+		return options, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(PollOptionsTable).
+		Where(squirrel.Eq{"poll_id": pollIDs}).
+		OrderBy("poll_id", "position").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &options, query, args...); err != nil {
+		return options, apperror.NewDBError(
+			err,
+			"Poll",
+			"GetOptions",
+			query,
+			args,
+		)
+	}
+
+	return options, nil
+}
+
+func (p *PollPosgresql) GetVotes(ctx context.Context, pollIDs []int64) ([]models.PollVote, error) {
+	votes := make([]models.PollVote, 0)
+	if len(pollIDs) == 0 {
+		return votes, nil
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(PollVotesTable).
+		Where(squirrel.Eq{"poll_id": pollIDs}).
+		OrderBy("created_at").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.SelectContext(ctx, &votes, query, args...); err != nil {
+		return votes, apperror.NewDBError(
+			err,
+			"Poll",
+			"GetVotes",
+			query,
+			args,
+		)
+	}
+
+	return votes, nil
+}
+
+// Vote replaces votes of the user in the poll with the chosen options.
+func (p *PollPosgresql) Vote(ctx context.Context, pollID, userID int64, optionIDs []int64, votedAt time.Time) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "Poll", "Vote", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Delete(PollVotesTable).
+		Where(squirrel.Eq{"poll_id": pollID, "user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "Poll", "Vote", query, args)
+	}
+
+	votes := squirrel.
+		Insert(PollVotesTable).
+		Columns("poll_id", "option_id", "user_id", "created_at")
+	for _, optionID := range optionIDs {
+		votes = votes.Values(pollID, optionID, userID, votedAt)
+	}
+	query, args, _ = votes.PlaceholderFormat(squirrel.Dollar).ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "Poll", "Vote", query, args)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "Poll", "Vote", "COMMIT", nil)
+	}
+
+	return nil
+}
+
+// Retract removes votes of the user, ErrNotFound is returned if the user has not voted.
+func (p *PollPosgresql) Retract(ctx context.Context, pollID, userID int64) error {
+	query, args, _ := squirrel.
+		Delete(PollVotesTable).
+		Where(squirrel.Eq{"poll_id": pollID, "user_id": userID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Poll",
+			"Retract",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// Close closes open poll, ErrNotFound is returned if it is already closed.
+func (p *PollPosgresql) Close(ctx context.Context, pollID int64, closedAt time.Time) error {
+	query, args, _ := squirrel.
+		Update(PollsTable).
+		Set("closed_at", closedAt).
+		Where(squirrel.Eq{"id": pollID, "closed_at": nil}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Poll",
+			"Close",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
@@ -12,10 +12,33 @@ import (
 )
 
 const (
-	UsersTable     = "users"
-	ChatsTable     = "chats"
-	ChatUsersTable = "chat_users"
-	MessagesTable  = "messages"
+	UsersTable              = "users"
+	ChatsTable              = "chats"
+	ChatUsersTable          = "chat_users"
+	MessagesTable           = "messages"
+	BotTokensTable          = "bot_tokens"
+	ChatWebhooksTable       = "chat_webhooks"
+	WebhookDeliveriesTable  = "webhook_deliveries"
+	IncomingWebhooksTable   = "incoming_webhooks"
+	BotCommandsTable        = "bot_commands"
+	MentionsTable           = "mentions"
+	PinnedMessagesTable     = "pinned_messages"
+	UserBlocksTable         = "user_blocks"
+	ReportsTable            = "reports"
+	ModerationActionsTable  = "moderation_actions"
+	DataExportsTable        = "data_exports"
+	MessageAttachmentsTable = "message_attachments"
+	ScheduledMessagesTable  = "scheduled_messages"
+	PollsTable              = "polls"
+	PollOptionsTable        = "poll_options"
+	PollVotesTable          = "poll_votes"
+	LinkPreviewsTable       = "link_previews"
+	DraftsTable             = "drafts"
+	RateLimitsTable         = "rate_limits"
+	FilterRulesTable        = "filter_rules"
+	FilterHitsTable         = "filter_hits"
+	CategoriesTable         = "categories"
+	ChatCategoriesTable     = "chat_categories"
 )
 
 func GetPgError(err error) *pgconn.PgError {
@@ -31,6 +54,7 @@ type DB interface {
 	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
 	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
 	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
 }
 
 type Config struct {
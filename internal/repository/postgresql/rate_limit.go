@@ -0,0 +1,100 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/ratelimit"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type RateLimitPosgresql struct {
+	db DB
+}
+
+func NewRateLimit(db DB) *RateLimitPosgresql {
+	return &RateLimitPosgresql{
+		db: db,
+	}
+}
+
+// Allow updates arrival time of the key with a single statement, so concurrent
+// requests of several instances can't exceed the limit.
+func (p *RateLimitPosgresql) Allow(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (time.Duration, error) {
+	interval := limit.Interval().Microseconds()
+	first, _ := ratelimit.Next(time.Time{}, limit, now)
+
+	query, args, _ := squirrel.
+		Insert(RateLimitsTable).
+		Columns("key", "tat").
+		Values(key, first).
+		Suffix(`ON CONFLICT (key) DO UPDATE
+			SET tat = GREATEST(rate_limits.tat, ?::timestamptz) + ?::bigint * INTERVAL '1 microsecond'
+			WHERE GREATEST(rate_limits.tat, ?::timestamptz) + ?::bigint * INTERVAL '1 microsecond' - ?::bigint * INTERVAL '1 microsecond' <= ?
+			RETURNING tat`,
+			now, interval, now, interval, limit.Period.Microseconds(), now,
+		).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var tat time.Time
+	err := p.db.GetContext(ctx, &tat, query, args...)
+	if err == nil {
+		return 0, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, apperror.NewDBError(
+			err,
+			"RateLimit",
+			"Allow",
+			query,
+			args,
+		)
+	}
+
+	// the limit is exceeded, row is not updated
+	query, args, _ = squirrel.
+		Select("tat").
+		From(RateLimitsTable).
+		Where(squirrel.Eq{"key": key}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &tat, query, args...); err != nil {
+		return 0, apperror.NewDBError(
+			err,
+			"RateLimit",
+			"Allow",
+			query,
+			args,
+		)
+	}
+
+	_, allowedAt := ratelimit.Next(tat, limit, now)
+
+	return max(allowedAt.Sub(now), 0), nil
+}
+
+func (p *RateLimitPosgresql) Release(ctx context.Context, key string, limit ratelimit.Limit) error {
+	query, args, _ := squirrel.
+		Update(RateLimitsTable).
+		Set("tat", squirrel.Expr("tat - ?::bigint * INTERVAL '1 microsecond'", limit.Interval().Microseconds())).
+		Where(squirrel.Eq{"key": key}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"RateLimit",
+			"Release",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/jackc/pgerrcode"
+)
+
+type ReportPosgresql struct {
+	db DB
+}
+
+func NewReport(db DB) *ReportPosgresql {
+	return &ReportPosgresql{
+		db: db,
+	}
+}
+
+func (p *ReportPosgresql) Create(ctx context.Context, report models.CreateReportRecord) (models.Report, error) {
+	query, args, _ := squirrel.
+		Insert(ReportsTable).
+		Columns(
+			"reporter_id",
+			"target_type",
+			"message_id",
+			"user_id",
+			"reason",
+			"comment",
+			"status",
+			"created_at",
+		).
+		Values(
+			report.ReporterID,
+			report.TargetType,
+			report.MessageID,
+			report.UserID,
+			report.Reason,
+			report.Comment,
+			models.ReportStatusOpen,
+			report.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.Report
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		pgErr := GetPgError(err)
+		if pgErr != nil && pgErr.Code == pgerrcode.UniqueViolation {
+			return created, models.ErrReportExists
+		}
+
+		return created, apperror.NewDBError(
+			err,
+			"Report",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *ReportPosgresql) GetByID(ctx context.Context, id int64) (models.Report, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(ReportsTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var report models.Report
+	if err := p.db.GetContext(ctx, &report, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return report, apperror.ErrNotFound
+		default:
+			return report, apperror.NewDBError(
+				err,
+				"Report",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return report, nil
+}
+
+func (p *ReportPosgresql) GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetReportsFilters) ([]models.Report, uint64, error) {
+	where := squirrel.And{}
+	if filters.Status != "" {
+		where = append(where, squirrel.Eq{"status": filters.Status})
+	}
+
+	// getting reports, the oldest open reports are handled first
+	queryString, args, _ := squirrel.
+		Select("*").
+		From(ReportsTable).
+		Where(where).
+		OrderBy("id").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var reports = make([]models.Report, 0)
+	if err := p.db.SelectContext(ctx, &reports, queryString, args...); err != nil {
+		return reports, count, apperror.NewDBError(
+			err,
+			"Report",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	// counting reports
+	queryString, args, _ = squirrel.
+		Select("COUNT(*)").
+		From(ReportsTable).
+		Where(where).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return reports, count, apperror.NewDBError(
+			err,
+			"Report",
+			"GetAll",
+			queryString,
+			args,
+		)
+	}
+
+	return reports, count, nil
+}
+
+// Resolve closes open report, apperror.ErrNotFound is returned if report is not open.
+func (p *ReportPosgresql) Resolve(ctx context.Context, report models.ResolveReportRecord) error {
+	query, args, _ := squirrel.
+		Update(ReportsTable).
+		Set("status", report.Status).
+		Set("resolved_by", report.ResolvedBy).
+		Set("resolved_at", report.ResolvedAt).
+		Where(squirrel.Eq{"id": report.ID, "status": models.ReportStatusOpen}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"Report",
+			"Resolve",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *ReportPosgresql) CreateAction(ctx context.Context, action models.CreateModerationActionRecord) error {
+	query, args, _ := squirrel.
+		Insert(ModerationActionsTable).
+		Columns(
+			"admin_id",
+			"action",
+			"report_id",
+			"target_user_id",
+			"target_message_id",
+			"comment",
+			"created_at",
+		).
+		Values(
+			action.AdminID,
+			action.Action,
+			action.ReportID,
+			action.TargetUserID,
+			action.TargetMessageID,
+			action.Comment,
+			action.CreatedAt,
+		).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Report",
+			"CreateAction",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *ReportPosgresql) GetActions(ctx context.Context, pagination models.DBPagination) ([]models.ModerationActionRecord, uint64, error) {
+	// getting actions
+	queryString, args, _ := squirrel.
+		Select("*").
+		From(ModerationActionsTable).
+		OrderBy("id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var actions = make([]models.ModerationActionRecord, 0)
+	if err := p.db.SelectContext(ctx, &actions, queryString, args...); err != nil {
+		return actions, count, apperror.NewDBError(
+			err,
+			"Report",
+			"GetActions",
+			queryString,
+			args,
+		)
+	}
+
+	// counting actions
+	queryString, args, _ = squirrel.
+		Select("COUNT(*)").
+		From(ModerationActionsTable).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return actions, count, apperror.NewDBError(
+			err,
+			"Report",
+			"GetActions",
+			queryString,
+			args,
+		)
+	}
+
+	return actions, count, nil
+}
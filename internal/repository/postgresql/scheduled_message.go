@@ -0,0 +1,217 @@
+package postgresql
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type ScheduledMessagePosgresql struct {
+	db DB
+}
+
+func NewScheduledMessage(db DB) *ScheduledMessagePosgresql {
+	return &ScheduledMessagePosgresql{
+		db: db,
+	}
+}
+
+func (p *ScheduledMessagePosgresql) Create(ctx context.Context, message models.CreateScheduledMessageRecord) (models.ScheduledMessage, error) {
+	query, args, _ := squirrel.
+		Insert(ScheduledMessagesTable).
+		Columns(
+			"chat_id",
+			"user_id",
+			"text",
+			"send_at",
+			"status",
+			"created_at",
+			"updated_at",
+		).
+		Values(
+			message.ChatID,
+			message.SenderID,
+			message.Text,
+			message.SendAt,
+			models.ScheduledMessagePending,
+			message.CreatedAt,
+			message.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.ScheduledMessage
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		return created, apperror.NewDBError(
+			err,
+			"ScheduledMessage",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *ScheduledMessagePosgresql) GetByID(ctx context.Context, id int64) (models.ScheduledMessage, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(ScheduledMessagesTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var message models.ScheduledMessage
+	if err := p.db.GetContext(ctx, &message, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return message, apperror.ErrNotFound
+		default:
+			return message, apperror.NewDBError(
+				err,
+				"ScheduledMessage",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return message, nil
+}
+
+// GetPendingByUser returns messages the user has scheduled and which are not sent yet.
+func (p *ScheduledMessagePosgresql) GetPendingByUser(ctx context.Context, userID int64, filters models.GetScheduledMessagesFilters) ([]models.ScheduledMessage, error) {
+	where := squirrel.Eq{"user_id": userID, "status": models.ScheduledMessagePending}
+	if filters.ChatID != 0 {
+		where["chat_id"] = filters.ChatID
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(ScheduledMessagesTable).
+		Where(where).
+		OrderBy("send_at", "id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	messages := make([]models.ScheduledMessage, 0)
+	if err := p.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"ScheduledMessage",
+			"GetPendingByUser",
+			query,
+			args,
+		)
+	}
+
+	return messages, nil
+}
+
+// ClaimDue marks pending messages which should be sent by now as sending and
+// returns them, the earliest first. Messages claimed by other instances are skipped.
+func (p *ScheduledMessagePosgresql) ClaimDue(ctx context.Context, now time.Time, limit uint64) ([]models.ScheduledMessage, error) {
+	due := squirrel.
+		Select("id").
+		From(ScheduledMessagesTable).
+		Where(squirrel.Eq{"status": models.ScheduledMessagePending}).
+		Where(squirrel.LtOrEq{"send_at": now}).
+		OrderBy("send_at", "id").
+		Limit(limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	query, args, _ := squirrel.
+		Update(ScheduledMessagesTable).
+		Set("status", models.ScheduledMessageSending).
+		Set("updated_at", now).
+		Where(squirrel.Expr("id IN (?)", due)).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	messages := make([]models.ScheduledMessage, 0)
+	if err := p.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return messages, apperror.NewDBError(
+			err,
+			"ScheduledMessage",
+			"ClaimDue",
+			query,
+			args,
+		)
+	}
+
+	// updated rows are returned in no particular order
+	slices.SortFunc(messages, func(a, b models.ScheduledMessage) int {
+		return cmp.Or(a.SendAt.Compare(b.SendAt), cmp.Compare(a.ID, b.ID))
+	})
+
+	return messages, nil
+}
+
+// Update changes pending message, ErrNotFound is returned if it is not pending anymore.
+func (p *ScheduledMessagePosgresql) Update(ctx context.Context, message models.UpdateScheduledMessageRecord) error {
+	query, args, _ := squirrel.
+		Update(ScheduledMessagesTable).
+		Set("text", message.Text).
+		Set("send_at", message.SendAt).
+		Set("updated_at", message.UpdatedAt).
+		Where(squirrel.Eq{"id": message.ID, "status": models.ScheduledMessagePending}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"ScheduledMessage",
+			"Update",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+// SetStatus finishes claimed message or gives it back, ErrNotFound is returned
+// if it is not claimed.
+func (p *ScheduledMessagePosgresql) SetStatus(ctx context.Context, message models.SetScheduledMessageStatusRecord) error {
+	query, args, _ := squirrel.
+		Update(ScheduledMessagesTable).
+		Set("status", message.Status).
+		Set("message_id", message.MessageID).
+		Set("error", message.Error).
+		Set("updated_at", message.UpdatedAt).
+		Where(squirrel.Eq{"id": message.ID, "status": models.ScheduledMessageSending}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"ScheduledMessage",
+			"SetStatus",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
@@ -4,21 +4,25 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"spsu-chat/internal/apperror"
 	"spsu-chat/internal/models"
 
 	"github.com/Masterminds/squirrel"
 	"github.com/jackc/pgerrcode"
+	"github.com/jmoiron/sqlx"
 )
 
+// UserPosgresql needs transactions, since deleted account leaves all chats
+// together with erasing its data.
 type UserPosgresql struct {
-	db DB
+	db *sqlx.DB
 }
 
-func NewUser(db DB) *UserPosgresql {
+func NewUser(psql PostgresqlRepository) *UserPosgresql {
 	return &UserPosgresql{
-		db: db,
+		db: psql.db,
 	}
 }
 
@@ -159,3 +163,190 @@ func (p *UserPosgresql) GetAll(ctx context.Context, pagination models.DBPaginati
 
 	return users, count, nil
 }
+
+// Search returns usable accounts matching the query by prefix of username or
+// display name words, or by trigram similarity. Exact username goes first,
+// then prefix matches, then the most similar ones.
+func (p *UserPosgresql) Search(ctx context.Context, search models.SearchUsersRecord) ([]models.PublicUser, error) {
+	prefix := escapeLike(search.Query) + "%"
+
+	query, args, _ := squirrel.
+		Select("id", "username", "display_name", "type").
+		From(UsersTable).
+		Where(squirrel.NotEq{"type": models.UserTypePlaceholder}).
+		Where(squirrel.Or{
+			squirrel.Eq{"status": models.UserStatusActive},
+			// suspension is over, but status is not updated yet
+			squirrel.And{
+				squirrel.Eq{"status": models.UserStatusSuspended},
+				squirrel.LtOrEq{"suspended_until": search.Now},
+			},
+		}).
+		Where(squirrel.Or{
+			squirrel.Expr("username ILIKE ?", prefix),
+			squirrel.Expr("display_name ILIKE ?", prefix),
+			squirrel.Expr("display_name ILIKE ?", "% "+prefix),
+			squirrel.Expr("username % ?", search.Query),
+			squirrel.Expr("display_name % ?", search.Query),
+		}).
+		OrderByClause("LOWER(username) = LOWER(?) DESC", search.Query).
+		OrderByClause("username ILIKE ? DESC", prefix).
+		OrderByClause("(display_name ILIKE ? OR display_name ILIKE ?) DESC", prefix, "% "+prefix).
+		OrderByClause("GREATEST(similarity(username, ?), similarity(display_name, ?)) DESC", search.Query, search.Query).
+		OrderBy("username").
+		Limit(search.Limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	users := make([]models.PublicUser, 0)
+	if err := p.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return users, apperror.NewDBError(
+			err,
+			"User",
+			"Search",
+			query,
+			args,
+		)
+	}
+
+	return users, nil
+}
+
+func (p *UserPosgresql) UpdateLastSeen(ctx context.Context, id int64, lastSeenAt time.Time) error {
+	query, args, _ := squirrel.
+		Update(UsersTable).
+		Set("last_seen_at", lastSeenAt).
+		Where(squirrel.Eq{"id": id}).
+		Where(squirrel.Or{
+			squirrel.Eq{"last_seen_at": nil},
+			squirrel.Lt{"last_seen_at": lastSeenAt},
+		}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"User",
+			"UpdateLastSeen",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *UserPosgresql) SetHidePresence(ctx context.Context, id int64, hide bool) error {
+	query, args, _ := squirrel.
+		Update(UsersTable).
+		Set("hide_presence", hide).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"User",
+			"SetHidePresence",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// SetStatus changes account status, suspension end is reset.
+func (p *UserPosgresql) SetStatus(ctx context.Context, id int64, status models.UserStatus) error {
+	query, args, _ := squirrel.
+		Update(UsersTable).
+		Set("status", status).
+		Set("suspended_until", nil).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"User",
+			"SetStatus",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *UserPosgresql) Suspend(ctx context.Context, id int64, until time.Time) error {
+	query, args, _ := squirrel.
+		Update(UsersTable).
+		Set("status", models.UserStatusSuspended).
+		Set("suspended_until", until).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"User",
+			"Suspend",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// Anonymize marks the account as deleted, erases its personal data and
+// credentials and removes the user from all chats.
+func (p *UserPosgresql) Anonymize(ctx context.Context, user models.AnonymizeUserRecord) (err error) {
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewDBError(err, "User", "Anonymize", "BEGIN", nil)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	query, args, _ := squirrel.
+		Update(UsersTable).
+		Set("username", user.Username).
+		Set("display_name", user.DisplayName).
+		Set("password_hash", []byte{}).
+		Set("status", models.UserStatusDeleted).
+		Set("suspended_until", nil).
+		Set("last_seen_at", nil).
+		Set("hide_presence", true).
+		Set("deleted_at", user.DeletedAt).
+		Where(squirrel.Eq{"id": user.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "User", "Anonymize", query, args)
+	}
+
+	query, args, _ = squirrel.
+		Delete(ChatUsersTable).
+		Where(squirrel.Eq{"user_id": user.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(err, "User", "Anonymize", query, args)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return apperror.NewDBError(err, "User", "Anonymize", "COMMIT", nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type UserBlockPosgresql struct {
+	db DB
+}
+
+func NewUserBlock(db DB) *UserBlockPosgresql {
+	return &UserBlockPosgresql{
+		db: db,
+	}
+}
+
+// Block blocks the user, blocking already blocked user is no-op.
+func (p *UserBlockPosgresql) Block(ctx context.Context, block models.CreateUserBlockRecord) error {
+	query, args, _ := squirrel.
+		Insert(UserBlocksTable).
+		Columns(
+			"blocker_id",
+			"blocked_id",
+			"created_at",
+		).
+		Values(
+			block.BlockerID,
+			block.BlockedID,
+			block.CreatedAt,
+		).
+		Suffix("ON CONFLICT (blocker_id, blocked_id) DO NOTHING").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"UserBlock",
+			"Block",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *UserBlockPosgresql) Unblock(ctx context.Context, blockerID, blockedID int64) error {
+	query, args, _ := squirrel.
+		Delete(UserBlocksTable).
+		Where(squirrel.Eq{"blocker_id": blockerID, "blocked_id": blockedID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	result, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return apperror.NewDBError(
+			err,
+			"UserBlock",
+			"Unblock",
+			query,
+			args,
+		)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return apperror.ErrNotFound
+	}
+
+	return nil
+}
+
+func (p *UserBlockPosgresql) IsBlocked(ctx context.Context, blockerID, blockedID int64) (bool, error) {
+	query, args, _ := squirrel.
+		Select("blocker_id").
+		From(UserBlocksTable).
+		Where(squirrel.Eq{"blocker_id": blockerID, "blocked_id": blockedID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var id int64
+	if err := p.db.GetContext(ctx, &id, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, nil
+		default:
+			return false, apperror.NewDBError(
+				err,
+				"UserBlock",
+				"IsBlocked",
+				query,
+				args,
+			)
+		}
+	}
+
+	return true, nil
+}
+
+// GetBlocked returns users blocked by the blocker, recently blocked first.
+func (p *UserBlockPosgresql) GetBlocked(ctx context.Context, blockerID int64) ([]models.User, error) {
+	query, args, _ := squirrel.
+		Select(UsersTable + ".*").
+		From(UsersTable).
+		Join(UserBlocksTable + " ON " + UserBlocksTable + ".blocked_id = " + UsersTable + ".id").
+		Where(squirrel.Eq{UserBlocksTable + ".blocker_id": blockerID}).
+		OrderBy(UserBlocksTable + ".created_at DESC").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var users = make([]models.User, 0)
+	if err := p.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return users, apperror.NewDBError(
+			err,
+			"UserBlock",
+			"GetBlocked",
+			query,
+			args,
+		)
+	}
+
+	return users, nil
+}
+
+func (p *UserBlockPosgresql) GetBlockerIDs(ctx context.Context, blockedID int64) ([]int64, error) {
+	query, args, _ := squirrel.
+		Select("blocker_id").
+		From(UserBlocksTable).
+		Where(squirrel.Eq{"blocked_id": blockedID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var ids = make([]int64, 0)
+	if err := p.db.SelectContext(ctx, &ids, query, args...); err != nil {
+		return ids, apperror.NewDBError(
+			err,
+			"UserBlock",
+			"GetBlockerIDs",
+			query,
+			args,
+		)
+	}
+
+	return ids, nil
+}
+
+func (p *UserBlockPosgresql) GetBlockedIDs(ctx context.Context, blockerID int64) ([]int64, error) {
+	query, args, _ := squirrel.
+		Select("blocked_id").
+		From(UserBlocksTable).
+		Where(squirrel.Eq{"blocker_id": blockerID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var ids = make([]int64, 0)
+	if err := p.db.SelectContext(ctx, &ids, query, args...); err != nil {
+		return ids, apperror.NewDBError(
+			err,
+			"UserBlock",
+			"GetBlockedIDs",
+			query,
+			args,
+		)
+	}
+
+	return ids, nil
+}
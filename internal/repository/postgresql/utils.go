@@ -1,5 +1,7 @@
 package postgresql
 
+import "strings"
+
 func FormatINClause(field string, count int) string {
 	query := field + " IN ("
 
@@ -13,3 +15,10 @@ func FormatINClause(field string, count int) string {
 
 	return query
 }
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike escapes wildcards of LIKE pattern, so the value is matched literally.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
@@ -0,0 +1,326 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+
+	"github.com/Masterminds/squirrel"
+)
+
+type WebhookPosgresql struct {
+	db DB
+}
+
+func NewWebhook(db DB) *WebhookPosgresql {
+	return &WebhookPosgresql{
+		db: db,
+	}
+}
+
+func (p *WebhookPosgresql) Create(ctx context.Context, webhook models.CreateWebhookRecord) (models.Webhook, error) {
+	query, args, _ := squirrel.
+		Insert(ChatWebhooksTable).
+		Columns(
+			"chat_id",
+			"creator_id",
+			"url",
+			"secret",
+			"created_at",
+		).
+		Values(
+			webhook.ChatID,
+			webhook.CreatorID,
+			webhook.URL,
+			webhook.Secret,
+			webhook.CreatedAt,
+		).
+		Suffix("RETURNING *").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var created models.Webhook
+	if err := p.db.GetContext(ctx, &created, query, args...); err != nil {
+		return created, apperror.NewDBError(
+			err,
+			"Webhook",
+			"Create",
+			query,
+			args,
+		)
+	}
+
+	return created, nil
+}
+
+func (p *WebhookPosgresql) GetByID(ctx context.Context, id int64) (models.Webhook, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(ChatWebhooksTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhook models.Webhook
+	if err := p.db.GetContext(ctx, &webhook, query, args...); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return webhook, apperror.ErrNotFound
+		default:
+			return webhook, apperror.NewDBError(
+				err,
+				"Webhook",
+				"GetByID",
+				query,
+				args,
+			)
+		}
+	}
+
+	return webhook, nil
+}
+
+func (p *WebhookPosgresql) GetAllByChat(ctx context.Context, chatID int64, onlyActive bool) ([]models.Webhook, error) {
+	where := squirrel.Eq{"chat_id": chatID}
+	if onlyActive {
+		where["is_active"] = true
+	}
+
+	query, args, _ := squirrel.
+		Select("*").
+		From(ChatWebhooksTable).
+		Where(where).
+		OrderBy("id").
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var webhooks = make([]models.Webhook, 0)
+	if err := p.db.SelectContext(ctx, &webhooks, query, args...); err != nil {
+		return webhooks, apperror.NewDBError(
+			err,
+			"Webhook",
+			"GetAllByChat",
+			query,
+			args,
+		)
+	}
+
+	return webhooks, nil
+}
+
+func (p *WebhookPosgresql) Delete(ctx context.Context, id int64) error {
+	query, args, _ := squirrel.
+		Delete(ChatWebhooksTable).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Webhook",
+			"Delete",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// RecordSuccess resets consecutive failures counter of the webhook.
+func (p *WebhookPosgresql) RecordSuccess(ctx context.Context, id int64) error {
+	query, args, _ := squirrel.
+		Update(ChatWebhooksTable).
+		Set("failure_count", 0).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Webhook",
+			"RecordSuccess",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// RecordFailure increments consecutive failures counter of the webhook
+// and disables it when the counter reaches maxFailures.
+func (p *WebhookPosgresql) RecordFailure(ctx context.Context, id int64, maxFailures int, now time.Time) error {
+	query, args, _ := squirrel.
+		Update(ChatWebhooksTable).
+		Set("failure_count", squirrel.Expr("failure_count + 1")).
+		Set("is_active", squirrel.Expr("failure_count + 1 < ?", maxFailures)).
+		Set("disabled_at", squirrel.Expr("CASE WHEN failure_count + 1 < ? THEN disabled_at ELSE ?::timestamptz END", maxFailures, now)).
+		Where(squirrel.Eq{"id": id}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Webhook",
+			"RecordFailure",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *WebhookPosgresql) CreateDeliveries(ctx context.Context, deliveries []models.CreateWebhookDeliveryRecord) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	insert := squirrel.
+		Insert(WebhookDeliveriesTable).
+		Columns(
+			"webhook_id",
+			"event",
+			"payload",
+			"status",
+			"next_attempt_at",
+			"created_at",
+		)
+	for _, delivery := range deliveries {
+		insert = insert.Values(
+			delivery.WebhookID,
+			delivery.Event,
+			delivery.Payload,
+			models.WebhookDeliveryPending,
+			delivery.CreatedAt,
+			delivery.CreatedAt,
+		)
+	}
+
+	query, args, _ := insert.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Webhook",
+			"CreateDeliveries",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+// GetDueDeliveries returns pending deliveries which next attempt time has come.
+func (p *WebhookPosgresql) GetDueDeliveries(ctx context.Context, now time.Time, limit uint64) ([]models.WebhookDelivery, error) {
+	query, args, _ := squirrel.
+		Select("*").
+		From(WebhookDeliveriesTable).
+		Where(squirrel.Eq{"status": models.WebhookDeliveryPending}).
+		Where(squirrel.LtOrEq{"next_attempt_at": now}).
+		OrderBy("next_attempt_at").
+		Limit(limit).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var deliveries = make([]models.WebhookDelivery, 0)
+	if err := p.db.SelectContext(ctx, &deliveries, query, args...); err != nil {
+		return deliveries, apperror.NewDBError(
+			err,
+			"Webhook",
+			"GetDueDeliveries",
+			query,
+			args,
+		)
+	}
+
+	return deliveries, nil
+}
+
+func (p *WebhookPosgresql) UpdateDelivery(ctx context.Context, delivery models.UpdateWebhookDeliveryRecord) error {
+	query, args, _ := squirrel.
+		Update(WebhookDeliveriesTable).
+		SetMap(map[string]interface{}{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"response_code":   delivery.ResponseCode,
+			"last_error":      delivery.LastError,
+			"delivered_at":    delivery.DeliveredAt,
+		}).
+		Where(squirrel.Eq{"id": delivery.ID}).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if _, err := p.db.ExecContext(ctx, query, args...); err != nil {
+		return apperror.NewDBError(
+			err,
+			"Webhook",
+			"UpdateDelivery",
+			query,
+			args,
+		)
+	}
+
+	return nil
+}
+
+func (p *WebhookPosgresql) GetDeliveries(ctx context.Context, webhookID int64, pagination models.DBPagination) ([]models.WebhookDelivery, uint64, error) {
+	// getting deliveries
+	query := squirrel.
+		Select("*").
+		From(WebhookDeliveriesTable).
+		Where(squirrel.Eq{"webhook_id": webhookID})
+
+	queryString, args, _ := query.
+		OrderBy("id DESC").
+		Limit(pagination.Limit).
+		Offset(pagination.Offset).
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	var count uint64
+	var deliveries = make([]models.WebhookDelivery, 0)
+	if err := p.db.SelectContext(ctx, &deliveries, queryString, args...); err != nil {
+		return deliveries, count, apperror.NewDBError(
+			err,
+			"Webhook",
+			"GetDeliveries",
+			queryString,
+			args,
+		)
+	}
+
+	// counting deliveries
+	query = squirrel.
+		Select("COUNT(*)").
+		From(WebhookDeliveriesTable).
+		Where(squirrel.Eq{"webhook_id": webhookID})
+
+	queryString, args, _ = query.
+		PlaceholderFormat(squirrel.Dollar).
+		ToSql()
+
+	if err := p.db.GetContext(ctx, &count, queryString, args...); err != nil {
+		return deliveries, count, apperror.NewDBError(
+			err,
+			"Webhook",
+			"GetDeliveries",
+			queryString,
+			args,
+		)
+	}
+
+	return deliveries, count, nil
+}
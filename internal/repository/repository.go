@@ -2,8 +2,11 @@ package repository
 
 import (
 	"context"
+	"time"
+
 	"spsu-chat/internal/logger"
 	"spsu-chat/internal/models"
+	"spsu-chat/internal/ratelimit"
 	"spsu-chat/internal/repository/postgresql"
 )
 
@@ -12,34 +15,236 @@ type User interface {
 	GetByID(ctx context.Context, id int64) (models.User, error)
 	GetByUsername(ctx context.Context, username string) (models.User, error)
 	GetAll(ctx context.Context, pagination models.DBPagination) ([]models.User, uint64, error)
+	Search(ctx context.Context, search models.SearchUsersRecord) ([]models.PublicUser, error)
+	UpdateLastSeen(ctx context.Context, id int64, lastSeenAt time.Time) error
+	SetHidePresence(ctx context.Context, id int64, hide bool) error
+	SetStatus(ctx context.Context, id int64, status models.UserStatus) error
+	Suspend(ctx context.Context, id int64, until time.Time) error
+	Anonymize(ctx context.Context, user models.AnonymizeUserRecord) error
 }
 
 type Chat interface {
 	Create(ctx context.Context, chat models.CreateChatRecord) error
 	GetByID(ctx context.Context, id int64) (models.Chat, error)
-	GetAll(ctx context.Context, pagination models.DBPagination) ([]models.Chat, uint64, error)
+	GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetChatsFilters) ([]models.Chat, uint64, error)
 	IsUserInChat(ctx context.Context, chatID, userID int64) (bool, error)
 	JoinUser(ctx context.Context, chatID int64, userID int64) error
 	LeaveUser(ctx context.Context, chatID int64, userID int64) error
+	GetMember(ctx context.Context, chatID, userID int64) (models.ChatMember, error)
+	SetMemberRole(ctx context.Context, chatID, userID int64, role models.ChatRole) error
+	UpdateTopic(ctx context.Context, chatID int64, topic string) error
+	UpdateSettings(ctx context.Context, chatID int64, settings models.UpdateChatSettingsInput) error
+	GetMemberIDs(ctx context.Context, chatID int64) ([]int64, error)
+	GetMemberships(ctx context.Context, userID int64) ([]models.ChatMembership, error)
 }
 
 type Message interface {
-	Create(ctx context.Context, message models.CreateMessageRecord) error
+	Create(ctx context.Context, message models.CreateMessageRecord) (models.Message, error)
 	GetByID(ctx context.Context, id int64) (models.Message, error)
 	GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetMessagesFilters) ([]models.Message, uint64, error)
+	GetUpdates(ctx context.Context, userID int64, offset int64, limit uint64, now time.Time) ([]models.Message, error)
+	GetBySender(ctx context.Context, senderID int64, afterID int64, limit uint64) ([]models.Message, error)
+	Iterate(ctx context.Context, filters models.GetMessagesFilters, fn func(models.ChatExportMessage) error) error
+	Delete(ctx context.Context, id int64, deletedBy int64, deletedAt time.Time) error
+	Restore(ctx context.Context, id int64) error
+	GetDeleted(ctx context.Context, pagination models.DBPagination, filters models.GetDeletedMessagesFilters) ([]models.Message, uint64, error)
+	Purge(ctx context.Context, deletedBefore time.Time, limit uint64) (int64, error)
+	GetExpired(ctx context.Context, now time.Time, limit uint64) ([]models.Message, error)
+	Expire(ctx context.Context, ids []int64, deletedAt time.Time) error
+	Pin(ctx context.Context, pin models.CreatePinRecord) error
+	Unpin(ctx context.Context, chatID, messageID int64) error
+	GetPinned(ctx context.Context, chatID int64, visibleAt time.Time, createdAfter *time.Time) ([]models.Message, error)
+	CountPinned(ctx context.Context, chatID int64) (uint64, error)
+	GetRecentStats(ctx context.Context, record models.GetRecentMessagesStatsRecord) (models.RecentMessagesStats, error)
+}
+
+type Bot interface {
+	CreateToken(ctx context.Context, token models.CreateBotTokenRecord) error
+	GetActiveToken(ctx context.Context, tokenHash []byte) (models.BotToken, error)
+	RevokeTokens(ctx context.Context, botID int64, revokedAt time.Time) error
+	SetCommands(ctx context.Context, botID int64, commands []models.BotCommand) error
+	GetCommands(ctx context.Context, botID int64) ([]models.BotCommand, error)
+	GetChatCommands(ctx context.Context, chatID int64) ([]models.BotCommand, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, webhook models.CreateWebhookRecord) (models.Webhook, error)
+	GetByID(ctx context.Context, id int64) (models.Webhook, error)
+	GetAllByChat(ctx context.Context, chatID int64, onlyActive bool) ([]models.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	RecordSuccess(ctx context.Context, id int64) error
+	RecordFailure(ctx context.Context, id int64, maxFailures int, now time.Time) error
+	CreateDeliveries(ctx context.Context, deliveries []models.CreateWebhookDeliveryRecord) error
+	GetDueDeliveries(ctx context.Context, now time.Time, limit uint64) ([]models.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery models.UpdateWebhookDeliveryRecord) error
+	GetDeliveries(ctx context.Context, webhookID int64, pagination models.DBPagination) ([]models.WebhookDelivery, uint64, error)
+}
+
+type IncomingWebhook interface {
+	Create(ctx context.Context, webhook models.CreateIncomingWebhookRecord) (models.IncomingWebhook, error)
+	GetByID(ctx context.Context, id int64) (models.IncomingWebhook, error)
+	GetActiveByTokenHash(ctx context.Context, tokenHash []byte) (models.IncomingWebhook, error)
+	GetAllByChat(ctx context.Context, chatID int64) ([]models.IncomingWebhook, error)
+	Revoke(ctx context.Context, id int64, revokedAt time.Time) error
+}
+
+type Mention interface {
+	Create(ctx context.Context, mentions []models.CreateMentionRecord) error
+	GetAll(ctx context.Context, userID int64, pagination models.DBPagination, filters models.GetMentionsFilters) ([]models.Mention, uint64, error)
+	CountUnread(ctx context.Context, userID int64, now time.Time) (uint64, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64, readAt time.Time) error
+}
+
+type UserBlock interface {
+	Block(ctx context.Context, block models.CreateUserBlockRecord) error
+	Unblock(ctx context.Context, blockerID, blockedID int64) error
+	IsBlocked(ctx context.Context, blockerID, blockedID int64) (bool, error)
+	GetBlocked(ctx context.Context, blockerID int64) ([]models.User, error)
+	GetBlockedIDs(ctx context.Context, blockerID int64) ([]int64, error)
+	// GetBlockerIDs returns ids of users who blocked the given user.
+	GetBlockerIDs(ctx context.Context, blockedID int64) ([]int64, error)
+}
+
+type Report interface {
+	Create(ctx context.Context, report models.CreateReportRecord) (models.Report, error)
+	GetByID(ctx context.Context, id int64) (models.Report, error)
+	GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetReportsFilters) ([]models.Report, uint64, error)
+	Resolve(ctx context.Context, report models.ResolveReportRecord) error
+	CreateAction(ctx context.Context, action models.CreateModerationActionRecord) error
+	GetActions(ctx context.Context, pagination models.DBPagination) ([]models.ModerationActionRecord, uint64, error)
+}
+
+type DataExport interface {
+	Create(ctx context.Context, userID int64, createdAt time.Time) (models.DataExport, error)
+	GetByID(ctx context.Context, id int64) (models.DataExport, error)
+	GetAllByUser(ctx context.Context, userID int64) ([]models.DataExport, error)
+	GetPending(ctx context.Context, limit uint64) ([]models.DataExport, error)
+	GetExpired(ctx context.Context, now time.Time, limit uint64) ([]models.DataExport, error)
+	Update(ctx context.Context, export models.UpdateDataExportRecord) error
+}
+
+type Attachment interface {
+	Create(ctx context.Context, messageID int64, attachments []models.CreateAttachmentRecord) error
+	GetByMessageIDs(ctx context.Context, messageIDs []int64) ([]models.Attachment, error)
+}
+
+type ChatImport interface {
+	// Import writes history in one transaction, messages are inserted in batches of batchSize.
+	Import(ctx context.Context, record models.ImportChatRecord, batchSize int) error
+}
+
+type ScheduledMessage interface {
+	Create(ctx context.Context, message models.CreateScheduledMessageRecord) (models.ScheduledMessage, error)
+	GetByID(ctx context.Context, id int64) (models.ScheduledMessage, error)
+	GetPendingByUser(ctx context.Context, userID int64, filters models.GetScheduledMessagesFilters) ([]models.ScheduledMessage, error)
+	ClaimDue(ctx context.Context, now time.Time, limit uint64) ([]models.ScheduledMessage, error)
+	Update(ctx context.Context, message models.UpdateScheduledMessageRecord) error
+	SetStatus(ctx context.Context, message models.SetScheduledMessageStatusRecord) error
+}
+
+type Poll interface {
+	// Create creates poll with its message and returns the message.
+	Create(ctx context.Context, poll models.CreatePollRecord) (models.Message, error)
+	GetByID(ctx context.Context, id int64) (models.Poll, error)
+	GetByMessageIDs(ctx context.Context, messageIDs []int64) ([]models.Poll, error)
+	GetOptions(ctx context.Context, pollIDs []int64) ([]models.PollOption, error)
+	GetVotes(ctx context.Context, pollIDs []int64) ([]models.PollVote, error)
+	Vote(ctx context.Context, pollID, userID int64, optionIDs []int64, votedAt time.Time) error
+	Retract(ctx context.Context, pollID, userID int64) error
+	Close(ctx context.Context, pollID int64, closedAt time.Time) error
+}
+
+type LinkPreview interface {
+	GetByURL(ctx context.Context, url string) (models.LinkPreview, error)
+	GetByURLs(ctx context.Context, urls []string) ([]models.LinkPreview, error)
+	// Save creates or replaces cached preview of the URL.
+	Save(ctx context.Context, preview models.SaveLinkPreviewRecord) (models.LinkPreview, error)
+	// Attach sets preview of the message, preview must be saved before.
+	Attach(ctx context.Context, messageID int64, url string) error
+}
+
+type Draft interface {
+	Get(ctx context.Context, userID, chatID int64) (models.Draft, error)
+	GetByChatIDs(ctx context.Context, userID int64, chatIDs []int64) ([]models.Draft, error)
+	// Save creates or replaces the draft.
+	Save(ctx context.Context, draft models.SaveDraftRecord) (models.Draft, error)
+	Delete(ctx context.Context, userID, chatID int64) error
+}
+
+// RateLimit is PostgreSQL implementation of ratelimit.Limiter shared by all instances.
+type RateLimit interface {
+	Allow(ctx context.Context, key string, limit ratelimit.Limit, now time.Time) (time.Duration, error)
+	Release(ctx context.Context, key string, limit ratelimit.Limit) error
+}
+
+type FilterRule interface {
+	Create(ctx context.Context, rule models.CreateFilterRuleRecord) (models.FilterRule, error)
+	GetByID(ctx context.Context, id int64) (models.FilterRule, error)
+	// GetAll returns rules of the chat or global rules if chatID is nil.
+	GetAll(ctx context.Context, chatID *int64) ([]models.FilterRule, error)
+	// GetForChat returns global rules and rules of the chat.
+	GetForChat(ctx context.Context, chatID int64) ([]models.FilterRule, error)
+	Delete(ctx context.Context, id int64) error
+}
+
+type FilterHit interface {
+	Create(ctx context.Context, hits []models.CreateFilterHitRecord) error
+	GetAll(ctx context.Context, pagination models.DBPagination, filters models.GetFilterHitsFilters) ([]models.FilterHit, uint64, error)
+}
+
+type Category interface {
+	Create(ctx context.Context, category models.CreateCategoryRecord) (models.Category, error)
+	GetAll(ctx context.Context) ([]models.Category, error)
 	Delete(ctx context.Context, id int64) error
+	GetByChatIDs(ctx context.Context, chatIDs []int64) ([]models.ChatCategory, error)
+	// SetChatCategories replaces categories of the chat.
+	SetChatCategories(ctx context.Context, chatID int64, categoryIDs []int64) error
 }
 
 type Repository struct {
 	User
 	Chat
 	Message
+	Bot
+	Webhook
+	IncomingWebhook
+	Mention
+	UserBlock
+	Report
+	DataExport
+	Attachment
+	ChatImport
+	ScheduledMessage
+	Poll
+	LinkPreview
+	Draft
+	RateLimit
+	FilterRule
+	FilterHit
+	Category
 }
 
 func New(psql postgresql.PostgresqlRepository, logger logger.Logger) *Repository {
 	return &Repository{
-		User:    postgresql.NewUser(psql.DB),
-		Chat:    postgresql.NewChat(psql.DB),
-		Message: postgresql.NewMessages(psql.DB),
+		User:             postgresql.NewUser(psql),
+		Chat:             postgresql.NewChat(psql.DB),
+		Message:          postgresql.NewMessages(psql),
+		Bot:              postgresql.NewBot(psql.DB),
+		Webhook:          postgresql.NewWebhook(psql.DB),
+		IncomingWebhook:  postgresql.NewIncomingWebhook(psql),
+		Mention:          postgresql.NewMention(psql.DB),
+		UserBlock:        postgresql.NewUserBlock(psql.DB),
+		Report:           postgresql.NewReport(psql.DB),
+		DataExport:       postgresql.NewDataExport(psql.DB),
+		Attachment:       postgresql.NewAttachment(psql.DB),
+		ChatImport:       postgresql.NewChatImport(psql),
+		ScheduledMessage: postgresql.NewScheduledMessage(psql.DB),
+		Poll:             postgresql.NewPoll(psql),
+		LinkPreview:      postgresql.NewLinkPreview(psql.DB),
+		Draft:            postgresql.NewDraft(psql.DB),
+		RateLimit:        postgresql.NewRateLimit(psql.DB),
+		FilterRule:       postgresql.NewFilterRule(psql.DB),
+		FilterHit:        postgresql.NewFilterHit(psql.DB),
+		Category:         postgresql.NewCategory(psql),
 	}
 }
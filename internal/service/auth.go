@@ -37,6 +37,17 @@ func (a *AuthorizationSerive) RefreshTokens(ctx context.Context, refreshToken st
 		return jwt.TokenPair{}, fmt.Errorf("Authorization.RefreshTokens: %w", err)
 	}
 
+	user, err := a.userRepo.GetByID(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return jwt.TokenPair{}, jwt.ErrInvalidToken
+		}
+		return jwt.TokenPair{}, fmt.Errorf("Authorization.RefreshTokens: %w", err)
+	}
+	if err := user.CheckStatus(clock.Now()); err != nil {
+		return jwt.TokenPair{}, err
+	}
+
 	tokenPair, err := a.jwt.GeneratePair(claims.Subject)
 	if err != nil {
 		return jwt.TokenPair{}, fmt.Errorf("Authorization.RefreshTokens: %w", err)
@@ -58,9 +69,17 @@ func (a *AuthorizationSerive) Login(ctx context.Context, input models.LoginUserI
 		return jwt.TokenPair{}, err
 	}
 
+	// bots and integrations have no password, they authorize with their tokens only
+	if len(user.PasswordHash) == 0 {
+		return jwt.TokenPair{}, models.ErrInvalidCredentials
+	}
+
 	if err := hash.Compare(user.PasswordHash, input.Password); err != nil {
 		return jwt.TokenPair{}, models.ErrInvalidCredentials
 	}
+	if err := user.CheckStatus(clock.Now()); err != nil {
+		return jwt.TokenPair{}, err
+	}
 
 	tokenPair, err := a.jwt.GeneratePair(user.ID)
 	if err != nil {
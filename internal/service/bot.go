@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/token"
+)
+
+type BotService struct {
+	repo        repository.Bot
+	userRepo    repository.User
+	chatRepo    repository.Chat
+	messageRepo repository.Message
+	notifier    *UpdatesNotifier
+}
+
+func NewBotService(
+	repo repository.Bot,
+	userRepo repository.User,
+	chatRepo repository.Chat,
+	messageRepo repository.Message,
+	notifier *UpdatesNotifier,
+) *BotService {
+	return &BotService{
+		repo:        repo,
+		userRepo:    userRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		notifier:    notifier,
+	}
+}
+
+func (b *BotService) Create(ctx context.Context, input models.CreateBotInput) (models.BotCredentials, error) {
+	if input.DisplayName == nil {
+		input.DisplayName = &input.Username
+	}
+
+	err := b.userRepo.Create(ctx, models.CreateUserRecord{
+		Username:    input.Username,
+		DisplayName: *input.DisplayName,
+		// bots can't sign in with password
+		PasswordHash: []byte{},
+		Type:         models.UserTypeBot,
+		CreatedAt:    clock.Now(),
+	})
+	if err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	bot, err := b.userRepo.GetByUsername(ctx, input.Username)
+	if err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	botToken, err := b.issueToken(ctx, bot.ID)
+	if err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	return models.BotCredentials{Bot: bot, Token: botToken}, nil
+}
+
+// RotateToken revokes all active tokens of the bot and issues a new one.
+func (b *BotService) RotateToken(ctx context.Context, botID int64) (models.BotCredentials, error) {
+	bot, err := b.getBot(ctx, botID)
+	if err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	if err := b.repo.RevokeTokens(ctx, bot.ID, clock.Now()); err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	botToken, err := b.issueToken(ctx, bot.ID)
+	if err != nil {
+		return models.BotCredentials{}, err
+	}
+
+	return models.BotCredentials{Bot: bot, Token: botToken}, nil
+}
+
+func (b *BotService) RevokeTokens(ctx context.Context, botID int64) error {
+	bot, err := b.getBot(ctx, botID)
+	if err != nil {
+		return err
+	}
+
+	return b.repo.RevokeTokens(ctx, bot.ID, clock.Now())
+}
+
+// Authenticate returns bot user owning the active token.
+func (b *BotService) Authenticate(ctx context.Context, botToken string) (models.User, error) {
+	storedToken, err := b.repo.GetActiveToken(ctx, token.Hash(botToken))
+	if err != nil {
+		return models.User{}, handleNotFoundError(err, models.ErrInvalidBotToken)
+	}
+
+	bot, err := b.getBot(ctx, storedToken.BotID)
+	if err != nil {
+		return models.User{}, handleNotFoundError(err, models.ErrInvalidBotToken)
+	}
+
+	return bot, nil
+}
+
+// GetUpdates returns new messages from the chats bot has joined.
+// If there are no messages yet, it waits for them until input timeout exceeds (long polling).
+// Typing of chat members is returned as events only while the request waits.
+func (b *BotService) GetUpdates(ctx context.Context, botID int64, input models.GetBotUpdatesInput) (models.BotUpdates, error) {
+	// subscribing before the first check, so messages created in between are not missed
+	events, unsubscribe := b.notifier.Subscribe()
+	defer unsubscribe()
+
+	timeout := time.NewTimer(input.Timeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(models.BotUpdatesPollInterval)
+	defer ticker.Stop()
+
+	updates := models.BotUpdates{Events: []models.ChatEvent{}}
+	for {
+		messages, err := b.messageRepo.GetUpdates(ctx, botID, input.Offset, input.Limit, clock.Now())
+		updates.Messages = messages
+		if err != nil || len(messages) > 0 {
+			return updates, err
+		}
+
+		typing, ok, err := b.wait(ctx, botID, events, timeout.C, ticker.C)
+		if err != nil {
+			return updates, err
+		}
+		if typing != nil {
+			updates.Events = append(updates.Events, *typing)
+			return updates, nil
+		}
+		if !ok {
+			return updates, nil
+		}
+	}
+}
+
+// wait blocks until a new message may be available or a member of the bot
+// chats starts typing, false is returned when the wait is over.
+func (b *BotService) wait(
+	ctx context.Context,
+	botID int64,
+	events <-chan models.ChatEvent,
+	timeout, tick <-chan time.Time,
+) (*models.ChatEvent, bool, error) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false, nil
+		case <-timeout:
+			return nil, false, nil
+		case <-tick:
+			return nil, true, nil
+		case event := <-events:
+			switch event.Type {
+			case models.ChatEventMessageCreated:
+				return nil, true, nil
+			case models.ChatEventTyping:
+				if data, ok := event.Data.(models.TypingEventData); !ok || data.UserID == botID {
+					continue
+				}
+				isJoined, err := b.chatRepo.IsUserInChat(ctx, event.ChatID, botID)
+				if err != nil {
+					return nil, false, err
+				}
+				if isJoined {
+					return &event, true, nil
+				}
+			}
+		}
+	}
+}
+
+func (b *BotService) SetCommands(ctx context.Context, botID int64, commands []models.BotCommand) error {
+	return b.repo.SetCommands(ctx, botID, commands)
+}
+
+func (b *BotService) GetCommands(ctx context.Context, botID int64) ([]models.BotCommand, error) {
+	return b.repo.GetCommands(ctx, botID)
+}
+
+func (b *BotService) getBot(ctx context.Context, botID int64) (models.User, error) {
+	bot, err := b.userRepo.GetByID(ctx, botID)
+	if err != nil {
+		return models.User{}, handleNotFoundError(err, models.ErrBotNotFound)
+	}
+	if bot.Type != models.UserTypeBot {
+		return models.User{}, models.ErrBotNotFound
+	}
+
+	return bot, nil
+}
+
+func (b *BotService) issueToken(ctx context.Context, botID int64) (string, error) {
+	botToken, err := token.Generate(token.DefaultLength)
+	if err != nil {
+		return "", fmt.Errorf("BotService.issueToken: %w", err)
+	}
+
+	err = b.repo.CreateToken(ctx, models.CreateBotTokenRecord{
+		BotID:     botID,
+		TokenHash: token.Hash(botToken),
+		CreatedAt: clock.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return botToken, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/token"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBotRepo struct {
+	repository.Bot
+	tokens []models.CreateBotTokenRecord
+}
+
+func (f *fakeBotRepo) CreateToken(ctx context.Context, token models.CreateBotTokenRecord) error {
+	f.tokens = append(f.tokens, token)
+	return nil
+}
+
+type fakeBotUserRepo struct {
+	repository.User
+	users []models.CreateUserRecord
+}
+
+func (f *fakeBotUserRepo) Create(ctx context.Context, user models.CreateUserRecord) error {
+	f.users = append(f.users, user)
+	return nil
+}
+
+func (f *fakeBotUserRepo) GetByUsername(ctx context.Context, username string) (models.User, error) {
+	for i, user := range f.users {
+		if user.Username == username {
+			return models.User{ID: int64(i + 1), Username: user.Username, DisplayName: user.DisplayName, Type: models.UserType(user.Type)}, nil
+		}
+	}
+	return models.User{}, models.ErrUserNotFound
+}
+
+type fakeUpdatesRepo struct {
+	repository.Message
+	mu       sync.Mutex
+	messages []models.Message
+	queries  int
+}
+
+func (f *fakeUpdatesRepo) GetUpdates(ctx context.Context, userID int64, offset int64, limit uint64, now time.Time) ([]models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.queries++
+	updates := make([]models.Message, 0)
+	for _, message := range f.messages {
+		if message.ID > offset && message.SenderID != userID {
+			updates = append(updates, message)
+		}
+	}
+	return updates, nil
+}
+
+func (f *fakeUpdatesRepo) add(message models.Message) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.messages = append(f.messages, message)
+}
+
+func (n *UpdatesNotifier) subscriberCount() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return len(n.subscribers)
+}
+
+func TestBotCreate(t *testing.T) {
+	repo := &fakeBotRepo{}
+	userRepo := &fakeBotUserRepo{}
+	bots := NewBotService(repo, userRepo, &fakeChatRepo{}, &fakeUpdatesRepo{}, NewUpdatesNotifier())
+
+	input, err := models.NewCreateBotInput("weather_bot", nil)
+	require.NoError(t, err)
+	credentials, err := bots.Create(context.Background(), input)
+	require.NoError(t, err)
+
+	require.Equal(t, "weather_bot", credentials.Bot.DisplayName)
+	require.EqualValues(t, models.UserTypeBot, credentials.Bot.Type)
+	require.Len(t, userRepo.users, 1)
+	require.Empty(t, userRepo.users[0].PasswordHash)
+
+	// only hash of the token is stored
+	require.Len(t, repo.tokens, 1)
+	require.Equal(t, credentials.Bot.ID, repo.tokens[0].BotID)
+	require.Equal(t, token.Hash(credentials.Token), repo.tokens[0].TokenHash)
+}
+
+func TestBotGetUpdatesWakesOnMessage(t *testing.T) {
+	ctx := context.Background()
+	messages := &fakeUpdatesRepo{messages: []models.Message{{ID: 1, ChatID: 1, SenderID: 2}}}
+	notifier := NewUpdatesNotifier()
+	bots := NewBotService(&fakeBotRepo{}, &fakeBotUserRepo{}, &fakeChatRepo{}, messages, notifier)
+
+	// pending messages are returned at once
+	updates, err := bots.GetUpdates(ctx, 10, models.GetBotUpdatesInput{Limit: 10, Timeout: time.Minute})
+	require.NoError(t, err)
+	require.Len(t, updates.Messages, 1)
+
+	// no messages and no timeout
+	updates, err = bots.GetUpdates(ctx, 10, models.GetBotUpdatesInput{Offset: 1, Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, updates.Messages)
+
+	result := make(chan models.BotUpdates)
+	go func() {
+		updates, _ := bots.GetUpdates(ctx, 10, models.GetBotUpdatesInput{Offset: 1, Limit: 10, Timeout: time.Minute})
+		result <- updates
+	}()
+	require.Eventually(t, func() bool { return notifier.subscriberCount() == 1 }, time.Second, time.Millisecond)
+
+	// other events don't wake the request
+	notifier.Dispatch(ctx, models.NewMemberEvent(models.ChatEventMemberJoined, 1, 3, time.Now()))
+
+	message := models.Message{ID: 2, ChatID: 1, SenderID: 3}
+	messages.add(message)
+	notifier.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageCreated, message, time.Now()))
+
+	select {
+	case updates := <-result:
+		require.Equal(t, []models.Message{message}, updates.Messages)
+		require.Empty(t, updates.Events)
+	case <-time.After(time.Second):
+		t.Fatal("waiting request is not woken by the new message")
+	}
+	require.Zero(t, notifier.subscriberCount())
+}
+
+func TestBotGetUpdatesPushesTyping(t *testing.T) {
+	ctx := context.Background()
+	notifier := NewUpdatesNotifier()
+	chatRepo := &fakeChatRepo{members: map[int64]bool{10: true}}
+	bots := NewBotService(&fakeBotRepo{}, &fakeBotUserRepo{}, chatRepo, &fakeUpdatesRepo{}, notifier)
+
+	result := make(chan models.BotUpdates)
+	go func() {
+		updates, _ := bots.GetUpdates(ctx, 10, models.GetBotUpdatesInput{Limit: 10, Timeout: time.Minute})
+		result <- updates
+	}()
+	require.Eventually(t, func() bool { return notifier.subscriberCount() == 1 }, time.Second, time.Millisecond)
+
+	// typing of the bot itself is not pushed back
+	now := time.Now()
+	notifier.Dispatch(ctx, models.NewTypingEvent(1, 10, now.Add(5*time.Second), now))
+	typing := models.NewTypingEvent(1, 2, now.Add(5*time.Second), now)
+	notifier.Dispatch(ctx, typing)
+
+	select {
+	case updates := <-result:
+		require.Empty(t, updates.Messages)
+		require.Equal(t, []models.ChatEvent{typing}, updates.Events)
+	case <-time.After(time.Second):
+		t.Fatal("waiting request is not woken by typing")
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+)
+
+// CategoryService manages categories chats are grouped by in discovery, only
+// admins can change them.
+type CategoryService struct {
+	repo     repository.Category
+	chatRepo repository.Chat
+}
+
+func NewCategoryService(repo repository.Category, chatRepo repository.Chat) *CategoryService {
+	return &CategoryService{
+		repo:     repo,
+		chatRepo: chatRepo,
+	}
+}
+
+func (c *CategoryService) GetAll(ctx context.Context) ([]models.Category, error) {
+	return c.repo.GetAll(ctx)
+}
+
+func (c *CategoryService) Create(ctx context.Context, input models.CreateCategoryInput) (models.Category, error) {
+	return c.repo.Create(ctx, models.CreateCategoryRecord{
+		Name:      input.Name,
+		Slug:      input.Slug,
+		CreatedAt: clock.Now(),
+	})
+}
+
+func (c *CategoryService) Delete(ctx context.Context, id int64) error {
+	return handleNotFoundError(c.repo.Delete(ctx, id), models.ErrCategoryNotFound)
+}
+
+// SetChatCategories replaces categories of the chat and returns them.
+func (c *CategoryService) SetChatCategories(ctx context.Context, chatID int64, categoryIDs []int64) ([]models.Category, error) {
+	if _, err := c.chatRepo.GetByID(ctx, chatID); err != nil {
+		return nil, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if err := c.repo.SetChatCategories(ctx, chatID, categoryIDs); err != nil {
+		return nil, err
+	}
+
+	chatCategories, err := c.repo.GetByChatIDs(ctx, []int64{chatID})
+	if err != nil {
+		return nil, err
+	}
+	categories := make([]models.Category, 0, len(chatCategories))
+	for _, category := range chatCategories {
+		categories = append(categories, category.Category)
+	}
+
+	return categories, nil
+}
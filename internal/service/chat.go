@@ -12,12 +12,23 @@ import (
 )
 
 type ChatService struct {
-	repo repository.Chat
+	repo         repository.Chat
+	draftRepo    repository.Draft
+	categoryRepo repository.Category
+	events       EventDispatcher
 }
 
-func NewChatService(repository repository.Chat) *ChatService {
+func NewChatService(
+	repository repository.Chat,
+	draftRepo repository.Draft,
+	categoryRepo repository.Category,
+	events EventDispatcher,
+) *ChatService {
 	return &ChatService{
-		repo: repository,
+		repo:         repository,
+		draftRepo:    draftRepo,
+		categoryRepo: categoryRepo,
+		events:       events,
 	}
 }
 
@@ -49,17 +60,73 @@ func (c *ChatService) Create(ctx context.Context, input models.CreateChatInput)
 
 func (c *ChatService) GetByID(ctx context.Context, id int64) (models.Chat, error) {
 	chat, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return chat, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+
+	chats := []models.Chat{chat}
+	if err := c.loadCategories(ctx, chats); err != nil {
+		return chat, err
+	}
 
-	return chat, handleNotFoundError(err, models.ErrChatNotFound)
+	return chats[0], nil
 }
 
-func (c *ChatService) GetAll(ctx context.Context, pagination models.Pagination) ([]models.Chat, models.FullPagination, error) {
+// GetAll returns chats matching the filters with drafts of the user.
+func (c *ChatService) GetAll(ctx context.Context, userID int64, pagination models.Pagination, filters models.GetChatsFilters) ([]models.Chat, models.FullPagination, error) {
+	filters.UserID = userID
 	chats, total, err := c.repo.GetAll(ctx, models.DBPagination{
 		Offset: pagination.Offset(),
 		Limit:  pagination.Limit(),
-	})
+	}, filters)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+	if err := c.loadCategories(ctx, chats); err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	chatIDs := make([]int64, 0, len(chats))
+	for _, chat := range chats {
+		chatIDs = append(chatIDs, chat.ID)
+	}
+	drafts, err := c.draftRepo.GetByChatIDs(ctx, userID, chatIDs)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	byChat := make(map[int64]*models.Draft, len(drafts))
+	for i := range drafts {
+		byChat[drafts[i].ChatID] = &drafts[i]
+	}
+	for i := range chats {
+		chats[i].Draft = byChat[chats[i].ID]
+	}
 
-	return chats, pagination.GetFull(total), err
+	return chats, pagination.GetFull(total), nil
+}
+
+// loadCategories sets categories of the chats.
+func (c *ChatService) loadCategories(ctx context.Context, chats []models.Chat) error {
+	chatIDs := make([]int64, 0, len(chats))
+	for _, chat := range chats {
+		chatIDs = append(chatIDs, chat.ID)
+	}
+
+	categories, err := c.categoryRepo.GetByChatIDs(ctx, chatIDs)
+	if err != nil {
+		return err
+	}
+
+	byChat := make(map[int64][]models.Category, len(chats))
+	for _, category := range categories {
+		byChat[category.ChatID] = append(byChat[category.ChatID], category.Category)
+	}
+	for i := range chats {
+		chats[i].Categories = byChat[chats[i].ID]
+	}
+
+	return nil
 }
 
 func (c *ChatService) JoinUser(ctx context.Context, chatID int64, userID int64, password string) error {
@@ -79,8 +146,55 @@ func (c *ChatService) JoinUser(ctx context.Context, chatID int64, userID int64,
 		return models.ErrChatWrongPassword
 	}
 
-	return c.repo.JoinUser(ctx, chatID, userID)
+	if err := c.repo.JoinUser(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	c.events.Dispatch(ctx, models.NewMemberEvent(models.ChatEventMemberJoined, chatID, userID, clock.Now()))
+
+	return nil
 }
 func (c *ChatService) LeaveUser(ctx context.Context, chatID int64, userID int64) error {
-	return c.repo.LeaveUser(ctx, chatID, userID)
+	if err := c.repo.LeaveUser(ctx, chatID, userID); err != nil {
+		return err
+	}
+
+	c.events.Dispatch(ctx, models.NewMemberEvent(models.ChatEventMemberLeft, chatID, userID, clock.Now()))
+
+	return nil
+}
+
+// SetMemberRole changes chat member role, only chat creator and admins can do it.
+func (c *ChatService) SetMemberRole(ctx context.Context, user models.User, chatID int64, memberID int64, role models.ChatRole) error {
+	chat, err := c.repo.GetByID(ctx, chatID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return models.ErrChatAccessDenied
+	}
+
+	err = c.repo.SetMemberRole(ctx, chatID, memberID, role)
+
+	return handleNotFoundError(err, models.ErrChatMemberNotFound)
+}
+
+// UpdateSettings changes chat settings, only chat creator and admins can do it.
+func (c *ChatService) UpdateSettings(ctx context.Context, user models.User, chatID int64, input models.UpdateChatSettingsInput) (models.Chat, error) {
+	chat, err := c.repo.GetByID(ctx, chatID)
+	if err != nil {
+		return models.Chat{}, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return models.Chat{}, models.ErrChatAccessDenied
+	}
+	if input.ForwardingDisabled != nil && chat.Type != models.ChatTypePrivate {
+		return models.Chat{}, models.ErrChatNotPrivate
+	}
+
+	if err := c.repo.UpdateSettings(ctx, chatID, input); err != nil {
+		return models.Chat{}, err
+	}
+
+	return c.GetByID(ctx, chatID)
 }
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/chatexport"
+	"spsu-chat/pkg/clock"
+	"time"
+)
+
+type ChatExportService struct {
+	chatRepo    repository.Chat
+	userRepo    repository.User
+	messageRepo repository.Message
+	location    *time.Location
+}
+
+func NewChatExportService(
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	messageRepo repository.Message,
+	config ChatExportConfig,
+	logger logger.Logger,
+) *ChatExportService {
+	location, err := time.LoadLocation(config.Timezone)
+	if err != nil {
+		logger.Warnf("ChatExportService: unknown timezone %q, using UTC: %s", config.Timezone, err)
+		location = time.UTC
+	}
+
+	return &ChatExportService{
+		chatRepo:    chatRepo,
+		userRepo:    userRepo,
+		messageRepo: messageRepo,
+		location:    location,
+	}
+}
+
+// GetChat returns the chat if the user can export its history: global admins and chat creator can.
+func (c *ChatExportService) GetChat(ctx context.Context, userID int64, chatID int64) (models.Chat, error) {
+	user, err := c.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return models.Chat{}, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+
+	chat, err := c.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return models.Chat{}, models.ErrChatNotFound
+		}
+		return models.Chat{}, err
+	}
+
+	if !canManageChat(user, chat) {
+		return models.Chat{}, models.ErrChatAccessDenied
+	}
+
+	return chat, nil
+}
+
+// Write streams the whole chat history to w in the format.
+func (c *ChatExportService) Write(ctx context.Context, chat models.Chat, format models.ChatExportFormat, w io.Writer) error {
+	buf := bufio.NewWriter(w)
+	writer, err := chatexport.NewWriter(format, buf, c.location)
+	if err != nil {
+		return err
+	}
+
+	if err := writer.WriteHeader(chat); err != nil {
+		return err
+	}
+	now := clock.Now()
+	filters := models.GetMessagesFilters{
+		ChatID:       chat.ID,
+		VisibleAt:    now,
+		CreatedAfter: chat.RetentionCutoff(now),
+	}
+	if err := c.messageRepo.Iterate(ctx, filters, writer.WriteMessage); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	return buf.Flush()
+}
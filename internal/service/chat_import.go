@@ -0,0 +1,336 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/uploader"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/file"
+	"spsu-chat/pkg/telegram"
+	"strings"
+)
+
+const (
+	placeholderDisplayName = "Telegram user"
+)
+
+var zipSignature = []byte("PK\x03\x04")
+
+// ChatImportService imports chat history exported from Telegram Desktop.
+type ChatImportService struct {
+	repo     repository.ChatImport
+	chatRepo repository.Chat
+	userRepo repository.User
+	uploader *uploader.Uploader
+	config   ImportConfig
+	logger   logger.Logger
+}
+
+func NewChatImportService(
+	repo repository.ChatImport,
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	uploader *uploader.Uploader,
+	config ImportConfig,
+	logger logger.Logger,
+) *ChatImportService {
+	return &ChatImportService{
+		repo:     repo,
+		chatRepo: chatRepo,
+		userRepo: userRepo,
+		uploader: uploader,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// importFile is a media file found in the export archive.
+type importFile struct {
+	path string
+	zip  *zip.File
+}
+
+func (c *ChatImportService) Import(ctx context.Context, input models.ImportChatInput) (models.ImportChatResult, error) {
+	if int64(len(input.Data)) > c.config.MaxFileSize {
+		return models.ImportChatResult{}, models.ErrImportFileTooLarge
+	}
+
+	user, err := c.userRepo.GetByID(ctx, input.UserID)
+	if err != nil {
+		return models.ImportChatResult{}, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	chat, err := c.chatRepo.GetByID(ctx, input.ChatID)
+	if err != nil {
+		return models.ImportChatResult{}, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return models.ImportChatResult{}, models.ErrChatAccessDenied
+	}
+
+	export, files, err := c.readExport(input.Data)
+	if err != nil {
+		return models.ImportChatResult{}, err
+	}
+
+	result := models.ImportChatResult{DryRun: input.DryRun}
+	record := models.ImportChatRecord{
+		ChatID:       chat.ID,
+		Senders:      make(map[string]int64),
+		Placeholders: make(map[string]models.CreateUserRecord),
+	}
+	senders := make(map[string]*models.ImportSender)
+
+	for _, message := range export.Messages {
+		media := make([]string, 0)
+		for _, mediaPath := range message.Media() {
+			if _, ok := files[mediaPath]; ok {
+				media = append(media, mediaPath)
+			}
+		}
+		if message.Type != telegram.MessageTypeMessage || message.FromID == "" ||
+			(message.Text == "" && len(media) == 0) {
+			result.Skipped++
+			continue
+		}
+
+		createdAt, err := message.Time()
+		if err != nil {
+			return models.ImportChatResult{}, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+		}
+
+		sender, ok := senders[message.FromID]
+		if !ok {
+			sender, err = c.resolveSender(ctx, message, input.Mapping, &record)
+			if err != nil {
+				return models.ImportChatResult{}, err
+			}
+			senders[message.FromID] = sender
+		}
+		sender.Messages++
+
+		importMessage := models.ImportMessageRecord{
+			SenderKey: message.FromID,
+			Text:      string(message.Text),
+			CreatedAt: createdAt,
+		}
+		for _, mediaPath := range media {
+			// Filename holds path in the archive until the file is uploaded, which is skipped on dry run
+			importMessage.Attachments = append(importMessage.Attachments, models.CreateAttachmentRecord{
+				Filename:  mediaPath,
+				Size:      int64(files[mediaPath].zip.UncompressedSize64),
+				CreatedAt: createdAt,
+			})
+		}
+		record.Messages = append(record.Messages, importMessage)
+
+		result.Messages++
+		result.Attachments += len(media)
+	}
+
+	result.Senders = make([]models.ImportSender, 0, len(senders))
+	for _, sender := range senders {
+		result.Senders = append(result.Senders, *sender)
+	}
+	sort.Slice(result.Senders, func(i, j int) bool {
+		return result.Senders[i].TelegramID < result.Senders[j].TelegramID
+	})
+
+	if input.DryRun || len(record.Messages) == 0 {
+		return result, nil
+	}
+
+	uploaded, err := c.uploadAttachments(chat.ID, files, record.Messages)
+	if err != nil {
+		c.removeAttachments(chat.ID, uploaded)
+		return models.ImportChatResult{}, err
+	}
+
+	if err := c.repo.Import(ctx, record, c.config.BatchSize); err != nil {
+		c.removeAttachments(chat.ID, uploaded)
+		return models.ImportChatResult{}, err
+	}
+
+	return result, nil
+}
+
+// readExport parses result.json, which is either uploaded as is or zipped with media files.
+func (c *ChatImportService) readExport(data []byte) (telegram.Export, map[string]importFile, error) {
+	files := make(map[string]importFile)
+	if !bytes.HasPrefix(data, zipSignature) {
+		export, err := telegram.Parse(bytes.NewReader(data))
+		if err != nil {
+			return telegram.Export{}, nil, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+		}
+		return export, files, nil
+	}
+
+	zipFiles, err := file.GetFilesFromZip(bytes.NewReader(data))
+	if err != nil {
+		return telegram.Export{}, nil, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+	}
+
+	// export directory may be zipped itself, so result.json is looked up at any depth
+	var result *zip.File
+	for _, zipFile := range zipFiles {
+		if path.Base(zipFile.Name) == telegram.ExportFilename &&
+			(result == nil || len(zipFile.Name) < len(result.Name)) {
+			result = zipFile
+		}
+	}
+	if result == nil {
+		return telegram.Export{}, nil, fmt.Errorf("%w: %s not found in archive", models.ErrInvalidImportFile, telegram.ExportFilename)
+	}
+
+	root := path.Dir(result.Name)
+	var totalSize uint64
+	for _, zipFile := range zipFiles {
+		if zipFile.FileInfo().IsDir() || zipFile == result {
+			continue
+		}
+		totalSize += zipFile.UncompressedSize64
+		if totalSize > uint64(c.config.MaxFileSize) {
+			return telegram.Export{}, nil, models.ErrImportFileTooLarge
+		}
+
+		mediaPath := zipFile.Name
+		if root != "." {
+			if !strings.HasPrefix(mediaPath, root+"/") {
+				continue
+			}
+			mediaPath = strings.TrimPrefix(mediaPath, root+"/")
+		}
+		files[mediaPath] = importFile{path: mediaPath, zip: zipFile}
+	}
+
+	reader, err := result.Open()
+	if err != nil {
+		return telegram.Export{}, nil, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+	}
+	defer reader.Close()
+
+	export, err := telegram.Parse(reader)
+	if err != nil {
+		return telegram.Export{}, nil, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+	}
+
+	return export, files, nil
+}
+
+// resolveSender maps Telegram sender to the user from mapping, to placeholder
+// account created by previous import or to a new placeholder account.
+func (c *ChatImportService) resolveSender(
+	ctx context.Context,
+	message telegram.Message,
+	mapping map[string]int64,
+	record *models.ImportChatRecord,
+) (*models.ImportSender, error) {
+	sender := &models.ImportSender{
+		TelegramID: message.FromID,
+		Name:       message.From,
+	}
+
+	if userID, ok := mapping[message.FromID]; ok {
+		user, err := c.userRepo.GetByID(ctx, userID)
+		if err != nil {
+			if errors.Is(err, apperror.ErrNotFound) {
+				return nil, fmt.Errorf("%w: %d", models.ErrImportMappingUser, userID)
+			}
+			return nil, err
+		}
+		if user.Status == models.UserStatusDeleted {
+			return nil, fmt.Errorf("%w: %d", models.ErrImportMappingUser, userID)
+		}
+
+		sender.UserID = &user.ID
+		record.Senders[message.FromID] = user.ID
+		return sender, nil
+	}
+
+	sender.Placeholder = true
+	username := models.PlaceholderUsernamePrefix + message.FromID
+	user, err := c.userRepo.GetByUsername(ctx, username)
+	switch {
+	case err == nil:
+		if user.Type != models.UserTypePlaceholder {
+			return nil, fmt.Errorf("%w: %s", models.ErrPlaceholderTaken, username)
+		}
+		sender.UserID = &user.ID
+		record.Senders[message.FromID] = user.ID
+	case errors.Is(err, apperror.ErrNotFound):
+		displayName := message.From
+		if displayName == "" {
+			displayName = placeholderDisplayName
+		}
+		record.Placeholders[message.FromID] = models.CreateUserRecord{
+			Username:     username,
+			DisplayName:  displayName,
+			PasswordHash: []byte{},
+			Type:         models.UserTypePlaceholder,
+			CreatedAt:    clock.Now(),
+		}
+	default:
+		return nil, err
+	}
+
+	return sender, nil
+}
+
+// uploadAttachments saves media files and fills attachment records, it returns
+// records of the uploaded files even on error, so they can be removed.
+func (c *ChatImportService) uploadAttachments(
+	chatID int64,
+	files map[string]importFile,
+	messages []models.ImportMessageRecord,
+) ([]models.CreateAttachmentRecord, error) {
+	uploaded := make([]models.CreateAttachmentRecord, 0)
+	for i := range messages {
+		for j := range messages[i].Attachments {
+			attachment := &messages[i].Attachments[j]
+			importFile := files[attachment.Filename]
+
+			data, err := readZipFile(importFile.zip)
+			if err != nil {
+				return uploaded, fmt.Errorf("%w: %s", models.ErrInvalidImportFile, err)
+			}
+			fileInfo, err := c.uploader.UploadAttachment(chatID, path.Base(importFile.path), data)
+			if err != nil {
+				return uploaded, err
+			}
+
+			attachment.FileID = fileInfo.ID
+			attachment.URL = fileInfo.URL
+			attachment.Filename = path.Base(importFile.path)
+			uploaded = append(uploaded, *attachment)
+		}
+	}
+
+	return uploaded, nil
+}
+
+func (c *ChatImportService) removeAttachments(chatID int64, attachments []models.CreateAttachmentRecord) {
+	for _, attachment := range attachments {
+		if err := c.uploader.DeleteAttachment(chatID, attachment.FileID); err != nil {
+			c.logger.Errorf("ChatImportService.removeAttachments: %s", err)
+		}
+	}
+}
+
+func readZipFile(zipFile *zip.File) ([]byte, error) {
+	reader, err := zipFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,51 @@
+// Package chatexport writes chat history in formats suitable for archiving.
+package chatexport
+
+import (
+	"fmt"
+	"io"
+	"spsu-chat/internal/models"
+	"time"
+)
+
+const (
+	timeLayout = "2006-01-02 15:04:05 MST"
+)
+
+// Writer writes chat history message by message, so the history does not have to fit into memory.
+type Writer interface {
+	// WriteHeader is called once before any message is written.
+	WriteHeader(chat models.Chat) error
+	WriteMessage(message models.ChatExportMessage) error
+	// Close finishes the document, it does not close underlying writer.
+	Close() error
+}
+
+// NewWriter creates writer of the format, timestamps are written in the location.
+func NewWriter(format models.ChatExportFormat, w io.Writer, location *time.Location) (Writer, error) {
+	switch format {
+	case models.ChatExportFormatJSON:
+		return newJSONWriter(w, location), nil
+	case models.ChatExportFormatHTML:
+		return newHTMLWriter(w, location), nil
+	case models.ChatExportFormatText:
+		return newTextWriter(w, location), nil
+	default:
+		return nil, models.ErrInvalidExportFormat
+	}
+}
+
+func ContentType(format models.ChatExportFormat) string {
+	switch format {
+	case models.ChatExportFormatHTML:
+		return "text/html; charset=utf-8"
+	case models.ChatExportFormatText:
+		return "text/plain; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+func Filename(chat models.Chat, format models.ChatExportFormat) string {
+	return fmt.Sprintf("chat-%d.%s", chat.ID, format)
+}
@@ -0,0 +1,86 @@
+package chatexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"spsu-chat/internal/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeAll(t *testing.T, format models.ChatExportFormat, messages []models.ChatExportMessage) string {
+	t.Helper()
+
+	location, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("timezone data is not available: %s", err)
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(format, &buf, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat := models.Chat{ID: 1, Name: "course <chat>", CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := writer.WriteHeader(chat); err != nil {
+		t.Fatal(err)
+	}
+	for _, message := range messages {
+		if err := writer.WriteMessage(message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.String()
+}
+
+var testMessages = []models.ChatExportMessage{
+	{ID: 1, SenderID: 1, SenderName: "Alice", Text: "hello", CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+	{ID: 2, SenderID: 2, SenderName: "Bob", Text: "<script>alert(1)</script>\nsecond line", CreatedAt: time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC)},
+}
+
+func TestJSONWriter(t *testing.T) {
+	for _, messages := range [][]models.ChatExportMessage{nil, testMessages} {
+		out := writeAll(t, models.ChatExportFormatJSON, messages)
+
+		var document struct {
+			Chat     map[string]any             `json:"chat"`
+			Messages []models.ChatExportMessage `json:"messages"`
+		}
+		if err := json.Unmarshal([]byte(out), &document); err != nil {
+			t.Fatalf("invalid json %q: %s", out, err)
+		}
+		if len(document.Messages) != len(messages) {
+			t.Errorf("got %d messages, want %d", len(document.Messages), len(messages))
+		}
+	}
+
+	out := writeAll(t, models.ChatExportFormatJSON, testMessages)
+	if !strings.Contains(out, "2024-01-01T15:00:00+03:00") {
+		t.Errorf("timestamps are not in configured timezone: %s", out)
+	}
+}
+
+func TestHTMLWriterEscapes(t *testing.T) {
+	out := writeAll(t, models.ChatExportFormatHTML, testMessages)
+
+	if strings.Contains(out, "<script>") || strings.Contains(out, "<chat>") {
+		t.Errorf("user content is not escaped: %s", out)
+	}
+	if !strings.Contains(out, "2024-01-01 15:00:00 MSK") {
+		t.Errorf("timestamps are not in configured timezone: %s", out)
+	}
+}
+
+func TestTextWriter(t *testing.T) {
+	out := writeAll(t, models.ChatExportFormatText, testMessages)
+
+	want := "[2024-01-01 15:01:00 MSK] Bob: <script>alert(1)</script>\n    second line\n"
+	if !strings.HasSuffix(out, want) {
+		t.Errorf("got %q, want suffix %q", out, want)
+	}
+}
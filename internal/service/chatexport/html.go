@@ -0,0 +1,86 @@
+package chatexport
+
+import (
+	"html/template"
+	"io"
+	"spsu-chat/internal/models"
+	"time"
+)
+
+// Styles are inlined, so the page can be viewed offline.
+var htmlHeader = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 800px; margin: 0 auto; padding: 16px; background: #f5f5f5; }
+header { border-bottom: 1px solid #ddd; margin-bottom: 16px; }
+.message { background: #fff; border-radius: 6px; padding: 8px 12px; margin: 8px 0; }
+.sender { font-weight: bold; }
+.time { color: #888; font-size: 0.85em; margin-left: 8px; }
+.text { white-space: pre-wrap; word-wrap: break-word; margin-top: 4px; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Name}}</h1>
+{{if .Topic}}<p>{{.Topic}}</p>{{end}}
+<p class="time">Created {{.CreatedAt}}</p>
+</header>
+<main>
+`))
+
+var htmlMessage = template.Must(template.New("message").Parse(`<div class="message" id="message-{{.ID}}">
+<span class="sender">{{.SenderName}}</span><span class="time">{{.CreatedAt}}</span>
+<div class="text">{{.Text}}</div>
+</div>
+`))
+
+const htmlFooter = `</main>
+</body>
+</html>
+`
+
+type htmlWriter struct {
+	w        io.Writer
+	location *time.Location
+}
+
+func newHTMLWriter(w io.Writer, location *time.Location) *htmlWriter {
+	return &htmlWriter{
+		w:        w,
+		location: location,
+	}
+}
+
+func (h *htmlWriter) WriteHeader(chat models.Chat) error {
+	return htmlHeader.Execute(h.w, struct {
+		Name      string
+		Topic     string
+		CreatedAt string
+	}{
+		Name:      chat.Name,
+		Topic:     chat.Topic,
+		CreatedAt: chat.CreatedAt.In(h.location).Format(timeLayout),
+	})
+}
+
+func (h *htmlWriter) WriteMessage(message models.ChatExportMessage) error {
+	return htmlMessage.Execute(h.w, struct {
+		ID         int64
+		SenderName string
+		Text       string
+		CreatedAt  string
+	}{
+		ID:         message.ID,
+		SenderName: message.SenderName,
+		Text:       message.Text,
+		CreatedAt:  message.CreatedAt.In(h.location).Format(timeLayout),
+	})
+}
+
+func (h *htmlWriter) Close() error {
+	_, err := io.WriteString(h.w, htmlFooter)
+	return err
+}
@@ -0,0 +1,72 @@
+package chatexport
+
+import (
+	"encoding/json"
+	"io"
+	"spsu-chat/internal/models"
+	"time"
+)
+
+type jsonChat struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Topic     string    `json:"topic"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type jsonWriter struct {
+	w        io.Writer
+	location *time.Location
+	written  int
+}
+
+func newJSONWriter(w io.Writer, location *time.Location) *jsonWriter {
+	return &jsonWriter{
+		w:        w,
+		location: location,
+	}
+}
+
+func (j *jsonWriter) WriteHeader(chat models.Chat) error {
+	data, err := json.Marshal(jsonChat{
+		ID:        chat.ID,
+		Name:      chat.Name,
+		Topic:     chat.Topic,
+		CreatedAt: chat.CreatedAt.In(j.location),
+	})
+	if err != nil {
+		return err
+	}
+
+	return j.write(`{"chat":`, string(data), `,"messages":[`)
+}
+
+func (j *jsonWriter) WriteMessage(message models.ChatExportMessage) error {
+	message.CreatedAt = message.CreatedAt.In(j.location)
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	separator := ""
+	if j.written > 0 {
+		separator = ","
+	}
+	j.written++
+
+	return j.write(separator, string(data))
+}
+
+func (j *jsonWriter) Close() error {
+	return j.write("]}")
+}
+
+func (j *jsonWriter) write(parts ...string) error {
+	for _, part := range parts {
+		if _, err := io.WriteString(j.w, part); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package chatexport
+
+import (
+	"fmt"
+	"io"
+	"spsu-chat/internal/models"
+	"strings"
+	"time"
+)
+
+type textWriter struct {
+	w        io.Writer
+	location *time.Location
+}
+
+func newTextWriter(w io.Writer, location *time.Location) *textWriter {
+	return &textWriter{
+		w:        w,
+		location: location,
+	}
+}
+
+func (t *textWriter) WriteHeader(chat models.Chat) error {
+	header := fmt.Sprintf("Chat: %s\n", chat.Name)
+	if chat.Topic != "" {
+		header += fmt.Sprintf("Topic: %s\n", chat.Topic)
+	}
+	header += fmt.Sprintf("Created: %s\n\n", chat.CreatedAt.In(t.location).Format(timeLayout))
+
+	_, err := io.WriteString(t.w, header)
+	return err
+}
+
+func (t *textWriter) WriteMessage(message models.ChatExportMessage) error {
+	// continuation lines are indented, so every message starts with its timestamp
+	text := strings.ReplaceAll(message.Text, "\n", "\n    ")
+
+	_, err := fmt.Fprintf(t.w, "[%s] %s: %s\n",
+		message.CreatedAt.In(t.location).Format(timeLayout),
+		message.SenderName,
+		text,
+	)
+	return err
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
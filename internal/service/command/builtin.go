@@ -0,0 +1,230 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+)
+
+// EventDispatcher delivers chat events to their subscribers.
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, event models.ChatEvent)
+}
+
+// ContentFilter checks text members put into the chat, blocked text results
+// in models.ErrMessageBlocked.
+type ContentFilter interface {
+	Apply(ctx context.Context, chatID, senderID int64, text string, entities models.MessageEntities) (models.FilterResult, error)
+}
+
+// RegisterBuiltins adds commands available in every chat.
+func RegisterBuiltins(
+	registry *Registry,
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	botRepo repository.Bot,
+	blockRepo repository.UserBlock,
+	filter ContentFilter,
+	events EventDispatcher,
+) {
+	registry.Register(
+		&meCommand{},
+		&topicCommand{chatRepo: chatRepo, filter: filter},
+		&inviteCommand{chatRepo: chatRepo, userRepo: userRepo, blockRepo: blockRepo, events: events},
+		&kickCommand{chatRepo: chatRepo, userRepo: userRepo, events: events},
+		&helpCommand{registry: registry, botRepo: botRepo},
+	)
+}
+
+type meCommand struct{}
+
+func (c *meCommand) Name() string           { return "me" }
+func (c *meCommand) Usage() string          { return "/me <action>" }
+func (c *meCommand) Description() string    { return "describe what you are doing" }
+func (c *meCommand) Permission() Permission { return PermissionMember }
+
+func (c *meCommand) Execute(ctx context.Context, call Call) (Result, error) {
+	if call.RawArgs == "" {
+		return Result{}, usageError(c)
+	}
+
+	return Posted(fmt.Sprintf("* %s %s", call.Caller.DisplayName, call.RawArgs)), nil
+}
+
+type topicCommand struct {
+	chatRepo repository.Chat
+	filter   ContentFilter
+}
+
+func (c *topicCommand) Name() string           { return "topic" }
+func (c *topicCommand) Usage() string          { return "/topic [new topic]" }
+func (c *topicCommand) Description() string    { return "show or change chat topic" }
+func (c *topicCommand) Permission() Permission { return PermissionMember }
+
+func (c *topicCommand) Execute(ctx context.Context, call Call) (Result, error) {
+	if call.RawArgs == "" {
+		if call.Chat.Topic == "" {
+			return Ephemeral("Topic is not set"), nil
+		}
+		return Ephemeral("Topic: " + call.Chat.Topic), nil
+	}
+
+	// everyone can see the topic, but only moderators can change it
+	if !call.CanModerate() {
+		return Result{}, models.ErrCommandAccessDenied
+	}
+	if len([]rune(call.RawArgs)) > models.MaxChatTopicLength {
+		return Result{}, models.ErrChatTopicTooLong
+	}
+
+	// topic is shown to all members, so it is filtered as messages are
+	filtered, err := c.filter.Apply(ctx, call.Chat.ID, call.Caller.ID, call.RawArgs, nil)
+	if err != nil {
+		return Result{}, err
+	}
+
+	if err := c.chatRepo.UpdateTopic(ctx, call.Chat.ID, filtered.Text); err != nil {
+		return Result{}, err
+	}
+
+	return Posted(fmt.Sprintf("* %s changed the topic to: %s", call.Caller.DisplayName, filtered.Text)), nil
+}
+
+type inviteCommand struct {
+	chatRepo  repository.Chat
+	userRepo  repository.User
+	blockRepo repository.UserBlock
+	events    EventDispatcher
+}
+
+func (c *inviteCommand) Name() string           { return "invite" }
+func (c *inviteCommand) Usage() string          { return "/invite <username>" }
+func (c *inviteCommand) Description() string    { return "add user to the chat" }
+func (c *inviteCommand) Permission() Permission { return PermissionModerator }
+
+func (c *inviteCommand) Execute(ctx context.Context, call Call) (Result, error) {
+	if len(call.Args) != 1 {
+		return Result{}, usageError(c)
+	}
+	if call.Chat.Type != models.ChatTypePrivate {
+		return Ephemeral("Chat is public, everyone can write here"), nil
+	}
+
+	user, err := c.userRepo.GetByUsername(ctx, strings.TrimPrefix(call.Args[0], "@"))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return Result{}, models.ErrUserNotFound
+		}
+		return Result{}, err
+	}
+
+	if user.Status == models.UserStatusDeleted {
+		return Result{}, models.ErrUserNotFound
+	}
+
+	// users can not be pulled into private conversation by someone they blocked
+	isBlocked, err := c.blockRepo.IsBlocked(ctx, user.ID, call.Caller.ID)
+	if err != nil {
+		return Result{}, err
+	}
+	if isBlocked {
+		return Result{}, models.ErrBlockedByUser
+	}
+
+	if err := c.chatRepo.JoinUser(ctx, call.Chat.ID, user.ID); err != nil {
+		if errors.Is(err, models.ErrChatAlreadyJoined) {
+			return Ephemeral(fmt.Sprintf("%s is already a member of the chat", user.DisplayName)), nil
+		}
+		return Result{}, err
+	}
+
+	c.events.Dispatch(ctx, models.NewMemberEvent(models.ChatEventMemberJoined, call.Chat.ID, user.ID, clock.Now()))
+
+	return Posted(fmt.Sprintf("* %s invited %s", call.Caller.DisplayName, user.DisplayName)), nil
+}
+
+type kickCommand struct {
+	chatRepo repository.Chat
+	userRepo repository.User
+	events   EventDispatcher
+}
+
+func (c *kickCommand) Name() string           { return "kick" }
+func (c *kickCommand) Usage() string          { return "/kick <username>" }
+func (c *kickCommand) Description() string    { return "remove user from the chat" }
+func (c *kickCommand) Permission() Permission { return PermissionModerator }
+
+func (c *kickCommand) Execute(ctx context.Context, call Call) (Result, error) {
+	if len(call.Args) != 1 {
+		return Result{}, usageError(c)
+	}
+
+	user, err := c.userRepo.GetByUsername(ctx, strings.TrimPrefix(call.Args[0], "@"))
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return Result{}, models.ErrUserNotFound
+		}
+		return Result{}, err
+	}
+
+	member, err := c.chatRepo.GetMember(ctx, call.Chat.ID, user.ID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			return Result{}, models.ErrChatMemberNotFound
+		}
+		return Result{}, err
+	}
+
+	isCallerOwner := call.Caller.ID == call.Chat.CreatorID || call.Caller.Type == models.UserTypeAdmin
+	switch {
+	case user.ID == call.Caller.ID, user.ID == call.Chat.CreatorID:
+		return Result{}, models.ErrCommandAccessDenied
+	// moderators can't kick each other, only chat creator and admins can
+	case member.Role == models.ChatRoleModerator && !isCallerOwner:
+		return Result{}, models.ErrCommandAccessDenied
+	}
+
+	if err := c.chatRepo.LeaveUser(ctx, call.Chat.ID, user.ID); err != nil {
+		return Result{}, err
+	}
+
+	c.events.Dispatch(ctx, models.NewMemberEvent(models.ChatEventMemberLeft, call.Chat.ID, user.ID, clock.Now()))
+
+	return Posted(fmt.Sprintf("* %s removed %s from the chat", call.Caller.DisplayName, user.DisplayName)), nil
+}
+
+type helpCommand struct {
+	registry *Registry
+	botRepo  repository.Bot
+}
+
+func (c *helpCommand) Name() string           { return "help" }
+func (c *helpCommand) Usage() string          { return "/help" }
+func (c *helpCommand) Description() string    { return "list available commands" }
+func (c *helpCommand) Permission() Permission { return PermissionMember }
+
+func (c *helpCommand) Execute(ctx context.Context, call Call) (Result, error) {
+	var help strings.Builder
+	help.WriteString("Available commands:")
+	for _, command := range c.registry.All() {
+		if call.Allowed(command.Permission()) {
+			fmt.Fprintf(&help, "\n%s - %s", command.Usage(), command.Description())
+		}
+	}
+
+	botCommands, err := c.botRepo.GetChatCommands(ctx, call.Chat.ID)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, command := range botCommands {
+		fmt.Fprintf(&help, "\n%s%s - %s", Prefix, command.Name, command.Description)
+	}
+
+	return Ephemeral(help.String()), nil
+}
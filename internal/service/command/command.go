@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"spsu-chat/internal/models"
+)
+
+const (
+	// PermissionMember allows command to everyone who can post into the chat.
+	PermissionMember Permission = iota
+	// PermissionModerator allows command to chat moderators, chat creator and global admins.
+	PermissionModerator
+	// PermissionAdmin allows command to global admins only.
+	PermissionAdmin
+)
+
+type Permission int8
+
+// Call is a single command invocation.
+type Call struct {
+	Caller models.User
+	Chat   models.Chat
+	// Member is caller chat membership, nil if caller has not joined the chat.
+	Member *models.ChatMember
+	Invocation
+}
+
+func (c Call) CanModerate() bool {
+	return models.CanModerateChat(c.Caller, c.Chat, c.Member)
+}
+
+func (c Call) Allowed(permission Permission) bool {
+	switch permission {
+	case PermissionMember:
+		return true
+	case PermissionModerator:
+		return c.CanModerate()
+	case PermissionAdmin:
+		return c.Caller.Type == models.UserTypeAdmin
+	}
+
+	return false
+}
+
+// Result is either a message posted into the chat on behalf of the caller
+// or an ephemeral reply visible only to the caller.
+type Result struct {
+	Post  string
+	Reply string
+}
+
+func Posted(text string) Result {
+	return Result{Post: text}
+}
+
+func Ephemeral(text string) Result {
+	return Result{Reply: text}
+}
+
+type Command interface {
+	Name() string
+	// Usage is a short syntax help, e.g. "/invite <username>".
+	Usage() string
+	Description() string
+	Permission() Permission
+	Execute(ctx context.Context, call Call) (Result, error)
+}
+
+type Registry struct {
+	commands map[string]Command
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		commands: make(map[string]Command),
+	}
+}
+
+// Register adds command to the registry, command with the same name is replaced.
+func (r *Registry) Register(commands ...Command) {
+	for _, command := range commands {
+		r.commands[command.Name()] = command
+	}
+}
+
+func (r *Registry) Get(name string) (Command, bool) {
+	command, ok := r.commands[name]
+	return command, ok
+}
+
+// All returns registered commands sorted by name.
+func (r *Registry) All() []Command {
+	commands := make([]Command, 0, len(r.commands))
+	for _, command := range r.commands {
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name() < commands[j].Name()
+	})
+
+	return commands
+}
+
+func usageError(command Command) error {
+	return fmt.Errorf("%w: usage: %s", models.ErrInvalidCommandArgs, command.Usage())
+}
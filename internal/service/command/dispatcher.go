@@ -0,0 +1,56 @@
+package command
+
+import (
+	"context"
+	"errors"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+)
+
+// Dispatcher runs commands from message text. Text starting with a name which
+// is not registered, e.g. bot commands or paths like "/etc/hosts", is posted as
+// is, so bots receive their commands with updates.
+type Dispatcher struct {
+	registry *Registry
+	chatRepo repository.Chat
+}
+
+func NewDispatcher(registry *Registry, chatRepo repository.Chat) *Dispatcher {
+	return &Dispatcher{
+		registry: registry,
+		chatRepo: chatRepo,
+	}
+}
+
+func (d *Dispatcher) Dispatch(ctx context.Context, caller models.User, chat models.Chat, text string) (Result, error) {
+	invocation, ok := Parse(text)
+	if !ok {
+		return Result{}, models.ErrUnknownCommand
+	}
+
+	command, ok := d.registry.Get(invocation.Name)
+	if !ok {
+		return Posted(text), nil
+	}
+
+	call := Call{
+		Caller:     caller,
+		Chat:       chat,
+		Invocation: invocation,
+	}
+	member, err := d.chatRepo.GetMember(ctx, chat.ID, caller.ID)
+	switch {
+	case err == nil:
+		call.Member = &member
+	case !errors.Is(err, apperror.ErrNotFound):
+		return Result{}, err
+	}
+
+	if !call.Allowed(command.Permission()) {
+		return Result{}, models.ErrCommandAccessDenied
+	}
+
+	return command.Execute(ctx, call)
+}
@@ -0,0 +1,55 @@
+package command_test
+
+import (
+	"context"
+	"testing"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/command"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChatRepo struct {
+	repository.Chat
+}
+
+func (f fakeChatRepo) GetMember(ctx context.Context, chatID, userID int64) (models.ChatMember, error) {
+	return models.ChatMember{}, apperror.ErrNotFound
+}
+
+type echoCommand struct{}
+
+func (echoCommand) Name() string                   { return "echo" }
+func (echoCommand) Usage() string                  { return "/echo <text>" }
+func (echoCommand) Description() string            { return "Replies with the text" }
+func (echoCommand) Permission() command.Permission { return command.PermissionMember }
+func (echoCommand) Execute(ctx context.Context, call command.Call) (command.Result, error) {
+	return command.Ephemeral(call.RawArgs), nil
+}
+
+func TestDispatch(t *testing.T) {
+	registry := command.NewRegistry()
+	registry.Register(echoCommand{})
+	dispatcher := command.NewDispatcher(registry, fakeChatRepo{})
+
+	testCases := []struct {
+		text   string
+		result command.Result
+	}{
+		{text: "/echo hi", result: command.Ephemeral("hi")},
+		// unregistered commands are posted as is
+		{text: "/weather Saint Petersburg", result: command.Posted("/weather Saint Petersburg")},
+		{text: "/etc/hosts is broken", result: command.Posted("/etc/hosts is broken")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			result, err := dispatcher.Dispatch(context.Background(), models.User{ID: 1}, models.Chat{ID: 1}, tc.text)
+			require.NoError(t, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package command
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	Prefix = "/"
+)
+
+type Invocation struct {
+	Name string
+	Args []string
+	// RawArgs is everything after command name as it was typed.
+	RawArgs string
+}
+
+// IsCommand reports whether message text is a command, i.e. starts with "/" followed by a letter.
+func IsCommand(text string) bool {
+	_, ok := Parse(text)
+	return ok
+}
+
+// Parse splits command text into name and arguments.
+// Arguments are separated by spaces, double quotes group several words into one argument.
+func Parse(text string) (Invocation, bool) {
+	if !strings.HasPrefix(text, Prefix) {
+		return Invocation{}, false
+	}
+	text = strings.TrimPrefix(text, Prefix)
+
+	nameEnd := strings.IndexFunc(text, unicode.IsSpace)
+	if nameEnd == -1 {
+		nameEnd = len(text)
+	}
+	name := strings.ToLower(text[:nameEnd])
+	if name == "" || !isLetter(rune(name[0])) {
+		return Invocation{}, false
+	}
+
+	rawArgs := strings.TrimSpace(text[nameEnd:])
+
+	return Invocation{
+		Name:    name,
+		Args:    splitArgs(rawArgs),
+		RawArgs: rawArgs,
+	}, true
+}
+
+func splitArgs(s string) []string {
+	args := make([]string, 0)
+
+	var (
+		current  strings.Builder
+		inQuotes bool
+		hasArg   bool
+	)
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuotes = !inQuotes
+			hasArg = true
+		case unicode.IsSpace(r) && !inQuotes:
+			if hasArg {
+				args = append(args, current.String())
+				current.Reset()
+				hasArg = false
+			}
+		default:
+			current.WriteRune(r)
+			hasArg = true
+		}
+	}
+	if hasArg {
+		args = append(args, current.String())
+	}
+
+	return args
+}
+
+func isLetter(r rune) bool {
+	return r >= 'a' && r <= 'z'
+}
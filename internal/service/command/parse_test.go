@@ -0,0 +1,42 @@
+package command_test
+
+import (
+	"testing"
+
+	"spsu-chat/internal/service/command"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		text    string
+		ok      bool
+		name    string
+		args    []string
+		rawArgs string
+	}{
+		{text: "/help", ok: true, name: "help", args: []string{}, rawArgs: ""},
+		{text: "/ME waves hand", ok: true, name: "me", args: []string{"waves", "hand"}, rawArgs: "waves hand"},
+		{text: "/topic  \"Exam   schedule\" here ", ok: true, name: "topic", args: []string{"Exam   schedule", "here"}, rawArgs: "\"Exam   schedule\" here"},
+		{text: "/invite \"\"", ok: true, name: "invite", args: []string{""}, rawArgs: "\"\""},
+		{text: "hello /help", ok: false},
+		{text: "/", ok: false},
+		{text: "/ help", ok: false},
+		{text: "/1abc", ok: false},
+		{text: "//escaped", ok: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.text, func(t *testing.T) {
+			invocation, ok := command.Parse(tc.text)
+			require.Equal(t, tc.ok, ok)
+			if !tc.ok {
+				return
+			}
+			require.Equal(t, tc.name, invocation.Name)
+			require.Equal(t, tc.args, invocation.Args)
+			require.Equal(t, tc.rawArgs, invocation.RawArgs)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"spsu-chat/internal/models"
+	"time"
+)
+
+// Config of services and their workers, missing intervals fall back to
+// defaults since workers can not tick with zero interval.
+type Config struct {
+	Webhook         WebhookConfig         `yaml:"webhook"`
+	IncomingWebhook IncomingWebhookConfig `yaml:"incomingWebhook"`
+	Retention       RetentionConfig       `yaml:"retention"`
+	Presence        PresenceConfig        `yaml:"presence"`
+	Export          ExportConfig          `yaml:"export"`
+	ChatExport      ChatExportConfig      `yaml:"chatExport"`
+	Import          ImportConfig          `yaml:"import"`
+	Scheduler       SchedulerConfig       `yaml:"scheduler"`
+	LinkPreview     LinkPreviewConfig     `yaml:"linkPreview"`
+	RateLimit       RateLimitConfig       `yaml:"rateLimit"`
+	Spam            SpamConfig            `yaml:"spam"`
+}
+
+type WebhookConfig struct {
+	// WorkerInterval is how often worker looks for pending deliveries.
+	WorkerInterval time.Duration `yaml:"workerInterval" env:"WEBHOOK_WORKER_INTERVAL" env-default:"5s"`
+	RequestTimeout time.Duration `yaml:"requestTimeout" env:"WEBHOOK_REQUEST_TIMEOUT" env-default:"10s"`
+	BatchSize      uint64        `yaml:"batchSize" env:"WEBHOOK_BATCH_SIZE" env-default:"50"`
+	// MaxAttempts is how many times delivery is attempted before it is marked as failed.
+	MaxAttempts int `yaml:"maxAttempts" env:"WEBHOOK_MAX_ATTEMPTS" env-default:"8"`
+	// Backoff is the delay before the first retry, every next retry waits twice longer.
+	Backoff    time.Duration `yaml:"backoff" env:"WEBHOOK_BACKOFF" env-default:"30s"`
+	MaxBackoff time.Duration `yaml:"maxBackoff" env:"WEBHOOK_MAX_BACKOFF" env-default:"1h"`
+	// MaxFailures is how many failed attempts in a row disable the webhook.
+	MaxFailures int `yaml:"maxFailures" env:"WEBHOOK_MAX_FAILURES" env-default:"20"`
+}
+
+type IncomingWebhookConfig struct {
+	// MessagesPerMinute is sustained rate of messages single webhook can post.
+	MessagesPerMinute int `yaml:"messagesPerMinute" env:"INCOMING_WEBHOOK_MESSAGES_PER_MINUTE" env-default:"30"`
+	Burst             int `yaml:"burst" env:"INCOMING_WEBHOOK_BURST" env-default:"10"`
+}
+
+type RetentionConfig struct {
+	// WorkerInterval is how often worker purges deleted messages.
+	WorkerInterval time.Duration `yaml:"workerInterval" env:"RETENTION_WORKER_INTERVAL" env-default:"1h"`
+	// DeletedMessagesTTL is how long soft-deleted messages are kept for moderation.
+	DeletedMessagesTTL time.Duration `yaml:"deletedMessagesTTL" env:"RETENTION_DELETED_MESSAGES_TTL" env-default:"720h"`
+	BatchSize          uint64        `yaml:"batchSize" env:"RETENTION_BATCH_SIZE" env-default:"1000"`
+	// ExpireInterval is how often worker deletes messages past their TTL or chat retention period.
+	ExpireInterval time.Duration `yaml:"expireInterval" env:"RETENTION_EXPIRE_INTERVAL" env-default:"10s"`
+}
+
+type PresenceConfig struct {
+	// OnlineTimeout is how long user stays online after the last request.
+	OnlineTimeout time.Duration `yaml:"onlineTimeout" env:"PRESENCE_ONLINE_TIMEOUT" env-default:"5m"`
+	// PersistInterval is how often last seen time is saved to the database.
+	PersistInterval time.Duration `yaml:"persistInterval" env:"PRESENCE_PERSIST_INTERVAL" env-default:"1m"`
+	// TypingTTL is how long typing signal is shown to other members.
+	TypingTTL time.Duration `yaml:"typingTTL" env:"PRESENCE_TYPING_TTL" env-default:"5s"`
+}
+
+type ExportConfig struct {
+	// WorkerInterval is how often worker looks for requested exports.
+	WorkerInterval time.Duration `yaml:"workerInterval" env:"EXPORT_WORKER_INTERVAL" env-default:"30s"`
+	// TTL is how long built archive is available for download.
+	TTL time.Duration `yaml:"ttl" env:"EXPORT_TTL" env-default:"168h"`
+	// BatchSize limits exports handled per tick and messages read per query.
+	BatchSize uint64 `yaml:"batchSize" env:"EXPORT_BATCH_SIZE" env-default:"1000"`
+}
+
+type ChatExportConfig struct {
+	// Timezone is IANA name of the timezone timestamps are written in, e.g. Europe/Moscow.
+	Timezone string `yaml:"timezone" env:"CHAT_EXPORT_TIMEZONE" env-default:"UTC"`
+}
+
+type ImportConfig struct {
+	// BatchSize is how many messages are inserted with a single query.
+	BatchSize int `yaml:"batchSize" env:"IMPORT_BATCH_SIZE" env-default:"500"`
+	// MaxFileSize limits size of uploaded export and total size of unpacked media files.
+	MaxFileSize int64 `yaml:"maxFileSize" env:"IMPORT_MAX_FILE_SIZE" env-default:"536870912"`
+}
+
+type SchedulerConfig struct {
+	// WorkerInterval is how often worker looks for due scheduled messages.
+	WorkerInterval time.Duration `yaml:"workerInterval" env:"SCHEDULER_WORKER_INTERVAL" env-default:"5s"`
+	BatchSize      uint64        `yaml:"batchSize" env:"SCHEDULER_BATCH_SIZE" env-default:"100"`
+}
+
+type LinkPreviewConfig struct {
+	// Workers is how many pages are fetched concurrently.
+	Workers int `yaml:"workers" env:"LINK_PREVIEW_WORKERS" env-default:"4"`
+	// QueueSize limits messages waiting for preview, new ones are skipped when it is full.
+	QueueSize int           `yaml:"queueSize" env:"LINK_PREVIEW_QUEUE_SIZE" env-default:"1000"`
+	Timeout   time.Duration `yaml:"timeout" env:"LINK_PREVIEW_TIMEOUT" env-default:"5s"`
+	// MaxBodySize limits how many bytes of the page are read.
+	MaxBodySize int64 `yaml:"maxBodySize" env:"LINK_PREVIEW_MAX_BODY_SIZE" env-default:"524288"`
+	// CacheTTL is how long fetched preview is reused for the same URL.
+	CacheTTL time.Duration `yaml:"cacheTTL" env:"LINK_PREVIEW_CACHE_TTL" env-default:"24h"`
+}
+
+const (
+	RateLimitBackendMemory   = "memory"
+	RateLimitBackendPostgres = "postgres"
+)
+
+type RateLimitConfig struct {
+	// Backend is where limits are counted: memory or postgres, postgres one is
+	// required to run several instances.
+	Backend string `yaml:"backend" env:"RATE_LIMIT_BACKEND" env-default:"memory"`
+	// Messages is how many messages single user can send per Period in all
+	// chats, zero disables the limit.
+	Messages int           `yaml:"messages" env:"RATE_LIMIT_MESSAGES"`
+	Period   time.Duration `yaml:"period" env:"RATE_LIMIT_PERIOD" env-default:"10s"`
+}
+
+type SpamConfig struct {
+	// RepeatCount is how many messages with the same text user can send to a
+	// chat within RepeatWindow, next ones are spam. Zero disables the check.
+	RepeatCount  int           `yaml:"repeatCount" env:"SPAM_REPEAT_COUNT"`
+	RepeatWindow time.Duration `yaml:"repeatWindow" env:"SPAM_REPEAT_WINDOW" env-default:"1m"`
+	// RepeatAction is block or flag, whole message can not be masked.
+	RepeatAction models.FilterAction `yaml:"repeatAction" env:"SPAM_REPEAT_ACTION" env-default:"block"`
+	// FloodCount is how many messages user can send to a chat within
+	// FloodWindow, next ones are flood. Zero disables the check.
+	FloodCount  int                 `yaml:"floodCount" env:"SPAM_FLOOD_COUNT"`
+	FloodWindow time.Duration       `yaml:"floodWindow" env:"SPAM_FLOOD_WINDOW" env-default:"30s"`
+	FloodAction models.FilterAction `yaml:"floodAction" env:"SPAM_FLOOD_ACTION" env-default:"flag"`
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+)
+
+type DraftService struct {
+	repo     repository.Draft
+	chatRepo repository.Chat
+}
+
+func NewDraftService(repo repository.Draft, chatRepo repository.Chat) *DraftService {
+	return &DraftService{
+		repo:     repo,
+		chatRepo: chatRepo,
+	}
+}
+
+func (d *DraftService) Get(ctx context.Context, userID, chatID int64) (models.Draft, error) {
+	if err := d.checkChat(ctx, userID, chatID); err != nil {
+		return models.Draft{}, err
+	}
+
+	draft, err := d.repo.Get(ctx, userID, chatID)
+
+	return draft, handleNotFoundError(err, models.ErrDraftNotFound)
+}
+
+func (d *DraftService) Save(ctx context.Context, input models.SaveDraftInput) (models.Draft, error) {
+	if err := d.checkChat(ctx, input.UserID, input.ChatID); err != nil {
+		return models.Draft{}, err
+	}
+
+	return d.repo.Save(ctx, models.SaveDraftRecord{
+		UserID:    input.UserID,
+		ChatID:    input.ChatID,
+		Text:      input.Text,
+		UpdatedAt: clock.Now(),
+	})
+}
+
+func (d *DraftService) Delete(ctx context.Context, userID, chatID int64) error {
+	if err := d.checkChat(ctx, userID, chatID); err != nil {
+		return err
+	}
+
+	return handleNotFoundError(d.repo.Delete(ctx, userID, chatID), models.ErrDraftNotFound)
+}
+
+// checkChat returns error if the user can't write to the chat, so drafts are
+// not kept for private chats the user is not a member of.
+func (d *DraftService) checkChat(ctx context.Context, userID, chatID int64) error {
+	chat, err := d.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if chat.Type != models.ChatTypePrivate {
+		return nil
+	}
+
+	isJoined, err := d.chatRepo.IsUserInChat(ctx, chat.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !isJoined {
+		return models.ErrChatNotJoined
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"spsu-chat/internal/filestorage"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/uploader"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/file"
+	"time"
+)
+
+const (
+	ExportFilename = "export.zip"
+)
+
+// ExportService builds archives of all user data in background.
+type ExportService struct {
+	repo           repository.DataExport
+	userRepo       repository.User
+	chatRepo       repository.Chat
+	messageRepo    repository.Message
+	attachmentRepo repository.Attachment
+	blockRepo      repository.UserBlock
+	uploader       *uploader.Uploader
+	fileStorage    filestorage.FileStorage
+	config         ExportConfig
+	logger         logger.Logger
+}
+
+func NewExportService(
+	repo repository.DataExport,
+	userRepo repository.User,
+	chatRepo repository.Chat,
+	messageRepo repository.Message,
+	attachmentRepo repository.Attachment,
+	blockRepo repository.UserBlock,
+	uploader *uploader.Uploader,
+	fileStorage filestorage.FileStorage,
+	config ExportConfig,
+	logger logger.Logger,
+) *ExportService {
+	return &ExportService{
+		repo:           repo,
+		userRepo:       userRepo,
+		chatRepo:       chatRepo,
+		messageRepo:    messageRepo,
+		attachmentRepo: attachmentRepo,
+		blockRepo:      blockRepo,
+		uploader:       uploader,
+		fileStorage:    fileStorage,
+		config:         config,
+		logger:         logger,
+	}
+}
+
+// Request schedules export of the user data.
+func (e *ExportService) Request(ctx context.Context, userID int64) (models.DataExport, error) {
+	return e.repo.Create(ctx, userID, clock.Now())
+}
+
+func (e *ExportService) GetAll(ctx context.Context, userID int64) ([]models.DataExport, error) {
+	return e.repo.GetAllByUser(ctx, userID)
+}
+
+// Download returns archive of the ready export owned by the user.
+func (e *ExportService) Download(ctx context.Context, userID int64, exportID int64) ([]byte, error) {
+	export, err := e.repo.GetByID(ctx, exportID)
+	if err != nil {
+		return nil, handleNotFoundError(err, models.ErrExportNotFound)
+	}
+	if export.UserID != userID {
+		return nil, models.ErrExportNotFound
+	}
+	if export.Status != models.DataExportReady || export.FileID == nil ||
+		(export.ExpiresAt != nil && !clock.Now().Before(*export.ExpiresAt)) {
+		return nil, models.ErrExportNotReady
+	}
+
+	return e.fileStorage.GetFile(e.bucket(userID), *export.FileID)
+}
+
+func (e *ExportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.config.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := e.buildPending(ctx); err != nil {
+				e.logger.Errorf("ExportService.Run: %s", err)
+			}
+			if err := e.removeExpired(ctx); err != nil {
+				e.logger.Errorf("ExportService.Run: %s", err)
+			}
+		}
+	}
+}
+
+func (e *ExportService) buildPending(ctx context.Context) error {
+	exports, err := e.repo.GetPending(ctx, e.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		update := models.UpdateDataExportRecord{
+			ID: export.ID,
+		}
+
+		fileInfo, err := e.build(ctx, export.UserID)
+		now := clock.Now()
+		update.CompletedAt = &now
+		if err != nil {
+			e.logger.Errorf("ExportService.build: export %d: %s", export.ID, err)
+			message := "failed to build export"
+			update.Status = models.DataExportFailed
+			update.Error = &message
+		} else {
+			expiresAt := now.Add(e.config.TTL)
+			update.Status = models.DataExportReady
+			update.FileID = &fileInfo.ID
+			update.ExpiresAt = &expiresAt
+		}
+
+		if err := e.repo.Update(ctx, update); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// build collects user data into zip archive and saves it to the file storage.
+func (e *ExportService) build(ctx context.Context, userID int64) (filestorage.FileInfo, error) {
+	user, err := e.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+	memberships, err := e.chatRepo.GetMemberships(ctx, userID)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+	blocked, err := e.blockRepo.GetBlocked(ctx, userID)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+	messages, err := e.getMessages(ctx, userID)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+	avatars, err := e.uploader.GetAvatars(userID)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+
+	documents := []struct {
+		name string
+		data any
+	}{
+		{name: "profile.json", data: user},
+		{name: "chats.json", data: memberships},
+		{name: "messages.json", data: messages},
+		{name: "blocked_users.json", data: blocked},
+	}
+
+	entries := make([]file.ZipEntry, 0, len(documents)+len(avatars)+len(messages))
+	for _, document := range documents {
+		data, err := json.MarshalIndent(document.data, "", "  ")
+		if err != nil {
+			return filestorage.FileInfo{}, fmt.Errorf("marshaling %s: %w", document.name, err)
+		}
+		entries = append(entries, file.ZipEntry{Name: document.name, Data: data})
+	}
+	for _, avatar := range avatars {
+		entries = append(entries, file.ZipEntry{
+			Name: path.Join("files", "avatars", avatar.Filename),
+			Data: avatar.Data,
+		})
+	}
+	for _, message := range messages {
+		for _, attachment := range message.Attachments {
+			data, err := e.getAttachment(message, attachment)
+			if err != nil {
+				return filestorage.FileInfo{}, err
+			}
+			if data == nil {
+				continue
+			}
+			entries = append(entries, file.ZipEntry{
+				// attachments of different messages can have the same name
+				Name: path.Join("files", "attachments", fmt.Sprintf("%d_%s", attachment.ID, attachment.Filename)),
+				Data: data,
+			})
+		}
+	}
+
+	archive, err := file.CreateZip(entries)
+	if err != nil {
+		return filestorage.FileInfo{}, err
+	}
+
+	return e.fileStorage.SaveFile(e.bucket(userID), ExportFilename, archive)
+}
+
+func (e *ExportService) getMessages(ctx context.Context, userID int64) ([]models.Message, error) {
+	var (
+		messages = make([]models.Message, 0)
+		afterID  int64
+	)
+	for {
+		batch, err := e.messageRepo.GetBySender(ctx, userID, afterID, e.config.BatchSize)
+		if err != nil {
+			return nil, err
+		}
+		if err := e.loadAttachments(ctx, batch); err != nil {
+			return nil, err
+		}
+		messages = append(messages, batch...)
+
+		if len(batch) == 0 || uint64(len(batch)) < e.config.BatchSize {
+			return messages, nil
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+}
+
+func (e *ExportService) loadAttachments(ctx context.Context, messages []models.Message) error {
+	ids := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.ID)
+	}
+
+	attachments, err := e.attachmentRepo.GetByMessageIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[int64][]models.Attachment, len(ids))
+	for _, attachment := range attachments {
+		byMessage[attachment.MessageID] = append(byMessage[attachment.MessageID], attachment)
+	}
+	for i := range messages {
+		messages[i].Attachments = byMessage[messages[i].ID]
+	}
+
+	return nil
+}
+
+// getAttachment reads attachment file, nil is returned if the file is gone.
+func (e *ExportService) getAttachment(message models.Message, attachment models.Attachment) ([]byte, error) {
+	// forwarded copies share the file uploaded to the original chat
+	chatID := message.ChatID
+	if message.ForwardedFromChatID != nil {
+		chatID = *message.ForwardedFromChatID
+	}
+
+	data, err := e.uploader.GetAttachment(chatID, attachment.FileID)
+	if err != nil {
+		if errors.Is(err, filestorage.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (e *ExportService) removeExpired(ctx context.Context) error {
+	exports, err := e.repo.GetExpired(ctx, clock.Now(), e.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		if export.FileID != nil {
+			if err := e.fileStorage.DeleteFile(e.bucket(export.UserID), *export.FileID); err != nil {
+				e.logger.Errorf("ExportService.removeExpired: export %d: %s", export.ID, err)
+			}
+		}
+
+		err := e.repo.Update(ctx, models.UpdateDataExportRecord{
+			ID:          export.ID,
+			Status:      models.DataExportExpired,
+			CompletedAt: export.CompletedAt,
+			ExpiresAt:   export.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *ExportService) bucket(userID int64) string {
+	return path.Join(filestorage.ExportsBucket, fmt.Sprintf("%d", userID))
+}
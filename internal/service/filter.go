@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/linkpreview"
+	"spsu-chat/pkg/markdown"
+	"spsu-chat/pkg/wordfilter"
+)
+
+// ContentFilterService checks messages before they are stored: banned words,
+// denied links and spam heuristics. Global rules are managed by admins, chat
+// rules are managed by chat managers and apply to the chat only.
+type ContentFilterService struct {
+	repo        repository.FilterRule
+	hitRepo     repository.FilterHit
+	messageRepo repository.Message
+	chatRepo    repository.Chat
+	config      SpamConfig
+}
+
+func NewContentFilterService(
+	repo repository.FilterRule,
+	hitRepo repository.FilterHit,
+	messageRepo repository.Message,
+	chatRepo repository.Chat,
+	config SpamConfig,
+) *ContentFilterService {
+	return &ContentFilterService{
+		repo:        repo,
+		hitRepo:     hitRepo,
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		config:      config,
+	}
+}
+
+// Check applies filter rules of the chat to the message. Masked text has the
+// same length in runes, so entities offsets stay valid.
+func (c *ContentFilterService) Check(ctx context.Context, chatID, senderID int64, text string, entities models.MessageEntities) (models.FilterResult, error) {
+	result := models.FilterResult{
+		ChatID:   chatID,
+		SenderID: senderID,
+		Source:   text,
+		Text:     text,
+		Entities: entities,
+	}
+
+	rules, err := c.repo.GetForChat(ctx, chatID)
+	if err != nil {
+		return result, err
+	}
+
+	words := make([]models.FilterRule, 0)
+	patterns := make([]string, 0)
+	deny := make([]models.FilterRule, 0)
+	allow := make([]models.FilterRule, 0)
+	for _, rule := range rules {
+		switch rule.Kind {
+		case models.FilterRuleWord:
+			words = append(words, rule)
+			patterns = append(patterns, rule.Pattern)
+		case models.FilterRuleLinkDeny:
+			deny = append(deny, rule)
+		case models.FilterRuleLinkAllow:
+			allow = append(allow, rule)
+		}
+	}
+
+	masked := make([][2]int, 0)
+	if len(words) > 0 {
+		for _, match := range wordfilter.New(patterns).Find(text) {
+			rule := words[match.Pattern]
+			result.Add(ruleMatch(rule, text[match.Start:match.End]))
+			if rule.Action == models.FilterActionMask {
+				masked = append(masked, [2]int{match.Start, match.End})
+			}
+		}
+	}
+
+	if len(deny) > 0 {
+		for _, loc := range linkpreview.FindURLs(text) {
+			link := text[loc[0]:loc[1]]
+			if rule, ok := matchLink(link, deny, allow); ok {
+				result.Add(ruleMatch(rule, link))
+				if rule.Action == models.FilterActionMask {
+					masked = append(masked, loc)
+				}
+			}
+		}
+
+		// masked links keep their text but lose the URL
+		kept := make(models.MessageEntities, 0, len(entities))
+		for _, entity := range entities {
+			if entity.Type == markdown.EntityLink {
+				if rule, ok := matchLink(entity.URL, deny, allow); ok {
+					result.Add(ruleMatch(rule, entity.URL))
+					if rule.Action == models.FilterActionMask {
+						continue
+					}
+				}
+			}
+			kept = append(kept, entity)
+		}
+		if len(kept) != len(entities) {
+			result.Entities = kept
+		}
+	}
+
+	result.Text = wordfilter.Mask(text, masked)
+
+	if err := c.checkSpam(ctx, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// checkSpam matches repeated messages and flood of the sender in the chat.
+func (c *ContentFilterService) checkSpam(ctx context.Context, result *models.FilterResult) error {
+	if c.config.RepeatCount <= 0 && c.config.FloodCount <= 0 {
+		return nil
+	}
+
+	now := clock.Now()
+	stats, err := c.messageRepo.GetRecentStats(ctx, models.GetRecentMessagesStatsRecord{
+		ChatID:        result.ChatID,
+		SenderID:      result.SenderID,
+		Text:          result.Text,
+		MessagesSince: now.Add(-c.config.FloodWindow),
+		RepeatedSince: now.Add(-c.config.RepeatWindow),
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.config.RepeatCount > 0 && stats.Repeated >= uint64(c.config.RepeatCount) {
+		result.Add(models.FilterMatch{
+			Kind:   models.FilterRuleRepeat,
+			Action: spamAction(c.config.RepeatAction),
+		})
+	}
+	if c.config.FloodCount > 0 && stats.Messages >= uint64(c.config.FloodCount) {
+		result.Add(models.FilterMatch{
+			Kind:   models.FilterRuleFlood,
+			Action: spamAction(c.config.FloodAction),
+		})
+	}
+
+	return nil
+}
+
+// Apply checks the message like Check, blocked message is logged and
+// models.ErrMessageBlocked is returned.
+func (c *ContentFilterService) Apply(ctx context.Context, chatID, senderID int64, text string, entities models.MessageEntities) (models.FilterResult, error) {
+	result, err := c.Check(ctx, chatID, senderID, text, entities)
+	if err != nil {
+		return result, err
+	}
+	if result.Blocked {
+		if err := c.Record(ctx, result, nil); err != nil {
+			return result, err
+		}
+		return result, models.ErrMessageBlocked
+	}
+
+	return result, nil
+}
+
+// Record logs matches of the message for admins, messageID is nil for blocked messages.
+func (c *ContentFilterService) Record(ctx context.Context, result models.FilterResult, messageID *int64) error {
+	hits := make([]models.CreateFilterHitRecord, 0, len(result.Matches))
+	for _, match := range result.Matches {
+		hits = append(hits, models.CreateFilterHitRecord{
+			RuleID:    match.RuleID,
+			ChatID:    result.ChatID,
+			UserID:    result.SenderID,
+			MessageID: messageID,
+			Kind:      match.Kind,
+			Action:    match.Action,
+			Match:     match.Match,
+			Text:      result.Source,
+			CreatedAt: clock.Now(),
+		})
+	}
+
+	return c.hitRepo.Create(ctx, hits)
+}
+
+// GetRules returns rules of the chat or global rules if chatID is nil.
+func (c *ContentFilterService) GetRules(ctx context.Context, user models.User, chatID *int64) ([]models.FilterRule, error) {
+	if err := c.checkAccess(ctx, user, chatID); err != nil {
+		return nil, err
+	}
+
+	return c.repo.GetAll(ctx, chatID)
+}
+
+func (c *ContentFilterService) CreateRule(ctx context.Context, user models.User, input models.CreateFilterRuleInput) (models.FilterRule, error) {
+	if err := c.checkAccess(ctx, user, input.ChatID); err != nil {
+		return models.FilterRule{}, err
+	}
+
+	return c.repo.Create(ctx, models.CreateFilterRuleRecord{
+		ChatID:    input.ChatID,
+		Kind:      input.Kind,
+		Pattern:   input.Pattern,
+		Action:    input.Action,
+		CreatedBy: input.CreatedBy,
+		CreatedAt: clock.Now(),
+	})
+}
+
+func (c *ContentFilterService) DeleteRule(ctx context.Context, user models.User, chatID *int64, ruleID int64) error {
+	if err := c.checkAccess(ctx, user, chatID); err != nil {
+		return err
+	}
+
+	rule, err := c.repo.GetByID(ctx, ruleID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrFilterRuleNotFound)
+	}
+	// global rules can not be deleted through chat and vice versa
+	if (rule.ChatID == nil) != (chatID == nil) || (chatID != nil && *rule.ChatID != *chatID) {
+		return models.ErrFilterRuleNotFound
+	}
+
+	return handleNotFoundError(c.repo.Delete(ctx, rule.ID), models.ErrFilterRuleNotFound)
+}
+
+func (c *ContentFilterService) GetHits(ctx context.Context, pagination models.Pagination, filters models.GetFilterHitsFilters) ([]models.FilterHit, models.FullPagination, error) {
+	hits, count, err := c.hitRepo.GetAll(ctx, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	}, filters)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	return hits, pagination.GetFull(count), nil
+}
+
+// checkAccess allows admins to manage global rules and chat managers to manage chat rules.
+func (c *ContentFilterService) checkAccess(ctx context.Context, user models.User, chatID *int64) error {
+	if chatID == nil {
+		if user.Type != models.UserTypeAdmin {
+			return models.ErrChatAccessDenied
+		}
+		return nil
+	}
+
+	chat, err := c.chatRepo.GetByID(ctx, *chatID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return models.ErrChatAccessDenied
+	}
+
+	return nil
+}
+
+func ruleMatch(rule models.FilterRule, match string) models.FilterMatch {
+	return models.FilterMatch{
+		RuleID: &rule.ID,
+		Kind:   rule.Kind,
+		Action: rule.Action,
+		Match:  match,
+	}
+}
+
+// matchLink returns the first deny rule of the link host unless it is allowed.
+func matchLink(link string, deny, allow []models.FilterRule) (models.FilterRule, bool) {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Hostname() == "" {
+		return models.FilterRule{}, false
+	}
+	host := parsed.Hostname()
+
+	for _, rule := range allow {
+		if rule.MatchesHost(host) {
+			return models.FilterRule{}, false
+		}
+	}
+	for _, rule := range deny {
+		if rule.MatchesHost(host) {
+			return rule, true
+		}
+	}
+	return models.FilterRule{}, false
+}
+
+// spamAction returns action of spam heuristic, whole message can not be masked
+// so mask is treated as flag.
+func spamAction(action models.FilterAction) models.FilterAction {
+	if action == models.FilterActionBlock {
+		return action
+	}
+	return models.FilterActionFlag
+}
@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeFilterRuleRepo struct {
+	repository.FilterRule
+	rules []models.FilterRule
+}
+
+func (f *fakeFilterRuleRepo) GetForChat(ctx context.Context, chatID int64) ([]models.FilterRule, error) {
+	return f.rules, nil
+}
+
+type fakeFilterHitRepo struct {
+	repository.FilterHit
+	hits []models.CreateFilterHitRecord
+}
+
+func (f *fakeFilterHitRepo) Create(ctx context.Context, hits []models.CreateFilterHitRecord) error {
+	f.hits = append(f.hits, hits...)
+	return nil
+}
+
+// fakeRecentMessagesRepo returns stats of the sent messages, so spam
+// heuristics see the same windows as the database query.
+type fakeRecentMessagesRepo struct {
+	repository.Message
+	sent    []models.Message
+	records []models.GetRecentMessagesStatsRecord
+}
+
+func (f *fakeRecentMessagesRepo) GetRecentStats(ctx context.Context, record models.GetRecentMessagesStatsRecord) (models.RecentMessagesStats, error) {
+	f.records = append(f.records, record)
+
+	var stats models.RecentMessagesStats
+	for _, message := range f.sent {
+		if message.ChatID != record.ChatID || message.SenderID != record.SenderID {
+			continue
+		}
+		if !message.CreatedAt.Before(record.MessagesSince) {
+			stats.Messages++
+		}
+		if !message.CreatedAt.Before(record.RepeatedSince) && message.Text == record.Text {
+			stats.Repeated++
+		}
+	}
+
+	return stats, nil
+}
+
+func TestContentFilterRules(t *testing.T) {
+	ctx := context.Background()
+	rules := &fakeFilterRuleRepo{rules: []models.FilterRule{
+		{ID: 1, Kind: models.FilterRuleWord, Pattern: "дурак", Action: models.FilterActionMask},
+		{ID: 2, Kind: models.FilterRuleWord, Pattern: "казино*", Action: models.FilterActionBlock},
+		{ID: 3, Kind: models.FilterRuleLinkDeny, Pattern: models.LinkPatternAny, Action: models.FilterActionFlag},
+		{ID: 4, Kind: models.FilterRuleLinkAllow, Pattern: "example.com"},
+	}}
+	hits := &fakeFilterHitRepo{}
+	filter := NewContentFilterService(rules, hits, &fakeRecentMessagesRepo{}, nil, SpamConfig{})
+
+	result, err := filter.Check(ctx, 1, 2, "ты дурак", nil)
+	require.NoError(t, err)
+	require.False(t, result.Blocked)
+	require.Equal(t, "ты *****", result.Text)
+	require.Equal(t, "ты дурак", result.Source)
+
+	result, err = filter.Check(ctx, 1, 2, "лучшее онлайн-казино", nil)
+	require.NoError(t, err)
+	require.True(t, result.Blocked)
+
+	result, err = filter.Check(ctx, 1, 2, "see https://docs.example.com/a and https://evil.test/b", nil)
+	require.NoError(t, err)
+	require.False(t, result.Blocked)
+	require.Len(t, result.Matches, 1)
+	require.Equal(t, "https://evil.test/b", result.Matches[0].Match)
+
+	messageID := int64(10)
+	require.NoError(t, filter.Record(ctx, result, &messageID))
+	require.Len(t, hits.hits, 1)
+	require.Equal(t, models.FilterActionFlag, hits.hits[0].Action)
+	require.Equal(t, &messageID, hits.hits[0].MessageID)
+}
+
+func TestContentFilterSpam(t *testing.T) {
+	ctx := context.Background()
+	now := clock.Now()
+
+	messages := &fakeRecentMessagesRepo{sent: []models.Message{
+		{ChatID: 1, SenderID: 2, Text: "buy now", CreatedAt: now.Add(-30 * time.Second)},
+		{ChatID: 1, SenderID: 2, Text: "buy now", CreatedAt: now.Add(-20 * time.Second)},
+		// outside of the repeat window
+		{ChatID: 1, SenderID: 2, Text: "buy now", CreatedAt: now.Add(-2 * time.Minute)},
+		// other user and other chat are not counted
+		{ChatID: 1, SenderID: 3, Text: "buy now", CreatedAt: now.Add(-time.Second)},
+		{ChatID: 2, SenderID: 2, Text: "buy now", CreatedAt: now.Add(-time.Second)},
+	}}
+	filter := NewContentFilterService(&fakeFilterRuleRepo{}, &fakeFilterHitRepo{}, messages, nil, SpamConfig{
+		RepeatCount:  2,
+		RepeatWindow: time.Minute,
+		RepeatAction: models.FilterActionBlock,
+		FloodCount:   3,
+		FloodWindow:  time.Minute,
+		FloodAction:  models.FilterActionMask,
+	})
+
+	result, err := filter.Check(ctx, 1, 2, "buy now", nil)
+	require.NoError(t, err)
+	require.True(t, result.Blocked)
+	require.Equal(t, []models.FilterMatch{{Kind: models.FilterRuleRepeat, Action: models.FilterActionBlock}}, result.Matches)
+
+	require.Len(t, messages.records, 1)
+	record := messages.records[0]
+	require.Equal(t, int64(1), record.ChatID)
+	require.Equal(t, int64(2), record.SenderID)
+	require.Equal(t, "buy now", record.Text)
+	require.WithinDuration(t, now.Add(-time.Minute), record.MessagesSince, time.Second)
+	require.WithinDuration(t, now.Add(-time.Minute), record.RepeatedSince, time.Second)
+
+	// flood can not be masked, so it is flagged
+	messages.sent = append(messages.sent, models.Message{ChatID: 1, SenderID: 2, Text: "hi", CreatedAt: now})
+	result, err = filter.Check(ctx, 1, 2, "another one", nil)
+	require.NoError(t, err)
+	require.False(t, result.Blocked)
+	require.Equal(t, []models.FilterMatch{{Kind: models.FilterRuleFlood, Action: models.FilterActionFlag}}, result.Matches)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"errors"
 	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
 )
 
 func handleNotFoundError(err error, newErr error) error {
@@ -11,3 +12,8 @@ func handleNotFoundError(err error, newErr error) error {
 	}
 	return err
 }
+
+// canManageChat reports whether user is allowed to change chat settings.
+func canManageChat(user models.User, chat models.Chat) bool {
+	return chat.CreatorID == user.ID || user.Type == models.UserTypeAdmin
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/ratelimit"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/token"
+)
+
+const (
+	integrationUsernamePrefix = "integration-"
+	integrationUsernameLength = 8
+)
+
+type IncomingWebhookService struct {
+	repo     repository.IncomingWebhook
+	chatRepo repository.Chat
+	messages Message
+	limiter  ratelimit.Limiter
+	config   IncomingWebhookConfig
+}
+
+func NewIncomingWebhookService(
+	repo repository.IncomingWebhook,
+	chatRepo repository.Chat,
+	messages Message,
+	limiter ratelimit.Limiter,
+	config IncomingWebhookConfig,
+) *IncomingWebhookService {
+	return &IncomingWebhookService{
+		repo:     repo,
+		chatRepo: chatRepo,
+		messages: messages,
+		limiter:  limiter,
+		config:   config,
+	}
+}
+
+// Create registers incoming webhook together with its integration identity,
+// which is joined to the chat, so it can post into private chats too.
+func (i *IncomingWebhookService) Create(ctx context.Context, user models.User, input models.CreateIncomingWebhookInput) (models.IncomingWebhookCredentials, error) {
+	if _, err := i.getManagedChat(ctx, user, input.ChatID); err != nil {
+		return models.IncomingWebhookCredentials{}, err
+	}
+
+	suffix, err := token.Generate(integrationUsernameLength)
+	if err != nil {
+		return models.IncomingWebhookCredentials{}, fmt.Errorf("IncomingWebhookService.Create: %w", err)
+	}
+	webhookToken, err := token.Generate(token.DefaultLength)
+	if err != nil {
+		return models.IncomingWebhookCredentials{}, fmt.Errorf("IncomingWebhookService.Create: %w", err)
+	}
+
+	now := clock.Now()
+	webhook, err := i.repo.Create(ctx, models.CreateIncomingWebhookRecord{
+		ChatID:    input.ChatID,
+		CreatorID: user.ID,
+		Integration: models.CreateUserRecord{
+			Username:    integrationUsernamePrefix + suffix,
+			DisplayName: input.Name,
+			// integrations can't sign in with password
+			PasswordHash: []byte{},
+			Type:         models.UserTypeIntegration,
+			CreatedAt:    now,
+		},
+		Name:      input.Name,
+		TokenHash: token.Hash(webhookToken),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return models.IncomingWebhookCredentials{}, err
+	}
+
+	return models.IncomingWebhookCredentials{Webhook: webhook, Token: webhookToken}, nil
+}
+
+func (i *IncomingWebhookService) GetAll(ctx context.Context, user models.User, chatID int64) ([]models.IncomingWebhook, error) {
+	if _, err := i.getManagedChat(ctx, user, chatID); err != nil {
+		return nil, err
+	}
+
+	return i.repo.GetAllByChat(ctx, chatID)
+}
+
+// Revoke disables webhook token and removes its integration identity from the chat.
+func (i *IncomingWebhookService) Revoke(ctx context.Context, user models.User, chatID int64, webhookID int64) error {
+	if _, err := i.getManagedChat(ctx, user, chatID); err != nil {
+		return err
+	}
+
+	webhook, err := i.repo.GetByID(ctx, webhookID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrIncomingWebhookNotFound)
+	}
+	if webhook.ChatID != chatID {
+		return models.ErrIncomingWebhookNotFound
+	}
+
+	return i.repo.Revoke(ctx, webhook.ID, clock.Now())
+}
+
+// Post creates message in the webhook chat on behalf of its integration identity.
+func (i *IncomingWebhookService) Post(ctx context.Context, webhookToken string, input models.IncomingWebhookMessageInput) (models.Message, error) {
+	webhook, err := i.repo.GetActiveByTokenHash(ctx, token.Hash(webhookToken))
+	if err != nil {
+		return models.Message{}, handleNotFoundError(err, models.ErrIncomingWebhookNotFound)
+	}
+
+	message := models.NewCreateMessageInput(webhook.ChatID, webhook.UserID, input.Text)
+	message.SenderName = input.DisplayName
+
+	if i.config.MessagesPerMinute <= 0 {
+		return i.messages.Create(ctx, message)
+	}
+
+	// limits are shared by all instances when postgres limiter is used
+	key := fmt.Sprintf("webhook:%d", webhook.ID)
+	burst := max(i.config.Burst, 1)
+	limit := ratelimit.Limit{
+		Count:  burst,
+		Period: time.Duration(burst) * time.Minute / time.Duration(i.config.MessagesPerMinute),
+	}
+	wait, err := i.limiter.Allow(ctx, key, limit, clock.Now())
+	if err != nil {
+		return models.Message{}, err
+	}
+	if wait > 0 {
+		return models.Message{}, models.RateLimitError{RetryAfter: wait}
+	}
+
+	created, err := i.messages.Create(ctx, message)
+	if err != nil && created.ID == 0 {
+		return created, errors.Join(err, i.limiter.Release(ctx, key, limit))
+	}
+
+	return created, err
+}
+
+func (i *IncomingWebhookService) getManagedChat(ctx context.Context, user models.User, chatID int64) (models.Chat, error) {
+	chat, err := i.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return chat, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return chat, models.ErrChatAccessDenied
+	}
+
+	return chat, nil
+}
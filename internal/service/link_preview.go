@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/linkpreview"
+	"spsu-chat/pkg/markdown"
+	"sync"
+)
+
+// PreviewFetcher downloads page metadata, it must not connect to private networks.
+type PreviewFetcher interface {
+	Fetch(ctx context.Context, url string) (linkpreview.Preview, error)
+}
+
+// LinkPreviewService attaches preview of the first link to created messages.
+// Messages are queued in memory and handled by workers, so page fetching
+// does not slow down sending.
+type LinkPreviewService struct {
+	repo    repository.LinkPreview
+	fetcher PreviewFetcher
+	events  EventDispatcher
+	queue   chan models.Message
+	config  LinkPreviewConfig
+	logger  logger.Logger
+}
+
+func NewLinkPreviewService(
+	repo repository.LinkPreview,
+	fetcher PreviewFetcher,
+	events EventDispatcher,
+	config LinkPreviewConfig,
+	logger logger.Logger,
+) *LinkPreviewService {
+	return &LinkPreviewService{
+		repo:    repo,
+		fetcher: fetcher,
+		events:  events,
+		queue:   make(chan models.Message, config.QueueSize),
+		config:  config,
+		logger:  logger,
+	}
+}
+
+// Enqueue schedules preview of the message link, messages are dropped if the
+// queue is full.
+func (l *LinkPreviewService) Enqueue(message models.Message) {
+	if previewURL(message) == "" {
+		return
+	}
+
+	select {
+	case l.queue <- message:
+	default:
+		l.logger.Warnf("LinkPreviewService.Enqueue: queue is full, message %d is skipped", message.ID)
+	}
+}
+
+func (l *LinkPreviewService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < max(l.config.Workers, 1); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case message := <-l.queue:
+					if err := l.attach(ctx, message); err != nil {
+						l.logger.Errorf("LinkPreviewService.Run: message %d: %s", message.ID, err)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// attach sets preview of the message and notifies chat subscribers.
+func (l *LinkPreviewService) attach(ctx context.Context, message models.Message) error {
+	preview, err := l.get(ctx, previewURL(message))
+	if err != nil || preview.Failed {
+		return err
+	}
+
+	if err := l.repo.Attach(ctx, message.ID, preview.URL); err != nil {
+		// message was deleted before preview was fetched
+		if errors.Is(err, apperror.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	message.LinkPreviewURL = &preview.URL
+	message.LinkPreview = &preview
+	l.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageUpdated, message, clock.Now()))
+
+	return nil
+}
+
+// get returns cached preview of the URL fetching the page if cache is missing or stale.
+func (l *LinkPreviewService) get(ctx context.Context, url string) (models.LinkPreview, error) {
+	cached, err := l.repo.GetByURL(ctx, url)
+	if err == nil && cached.IsFresh(clock.Now(), l.config.CacheTTL) {
+		return cached, nil
+	}
+	if err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return models.LinkPreview{}, err
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, l.config.Timeout)
+	defer cancel()
+
+	fetched, err := l.fetcher.Fetch(fetchCtx, url)
+	if err != nil {
+		l.logger.Infof("LinkPreviewService.get: fetching %s: %s", url, err)
+	}
+
+	return l.repo.Save(ctx, models.SaveLinkPreviewRecord{
+		URL:         url,
+		Title:       fetched.Title,
+		Description: fetched.Description,
+		ImageURL:    fetched.ImageURL,
+		SiteName:    fetched.SiteName,
+		Failed:      err != nil || fetched.IsEmpty(),
+		FetchedAt:   clock.Now(),
+	})
+}
+
+// previewURL returns the first link of the message, formatted links go first.
+func previewURL(message models.Message) string {
+	for _, entity := range message.Entities {
+		if entity.Type == markdown.EntityLink {
+			return entity.URL
+		}
+	}
+	return linkpreview.FindURL(message.Text)
+}
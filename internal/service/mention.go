@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/mention"
+)
+
+type MentionService struct {
+	repo      repository.Mention
+	chatRepo  repository.Chat
+	userRepo  repository.User
+	blockRepo repository.UserBlock
+	presence  *PresenceService
+}
+
+func NewMentionService(
+	repo repository.Mention,
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	blockRepo repository.UserBlock,
+	presence *PresenceService,
+) *MentionService {
+	return &MentionService{
+		repo:      repo,
+		chatRepo:  chatRepo,
+		userRepo:  userRepo,
+		blockRepo: blockRepo,
+		presence:  presence,
+	}
+}
+
+// Record stores mentions of the message. Unknown usernames are ignored, as well as
+// users who can not see the private chat or blocked the sender. @all and @here are allowed to chat moderators only.
+func (m *MentionService) Record(ctx context.Context, chat models.Chat, message models.Message) error {
+	usernames := mention.Parse(message.Text)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	mentioned := make(map[int64]models.MentionKind)
+	for _, username := range usernames {
+		switch username {
+		case mention.All, mention.Here:
+			if err := m.resolveBroadcast(ctx, chat, message, username, mentioned); err != nil {
+				return err
+			}
+		default:
+			user, err := m.userRepo.GetByUsername(ctx, username)
+			if err != nil {
+				if errors.Is(err, apperror.ErrNotFound) {
+					continue
+				}
+				return err
+			}
+			mentioned[user.ID] = models.MentionKindUser
+		}
+	}
+	delete(mentioned, message.SenderID)
+
+	// users who blocked the sender are not notified
+	blockerIDs, err := m.blockRepo.GetBlockerIDs(ctx, message.SenderID)
+	if err != nil {
+		return err
+	}
+	for _, id := range blockerIDs {
+		delete(mentioned, id)
+	}
+
+	records := make([]models.CreateMentionRecord, 0, len(mentioned))
+	for userID, kind := range mentioned {
+		if chat.Type == models.ChatTypePrivate && kind == models.MentionKindUser {
+			isJoined, err := m.chatRepo.IsUserInChat(ctx, chat.ID, userID)
+			if err != nil {
+				return err
+			}
+			if !isJoined {
+				continue
+			}
+		}
+
+		records = append(records, models.CreateMentionRecord{
+			MessageID: message.ID,
+			ChatID:    chat.ID,
+			UserID:    userID,
+			Kind:      kind,
+			CreatedAt: message.CreatedAt,
+		})
+	}
+
+	return m.repo.Create(ctx, records)
+}
+
+// resolveBroadcast adds chat members to mentioned if the sender can moderate the chat,
+// @here mentions online members only.
+// Direct user mentions take precedence over broadcast ones.
+func (m *MentionService) resolveBroadcast(
+	ctx context.Context,
+	chat models.Chat,
+	message models.Message,
+	username string,
+	mentioned map[int64]models.MentionKind,
+) error {
+	sender, err := m.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	var member *models.ChatMember
+	senderMember, err := m.chatRepo.GetMember(ctx, chat.ID, sender.ID)
+	switch {
+	case err == nil:
+		member = &senderMember
+	case !errors.Is(err, apperror.ErrNotFound):
+		return err
+	}
+	if !models.CanModerateChat(sender, chat, member) {
+		return nil
+	}
+
+	var kind models.MentionKind = models.MentionKindAll
+	if username == mention.Here {
+		kind = models.MentionKindHere
+	}
+
+	memberIDs, err := m.chatRepo.GetMemberIDs(ctx, chat.ID)
+	if err != nil {
+		return err
+	}
+	for _, id := range memberIDs {
+		if kind == models.MentionKindHere && !m.presence.IsOnline(id) {
+			continue
+		}
+		if _, ok := mentioned[id]; !ok {
+			mentioned[id] = kind
+		}
+	}
+
+	return nil
+}
+
+func (m *MentionService) GetAll(ctx context.Context, userID int64, pagination models.Pagination, filters models.GetMentionsFilters) ([]models.Mention, models.FullPagination, error) {
+	filters.VisibleAt = clock.Now()
+	mentions, count, err := m.repo.GetAll(ctx, userID, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	}, filters)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	return mentions, pagination.GetFull(count), nil
+}
+
+func (m *MentionService) CountUnread(ctx context.Context, userID int64) (uint64, error) {
+	return m.repo.CountUnread(ctx, userID, clock.Now())
+}
+
+func (m *MentionService) MarkRead(ctx context.Context, userID int64, ids []int64) error {
+	return m.repo.MarkRead(ctx, userID, ids, clock.Now())
+}
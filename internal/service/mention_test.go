@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMentionRepo hides mentions in messages expired by the given time,
+// the same as the database query does.
+type fakeMentionRepo struct {
+	repository.Mention
+	mentions  []models.Mention
+	expiresAt map[int64]time.Time
+}
+
+func (f *fakeMentionRepo) visible(now time.Time) []models.Mention {
+	visible := make([]models.Mention, 0)
+	for _, mention := range f.mentions {
+		if expiresAt, ok := f.expiresAt[mention.MessageID]; ok && !expiresAt.After(now) {
+			continue
+		}
+		visible = append(visible, mention)
+	}
+
+	return visible
+}
+
+func (f *fakeMentionRepo) GetAll(ctx context.Context, userID int64, pagination models.DBPagination, filters models.GetMentionsFilters) ([]models.Mention, uint64, error) {
+	mentions := f.visible(filters.VisibleAt)
+	return mentions, uint64(len(mentions)), nil
+}
+
+func (f *fakeMentionRepo) CountUnread(ctx context.Context, userID int64, now time.Time) (uint64, error) {
+	var count uint64
+	for _, mention := range f.visible(now) {
+		if mention.ReadAt == nil {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func TestMentionsSkipExpiredMessages(t *testing.T) {
+	ctx := context.Background()
+	now := clock.Now()
+
+	repo := &fakeMentionRepo{
+		mentions: []models.Mention{
+			{ID: 1, MessageID: 10, ChatID: 1, UserID: 2},
+			{ID: 2, MessageID: 11, ChatID: 1, UserID: 2},
+			{ID: 3, MessageID: 12, ChatID: 1, UserID: 2},
+		},
+		expiresAt: map[int64]time.Time{
+			10: now.Add(-time.Minute),
+			11: now.Add(time.Hour),
+		},
+	}
+	mentions := NewMentionService(repo, nil, nil, nil, nil)
+
+	result, pagination, err := mentions.GetAll(ctx, 2, models.Pagination{Page: 1, PageLimit: 10}, models.GetMentionsFilters{})
+	require.NoError(t, err)
+	require.Equal(t, []int64{11, 12}, []int64{result[0].MessageID, result[1].MessageID})
+	require.Equal(t, uint64(2), pagination.Total)
+
+	count, err := mentions.CountUnread(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+}
@@ -3,51 +3,362 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
 	"spsu-chat/internal/apperror"
 	"spsu-chat/internal/models"
+	"spsu-chat/internal/ratelimit"
 	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/command"
 	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/markdown"
+	"time"
 )
 
+// LinkPreviewQueue attaches link previews to messages in background.
+type LinkPreviewQueue interface {
+	Enqueue(message models.Message)
+}
+
 type MessageService struct {
-	repo     repository.Message
-	chatRepo repository.Chat
+	repo           repository.Message
+	chatRepo       repository.Chat
+	userRepo       repository.User
+	blockRepo      repository.UserBlock
+	attachmentRepo repository.Attachment
+	pollRepo       repository.Poll
+	previewRepo    repository.LinkPreview
+	draftRepo      repository.Draft
+	previews       LinkPreviewQueue
+	limiter        ratelimit.Limiter
+	rateLimit      RateLimitConfig
+	commands       *command.Dispatcher
+	mentions       *MentionService
+	filter         *ContentFilterService
+	events         EventDispatcher
 }
 
-func NewMessageService(repo repository.Message, chatRepo repository.Chat) *MessageService {
+func NewMessageService(
+	repo repository.Message,
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	blockRepo repository.UserBlock,
+	attachmentRepo repository.Attachment,
+	pollRepo repository.Poll,
+	previewRepo repository.LinkPreview,
+	draftRepo repository.Draft,
+	previews LinkPreviewQueue,
+	limiter ratelimit.Limiter,
+	rateLimit RateLimitConfig,
+	commands *command.Dispatcher,
+	mentions *MentionService,
+	filter *ContentFilterService,
+	events EventDispatcher,
+) *MessageService {
 	return &MessageService{
-		repo:     repo,
-		chatRepo: chatRepo,
+		repo:           repo,
+		chatRepo:       chatRepo,
+		userRepo:       userRepo,
+		blockRepo:      blockRepo,
+		attachmentRepo: attachmentRepo,
+		pollRepo:       pollRepo,
+		previewRepo:    previewRepo,
+		draftRepo:      draftRepo,
+		previews:       previews,
+		limiter:        limiter,
+		rateLimit:      rateLimit,
+		commands:       commands,
+		mentions:       mentions,
+		filter:         filter,
+		events:         events,
+	}
+}
+
+func (m *MessageService) Create(ctx context.Context, message models.CreateMessageInput) (models.Message, error) {
+	chat, err := m.getPostableChat(ctx, message.ChatID, message.SenderID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	// commands change the chat too, so they are limited like messages, tokens
+	// are given back when nothing is posted
+	tokens, err := m.checkRateLimits(ctx, chat, message.SenderID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	text := message.Text
+	if command.IsCommand(text) {
+		result, err := m.runCommand(ctx, chat, message.SenderID, text)
+		if err != nil {
+			return models.Message{}, errors.Join(err, m.releaseRateLimits(ctx, tokens))
+		}
+		if result.Post == "" {
+			if err := m.releaseRateLimits(ctx, tokens); err != nil {
+				return models.Message{}, err
+			}
+			return models.NewEphemeralMessage(chat.ID, result.Reply, clock.Now()), nil
+		}
+		text = result.Post
+	}
+	text, entities := markdown.Parse(text)
+
+	filtered, err := m.filter.Apply(ctx, chat.ID, message.SenderID, text, entities)
+	if err != nil {
+		return models.Message{}, errors.Join(err, m.releaseRateLimits(ctx, tokens))
+	}
+
+	now := clock.Now()
+	input := models.CreateMessageRecord{
+		ChatID:     message.ChatID,
+		SenderID:   message.SenderID,
+		Text:       filtered.Text,
+		SenderName: message.SenderName,
+		CreatedAt:  now,
+		Entities:   filtered.Entities,
+	}
+	if message.TTL > 0 {
+		expiresAt := now.Add(message.TTL)
+		input.ExpiresAt = &expiresAt
+	}
+	created, err := m.repo.Create(ctx, input)
+	if err != nil {
+		return created, errors.Join(err, m.releaseRateLimits(ctx, tokens))
+	}
+
+	if err := m.filter.Record(ctx, filtered, &created.ID); err != nil {
+		return created, err
+	}
+	if err := m.mentions.Record(ctx, chat, created); err != nil {
+		return created, err
+	}
+	// sent draft is cleared on all user devices
+	if err := m.draftRepo.Delete(ctx, message.SenderID, chat.ID); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return created, err
+	}
+
+	m.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageCreated, created, clock.Now()))
+	m.previews.Enqueue(created)
+
+	return created, nil
+}
+
+// Forward copies the message with its attachments to the target chats, forwarded
+// copies keep the original chat, sender and send time.
+func (m *MessageService) Forward(ctx context.Context, userID int64, messageID int64, chatIDs []int64) ([]models.Message, error) {
+	message, err := m.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return nil, handleNotFoundError(err, models.ErrMessageNotFound)
+	}
+	if message.IsDeleted() || message.IsExpired(clock.Now()) {
+		return nil, models.ErrMessageNotFound
+	}
+	if message.Kind == models.MessageKindPoll {
+		return nil, models.ErrCannotForwardPoll
+	}
+
+	source, err := m.getPostableChat(ctx, message.ChatID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if source.Type == models.ChatTypePrivate && source.ForwardingDisabled {
+		return nil, models.ErrForwardDisabled
+	}
+
+	// each chat has its own filter rules
+	targets := make([]models.Chat, 0, len(chatIDs))
+	filtered := make([]models.FilterResult, 0, len(chatIDs))
+	for _, chatID := range chatIDs {
+		target, err := m.getPostableChat(ctx, chatID, userID)
+		if err != nil {
+			return nil, err
+		}
+		result, err := m.filter.Apply(ctx, target.ID, userID, message.Text, message.Entities)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+		filtered = append(filtered, result)
+	}
+
+	// limits are taken after all targets are checked and given back for copies
+	// which are not created
+	tokens := make([][]rateLimitToken, 0, len(targets))
+	for _, target := range targets {
+		taken, err := m.checkRateLimits(ctx, target, userID)
+		if err != nil {
+			return nil, errors.Join(err, m.releaseRateLimits(ctx, tokens...))
+		}
+		tokens = append(tokens, taken)
+	}
+
+	attachments, err := m.attachmentRepo.GetByMessageIDs(ctx, []int64{message.ID})
+	if err != nil {
+		return nil, err
+	}
+	copies := make([]models.CreateAttachmentRecord, 0, len(attachments))
+	for _, attachment := range attachments {
+		copies = append(copies, models.CreateAttachmentRecord{
+			FileID:    attachment.FileID,
+			Filename:  attachment.Filename,
+			URL:       attachment.URL,
+			Size:      attachment.Size,
+			CreatedAt: clock.Now(),
+		})
+	}
+
+	origin := message.ForwardOrigin()
+	forwarded := make([]models.Message, 0, len(chatIDs))
+	for i, result := range filtered {
+		created, err := m.repo.Create(ctx, models.CreateMessageRecord{
+			ChatID:    result.ChatID,
+			SenderID:  userID,
+			Text:      result.Text,
+			CreatedAt: clock.Now(),
+			Forward:   &origin,
+			Entities:  result.Entities,
+		})
+		if err != nil {
+			return nil, errors.Join(err, m.releaseRateLimits(ctx, tokens[i:]...))
+		}
+		if err := m.attachmentRepo.Create(ctx, created.ID, copies); err != nil {
+			return nil, err
+		}
+		if err := m.filter.Record(ctx, result, &created.ID); err != nil {
+			return nil, err
+		}
+
+		m.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageCreated, created, clock.Now()))
+		m.previews.Enqueue(created)
+		forwarded = append(forwarded, created)
 	}
+
+	if err := m.loadAttachments(ctx, forwarded); err != nil {
+		return nil, err
+	}
+
+	return forwarded, nil
 }
 
-func (m *MessageService) Create(ctx context.Context, message models.CreateMessageInput) error {
-	chat, err := m.chatRepo.GetByID(ctx, message.ChatID)
+func (m *MessageService) getPostableChat(ctx context.Context, chatID int64, userID int64) (models.Chat, error) {
+	return getPostableChat(ctx, m.chatRepo, chatID, userID)
+}
+
+// getPostableChat returns the chat if the user can post to it: anyone can post
+// to public chats, only members can post to private ones.
+func getPostableChat(ctx context.Context, chatRepo repository.Chat, chatID int64, userID int64) (models.Chat, error) {
+	chat, err := chatRepo.GetByID(ctx, chatID)
 	if err != nil {
 		if errors.Is(err, apperror.ErrNotFound) {
-			return models.ErrChatNotFound
+			return models.Chat{}, models.ErrChatNotFound
 		}
-		return err
+		return models.Chat{}, err
 	}
 
 	if chat.Type == models.ChatTypePrivate {
-		isJoined, err := m.chatRepo.IsUserInChat(ctx, chat.ID, message.SenderID)
+		isJoined, err := chatRepo.IsUserInChat(ctx, chat.ID, userID)
 		if err != nil {
-			return err
+			return models.Chat{}, err
 		}
 		if !isJoined {
-			return models.ErrChatNotJoined
+			return models.Chat{}, models.ErrChatNotJoined
 		}
 	}
 
-	input := models.CreateMessageRecord{
-		ChatID:    message.ChatID,
-		SenderID:  message.SenderID,
-		Text:      message.Text,
-		CreatedAt: clock.Now(),
+	return chat, nil
+}
+
+// rateLimitToken is an event recorded by the limiter for the message being sent.
+type rateLimitToken struct {
+	key   string
+	limit ratelimit.Limit
+}
+
+// checkRateLimits returns models.RateLimitError if the user sends messages too
+// often: more than allowed to all chats or more often than chat slow mode allows.
+// Taken tokens must be released if the message is not created.
+func (m *MessageService) checkRateLimits(ctx context.Context, chat models.Chat, userID int64) (tokens []rateLimitToken, err error) {
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, m.releaseRateLimits(ctx, tokens))
+			tokens = nil
+		}
+	}()
+
+	now := clock.Now()
+
+	if m.rateLimit.Messages > 0 {
+		token := rateLimitToken{
+			key:   fmt.Sprintf("messages:%d", userID),
+			limit: ratelimit.Limit{Count: m.rateLimit.Messages, Period: m.rateLimit.Period},
+		}
+		wait, err := m.limiter.Allow(ctx, token.key, token.limit, now)
+		if err != nil {
+			return tokens, err
+		}
+		if wait > 0 {
+			return tokens, models.RateLimitError{RetryAfter: wait}
+		}
+		tokens = append(tokens, token)
+	}
+
+	if chat.SlowModeSeconds == 0 {
+		return tokens, nil
+	}
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return tokens, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	var member *models.ChatMember
+	chatMember, err := m.chatRepo.GetMember(ctx, chat.ID, userID)
+	switch {
+	case err == nil:
+		member = &chatMember
+	case !errors.Is(err, apperror.ErrNotFound):
+		return tokens, err
+	}
+	if models.CanModerateChat(user, chat, member) {
+		return tokens, nil
 	}
-	return m.repo.Create(ctx, input)
+
+	token := rateLimitToken{
+		key:   fmt.Sprintf("slowmode:%d:%d", chat.ID, userID),
+		limit: ratelimit.Every(time.Duration(chat.SlowModeSeconds) * time.Second),
+	}
+	wait, err := m.limiter.Allow(ctx, token.key, token.limit, now)
+	if err != nil {
+		return tokens, err
+	}
+	if wait > 0 {
+		return tokens, models.RateLimitError{RetryAfter: wait, SlowMode: true}
+	}
+
+	return append(tokens, token), nil
+}
+
+// releaseRateLimits gives back tokens of messages which are not created.
+func (m *MessageService) releaseRateLimits(ctx context.Context, tokens ...[]rateLimitToken) error {
+	var errs []error
+	for _, taken := range tokens {
+		for _, token := range taken {
+			if err := m.limiter.Release(ctx, token.key, token.limit); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *MessageService) runCommand(ctx context.Context, chat models.Chat, callerID int64, text string) (command.Result, error) {
+	caller, err := m.userRepo.GetByID(ctx, callerID)
+	if err != nil {
+		return command.Result{}, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+
+	return m.commands.Dispatch(ctx, caller, chat, text)
 }
+
 func (m *MessageService) GetAll(ctx context.Context, pagination models.Pagination, filters models.GetMessagesFilters, userID int64) ([]models.Message, uint64, error) {
 	chat, err := m.chatRepo.GetByID(ctx, filters.ChatID)
 	if err != nil {
@@ -67,10 +378,133 @@ func (m *MessageService) GetAll(ctx context.Context, pagination models.Paginatio
 		}
 	}
 
-	return m.repo.GetAll(ctx, models.DBPagination{
+	blockedIDs, err := m.blockRepo.GetBlockedIDs(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	filters.ExcludeSenderIDs = nil
+	if filters.HideBlocked {
+		filters.ExcludeSenderIDs = blockedIDs
+	}
+	// expired messages are hidden even if they are not swept yet
+	now := clock.Now()
+	filters.VisibleAt = now
+	filters.CreatedAfter = chat.RetentionCutoff(now)
+
+	messages, count, err := m.repo.GetAll(ctx, models.DBPagination{
 		Offset: pagination.Offset(),
 		Limit:  pagination.Limit(),
 	}, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i := range messages {
+		if messages[i].IsDeleted() {
+			messages[i] = messages[i].Tombstone()
+		} else if filters.HTML {
+			messages[i].HTML = markdown.HTML(messages[i].Text, messages[i].Entities)
+		}
+		messages[i].SenderBlocked = slices.Contains(blockedIDs, messages[i].SenderID)
+	}
+
+	if err := m.loadAttachments(ctx, messages); err != nil {
+		return nil, 0, err
+	}
+	if err := m.loadPolls(ctx, messages, userID); err != nil {
+		return nil, 0, err
+	}
+	if err := m.loadLinkPreviews(ctx, messages); err != nil {
+		return nil, 0, err
+	}
+
+	return messages, count, nil
+}
+
+// loadPolls sets polls with results for the viewer to not deleted poll messages.
+func (m *MessageService) loadPolls(ctx context.Context, messages []models.Message, viewerID int64) error {
+	ids := make([]int64, 0)
+	for _, message := range messages {
+		if message.Kind == models.MessageKindPoll && !message.IsDeleted() {
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	polls, err := m.pollRepo.GetByMessageIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	if err := setPollResults(ctx, m.pollRepo, polls, viewerID); err != nil {
+		return err
+	}
+
+	byMessage := make(map[int64]*models.Poll, len(polls))
+	for i := range polls {
+		byMessage[polls[i].MessageID] = &polls[i]
+	}
+	for i := range messages {
+		messages[i].Poll = byMessage[messages[i].ID]
+	}
+
+	return nil
+}
+
+// loadLinkPreviews sets fetched previews of messages links.
+func (m *MessageService) loadLinkPreviews(ctx context.Context, messages []models.Message) error {
+	urls := make([]string, 0)
+	for _, message := range messages {
+		if message.LinkPreviewURL != nil {
+			urls = append(urls, *message.LinkPreviewURL)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	previews, err := m.previewRepo.GetByURLs(ctx, urls)
+	if err != nil {
+		return err
+	}
+
+	byURL := make(map[string]*models.LinkPreview, len(previews))
+	for i := range previews {
+		byURL[previews[i].URL] = &previews[i]
+	}
+	for i := range messages {
+		if messages[i].LinkPreviewURL != nil {
+			messages[i].LinkPreview = byURL[*messages[i].LinkPreviewURL]
+		}
+	}
+
+	return nil
+}
+
+// loadAttachments sets attachments of not deleted messages.
+func (m *MessageService) loadAttachments(ctx context.Context, messages []models.Message) error {
+	ids := make([]int64, 0, len(messages))
+	for _, message := range messages {
+		if !message.IsDeleted() {
+			ids = append(ids, message.ID)
+		}
+	}
+
+	attachments, err := m.attachmentRepo.GetByMessageIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	byMessage := make(map[int64][]models.Attachment, len(ids))
+	for _, attachment := range attachments {
+		byMessage[attachment.MessageID] = append(byMessage[attachment.MessageID], attachment)
+	}
+	for i := range messages {
+		messages[i].Attachments = byMessage[messages[i].ID]
+	}
+
+	return nil
 }
 func (m *MessageService) Delete(ctx context.Context, userID int64, messageID int64) error {
 	message, err := m.repo.GetByID(ctx, messageID)
@@ -80,9 +514,129 @@ func (m *MessageService) Delete(ctx context.Context, userID int64, messageID int
 		}
 		return err
 	}
+	if message.IsDeleted() {
+		return models.ErrMessageNotFound
+	}
 	if message.SenderID != userID {
 		return models.ErrNotYourMessage
 	}
 
-	return m.repo.Delete(ctx, messageID)
+	return m.remove(ctx, message, userID)
+}
+
+// remove soft-deletes the message on behalf of deletedBy user.
+func (m *MessageService) remove(ctx context.Context, message models.Message, deletedBy int64) error {
+	if err := m.repo.Unpin(ctx, message.ChatID, message.ID); err != nil && !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+	if err := m.repo.Delete(ctx, message.ID, deletedBy, clock.Now()); err != nil {
+		return err
+	}
+
+	m.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageDeleted, message, clock.Now()))
+
+	return nil
+}
+
+// GetDeleted returns deleted messages with their content for moderation.
+func (m *MessageService) GetDeleted(ctx context.Context, pagination models.Pagination, filters models.GetDeletedMessagesFilters) ([]models.Message, models.FullPagination, error) {
+	messages, count, err := m.repo.GetDeleted(ctx, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	}, filters)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	return messages, pagination.GetFull(count), nil
+}
+
+func (m *MessageService) Restore(ctx context.Context, messageID int64) error {
+	if _, err := m.repo.GetByID(ctx, messageID); err != nil {
+		return handleNotFoundError(err, models.ErrMessageNotFound)
+	}
+
+	return handleNotFoundError(m.repo.Restore(ctx, messageID), models.ErrMessageNotDeleted)
+}
+
+// Pin pins chat message, only chat moderators can pin.
+func (m *MessageService) Pin(ctx context.Context, user models.User, chatID int64, messageID int64) error {
+	chat, message, err := m.getModeratedMessage(ctx, user, chatID, messageID)
+	if err != nil {
+		return err
+	}
+
+	count, err := m.repo.CountPinned(ctx, chat.ID)
+	if err != nil {
+		return err
+	}
+	if count >= models.MaxPinnedMessages {
+		return models.ErrTooManyPinnedMessages
+	}
+
+	return m.repo.Pin(ctx, models.CreatePinRecord{
+		ChatID:    chat.ID,
+		MessageID: message.ID,
+		PinnedBy:  user.ID,
+		PinnedAt:  clock.Now(),
+	})
+}
+
+func (m *MessageService) Unpin(ctx context.Context, user models.User, chatID int64, messageID int64) error {
+	chat, message, err := m.getModeratedMessage(ctx, user, chatID, messageID)
+	if err != nil {
+		return err
+	}
+
+	return handleNotFoundError(m.repo.Unpin(ctx, chat.ID, message.ID), models.ErrMessageNotPinned)
+}
+
+func (m *MessageService) GetPinned(ctx context.Context, userID int64, chatID int64) ([]models.Message, error) {
+	chat, err := m.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return nil, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+
+	if chat.Type == models.ChatTypePrivate {
+		isJoined, err := m.chatRepo.IsUserInChat(ctx, chat.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isJoined {
+			return nil, models.ErrChatNotJoined
+		}
+	}
+
+	now := clock.Now()
+	return m.repo.GetPinned(ctx, chat.ID, now, chat.RetentionCutoff(now))
+}
+
+// getModeratedMessage returns chat and its message if user can moderate the chat.
+func (m *MessageService) getModeratedMessage(ctx context.Context, user models.User, chatID int64, messageID int64) (models.Chat, models.Message, error) {
+	chat, err := m.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return models.Chat{}, models.Message{}, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+
+	var member *models.ChatMember
+	chatMember, err := m.chatRepo.GetMember(ctx, chat.ID, user.ID)
+	switch {
+	case err == nil:
+		member = &chatMember
+	case !errors.Is(err, apperror.ErrNotFound):
+		return models.Chat{}, models.Message{}, err
+	}
+	if !models.CanModerateChat(user, chat, member) {
+		return models.Chat{}, models.Message{}, models.ErrChatAccessDenied
+	}
+
+	message, err := m.repo.GetByID(ctx, messageID)
+	if err != nil {
+		return models.Chat{}, models.Message{}, handleNotFoundError(err, models.ErrMessageNotFound)
+	}
+	if message.ChatID != chat.ID || message.IsDeleted() {
+		return models.Chat{}, models.Message{}, models.ErrMessageNotFound
+	}
+
+	return chat, message, nil
 }
@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"time"
+)
+
+type ModerationService struct {
+	repo        repository.Report
+	userRepo    repository.User
+	chatRepo    repository.Chat
+	messageRepo repository.Message
+	messages    *MessageService
+}
+
+func NewModerationService(
+	repo repository.Report,
+	userRepo repository.User,
+	chatRepo repository.Chat,
+	messageRepo repository.Message,
+	messages *MessageService,
+) *ModerationService {
+	return &ModerationService{
+		repo:        repo,
+		userRepo:    userRepo,
+		chatRepo:    chatRepo,
+		messageRepo: messageRepo,
+		messages:    messages,
+	}
+}
+
+func (m *ModerationService) ReportMessage(ctx context.Context, messageID int64, input models.CreateReportInput) (models.Report, error) {
+	message, err := m.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		return models.Report{}, handleNotFoundError(err, models.ErrMessageNotFound)
+	}
+	if message.IsDeleted() {
+		return models.Report{}, models.ErrMessageNotFound
+	}
+	if message.SenderID == input.ReporterID {
+		return models.Report{}, models.ErrCannotReportSelf
+	}
+
+	// messages of private chats can be reported by members only
+	chat, err := m.chatRepo.GetByID(ctx, message.ChatID)
+	if err != nil {
+		return models.Report{}, handleNotFoundError(err, models.ErrMessageNotFound)
+	}
+	if chat.Type == models.ChatTypePrivate {
+		isJoined, err := m.chatRepo.IsUserInChat(ctx, chat.ID, input.ReporterID)
+		if err != nil {
+			return models.Report{}, err
+		}
+		if !isJoined {
+			return models.Report{}, models.ErrMessageNotFound
+		}
+	}
+
+	return m.repo.Create(ctx, models.CreateReportRecord{
+		ReporterID: input.ReporterID,
+		TargetType: models.ReportTargetMessage,
+		MessageID:  &message.ID,
+		UserID:     message.SenderID,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+		CreatedAt:  clock.Now(),
+	})
+}
+
+func (m *ModerationService) ReportUser(ctx context.Context, userID int64, input models.CreateReportInput) (models.Report, error) {
+	if userID == input.ReporterID {
+		return models.Report{}, models.ErrCannotReportSelf
+	}
+	if _, err := m.userRepo.GetByID(ctx, userID); err != nil {
+		return models.Report{}, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+
+	return m.repo.Create(ctx, models.CreateReportRecord{
+		ReporterID: input.ReporterID,
+		TargetType: models.ReportTargetUser,
+		UserID:     userID,
+		Reason:     input.Reason,
+		Comment:    input.Comment,
+		CreatedAt:  clock.Now(),
+	})
+}
+
+func (m *ModerationService) GetReports(ctx context.Context, pagination models.Pagination, filters models.GetReportsFilters) ([]models.Report, models.FullPagination, error) {
+	reports, count, err := m.repo.GetAll(ctx, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	}, filters)
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	return reports, pagination.GetFull(count), nil
+}
+
+// Resolve closes the report applying the action, the action is recorded to the moderation log.
+func (m *ModerationService) Resolve(ctx context.Context, admin models.User, reportID int64, input models.ResolveReportInput) error {
+	report, err := m.repo.GetByID(ctx, reportID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrReportNotFound)
+	}
+	if report.Status != models.ReportStatusOpen {
+		return models.ErrReportResolved
+	}
+
+	status := models.ReportStatusResolved
+	var target models.User
+	switch input.Action {
+	case models.ModerationActionDismiss:
+		status = models.ReportStatusDismissed
+	case models.ModerationActionDeleteMessage:
+		if report.MessageID == nil {
+			return models.ErrInvalidModerationAction
+		}
+	case models.ModerationActionBanUser:
+		target, err = m.getBannableUser(ctx, report.UserID)
+		if err != nil {
+			return err
+		}
+	default:
+		return models.ErrInvalidModerationAction
+	}
+
+	// the report is claimed before the action is applied, so concurrent
+	// resolves by other admins do not apply their actions as well
+	now := clock.Now()
+	err = m.repo.Resolve(ctx, models.ResolveReportRecord{
+		ID:         report.ID,
+		Status:     status,
+		ResolvedBy: admin.ID,
+		ResolvedAt: now,
+	})
+	if err != nil {
+		return handleNotFoundError(err, models.ErrReportResolved)
+	}
+
+	switch input.Action {
+	case models.ModerationActionDeleteMessage:
+		if err := m.deleteMessage(ctx, admin, *report.MessageID); err != nil {
+			return err
+		}
+	case models.ModerationActionBanUser:
+		if err := m.userRepo.SetStatus(ctx, target.ID, models.UserStatusBanned); err != nil {
+			return err
+		}
+	}
+
+	return m.repo.CreateAction(ctx, models.CreateModerationActionRecord{
+		AdminID:         admin.ID,
+		Action:          input.Action,
+		ReportID:        &report.ID,
+		TargetUserID:    &report.UserID,
+		TargetMessageID: report.MessageID,
+		Comment:         input.Comment,
+		CreatedAt:       now,
+	})
+}
+
+// Suspend disables the account until the given time.
+func (m *ModerationService) Suspend(ctx context.Context, admin models.User, userID int64, until time.Time, comment string) error {
+	now := clock.Now()
+	if !until.After(now) {
+		return models.ErrInvalidSuspend
+	}
+
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	switch {
+	case user.Type == models.UserTypeAdmin:
+		return models.ErrCannotBanAdmin
+	case user.Status == models.UserStatusDeleted:
+		return models.ErrUserDeleted
+	}
+
+	if err := m.userRepo.Suspend(ctx, user.ID, until); err != nil {
+		return err
+	}
+
+	return m.repo.CreateAction(ctx, models.CreateModerationActionRecord{
+		AdminID:      admin.ID,
+		Action:       models.ModerationActionSuspendUser,
+		TargetUserID: &user.ID,
+		Comment:      comment,
+		CreatedAt:    now,
+	})
+}
+
+// Unsuspend reactivates suspended or banned account.
+func (m *ModerationService) Unsuspend(ctx context.Context, admin models.User, userID int64, comment string) error {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	if user.Status != models.UserStatusSuspended && user.Status != models.UserStatusBanned {
+		return models.ErrNotSuspended
+	}
+
+	if err := m.userRepo.SetStatus(ctx, user.ID, models.UserStatusActive); err != nil {
+		return err
+	}
+
+	return m.repo.CreateAction(ctx, models.CreateModerationActionRecord{
+		AdminID:      admin.ID,
+		Action:       models.ModerationActionUnsuspendUser,
+		TargetUserID: &user.ID,
+		Comment:      comment,
+		CreatedAt:    clock.Now(),
+	})
+}
+
+func (m *ModerationService) GetActions(ctx context.Context, pagination models.Pagination) ([]models.ModerationActionRecord, models.FullPagination, error) {
+	actions, count, err := m.repo.GetActions(ctx, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	})
+	if err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	return actions, pagination.GetFull(count), nil
+}
+
+func (m *ModerationService) deleteMessage(ctx context.Context, admin models.User, messageID int64) error {
+	message, err := m.messageRepo.GetByID(ctx, messageID)
+	if err != nil {
+		if errors.Is(err, apperror.ErrNotFound) {
+			// message is already purged
+			return nil
+		}
+		return err
+	}
+	if message.IsDeleted() {
+		return nil
+	}
+
+	return m.messages.remove(ctx, message, admin.ID)
+}
+
+func (m *ModerationService) getBannableUser(ctx context.Context, userID int64) (models.User, error) {
+	user, err := m.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return user, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	if user.Type == models.UserTypeAdmin {
+		return user, models.ErrCannotBanAdmin
+	}
+
+	return user, nil
+}
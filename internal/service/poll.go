@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+)
+
+type PollService struct {
+	repo        repository.Poll
+	messageRepo repository.Message
+	chatRepo    repository.Chat
+	messages    *MessageService
+}
+
+func NewPollService(
+	repo repository.Poll,
+	messageRepo repository.Message,
+	chatRepo repository.Chat,
+	messages *MessageService,
+) *PollService {
+	return &PollService{
+		repo:        repo,
+		messageRepo: messageRepo,
+		chatRepo:    chatRepo,
+		messages:    messages,
+	}
+}
+
+// Create posts poll message, the same checks as for ordinary messages apply.
+func (p *PollService) Create(ctx context.Context, input models.CreatePollInput) (models.Message, error) {
+	chat, err := p.messages.getPostableChat(ctx, input.ChatID, input.SenderID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	now := clock.Now()
+	if input.ClosesAt != nil && !input.ClosesAt.After(now) {
+		return models.Message{}, models.ErrInvalidPollCloseTime
+	}
+
+	// question and options are shown to all members, so each of them is
+	// filtered as a message
+	filtered := make([]models.FilterResult, 0, len(input.Options)+1)
+	for _, text := range append([]string{input.Question}, input.Options...) {
+		result, err := p.messages.filter.Apply(ctx, chat.ID, input.SenderID, text, nil)
+		if err != nil {
+			return models.Message{}, err
+		}
+		filtered = append(filtered, result)
+	}
+	question := filtered[0].Text
+	options := make([]string, 0, len(input.Options))
+	for _, result := range filtered[1:] {
+		options = append(options, result.Text)
+	}
+
+	tokens, err := p.messages.checkRateLimits(ctx, chat, input.SenderID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	created, err := p.repo.Create(ctx, models.CreatePollRecord{
+		Message: models.CreateMessageRecord{
+			ChatID:    chat.ID,
+			SenderID:  input.SenderID,
+			Text:      question,
+			CreatedAt: now,
+		},
+		Question:  question,
+		Options:   options,
+		Multiple:  input.Multiple,
+		Anonymous: input.Anonymous,
+		ClosesAt:  input.ClosesAt,
+	})
+	if err != nil {
+		return models.Message{}, errors.Join(err, p.messages.releaseRateLimits(ctx, tokens))
+	}
+
+	for _, result := range filtered {
+		if err := p.messages.filter.Record(ctx, result, &created.ID); err != nil {
+			return models.Message{}, err
+		}
+	}
+
+	messages := []models.Message{created}
+	if err := p.messages.loadPolls(ctx, messages, input.SenderID); err != nil {
+		return models.Message{}, err
+	}
+	created = messages[0]
+
+	p.messages.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageCreated, created, now))
+
+	return created, nil
+}
+
+// Get returns poll with results for the user.
+func (p *PollService) Get(ctx context.Context, userID int64, pollID int64) (models.Poll, error) {
+	poll, _, err := p.getPoll(ctx, userID, pollID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+
+	return p.withResults(ctx, poll, userID)
+}
+
+// Vote replaces votes of the user with the chosen options.
+func (p *PollService) Vote(ctx context.Context, userID int64, pollID int64, optionIDs []int64) (models.Poll, error) {
+	poll, _, err := p.getPoll(ctx, userID, pollID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+	now := clock.Now()
+	if poll.IsClosed(now) {
+		return models.Poll{}, models.ErrPollClosed
+	}
+
+	slices.Sort(optionIDs)
+	optionIDs = slices.Compact(optionIDs)
+	if len(optionIDs) == 0 {
+		return models.Poll{}, models.ErrInvalidPollVote
+	}
+	if !poll.Multiple && len(optionIDs) > 1 {
+		return models.Poll{}, models.ErrPollSingleChoice
+	}
+
+	options, err := p.repo.GetOptions(ctx, []int64{poll.ID})
+	if err != nil {
+		return models.Poll{}, err
+	}
+	for _, optionID := range optionIDs {
+		if !slices.ContainsFunc(options, func(option models.PollOption) bool { return option.ID == optionID }) {
+			return models.Poll{}, models.ErrInvalidPollVote
+		}
+	}
+
+	if err := p.repo.Vote(ctx, poll.ID, userID, optionIDs, now); err != nil {
+		return models.Poll{}, err
+	}
+
+	return p.withResults(ctx, poll, userID)
+}
+
+func (p *PollService) Retract(ctx context.Context, userID int64, pollID int64) (models.Poll, error) {
+	poll, _, err := p.getPoll(ctx, userID, pollID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+	if poll.IsClosed(clock.Now()) {
+		return models.Poll{}, models.ErrPollClosed
+	}
+
+	if err := p.repo.Retract(ctx, poll.ID, userID); err != nil {
+		return models.Poll{}, handleNotFoundError(err, models.ErrPollNotVoted)
+	}
+
+	return p.withResults(ctx, poll, userID)
+}
+
+// Close stops voting, poll author and chat moderators can close it.
+func (p *PollService) Close(ctx context.Context, user models.User, pollID int64) (models.Poll, error) {
+	poll, message, err := p.getPoll(ctx, user.ID, pollID)
+	if err != nil {
+		return models.Poll{}, err
+	}
+
+	if message.SenderID != user.ID {
+		chat, err := p.chatRepo.GetByID(ctx, message.ChatID)
+		if err != nil {
+			return models.Poll{}, handleNotFoundError(err, models.ErrChatNotFound)
+		}
+		var member *models.ChatMember
+		chatMember, err := p.chatRepo.GetMember(ctx, chat.ID, user.ID)
+		switch {
+		case err == nil:
+			member = &chatMember
+		case !errors.Is(err, apperror.ErrNotFound):
+			return models.Poll{}, err
+		}
+		if !models.CanModerateChat(user, chat, member) {
+			return models.Poll{}, models.ErrChatAccessDenied
+		}
+	}
+
+	now := clock.Now()
+	if poll.IsClosed(now) {
+		return models.Poll{}, models.ErrPollClosed
+	}
+	if err := p.repo.Close(ctx, poll.ID, now); err != nil {
+		return models.Poll{}, handleNotFoundError(err, models.ErrPollClosed)
+	}
+	poll.ClosedAt = &now
+
+	return p.withResults(ctx, poll, user.ID)
+}
+
+// getPoll returns poll of not deleted message in the chat the user can post to.
+func (p *PollService) getPoll(ctx context.Context, userID int64, pollID int64) (models.Poll, models.Message, error) {
+	poll, err := p.repo.GetByID(ctx, pollID)
+	if err != nil {
+		return models.Poll{}, models.Message{}, handleNotFoundError(err, models.ErrPollNotFound)
+	}
+
+	message, err := p.messageRepo.GetByID(ctx, poll.MessageID)
+	if err != nil {
+		return models.Poll{}, models.Message{}, handleNotFoundError(err, models.ErrPollNotFound)
+	}
+	if message.IsDeleted() || message.IsExpired(clock.Now()) {
+		return models.Poll{}, models.Message{}, models.ErrPollNotFound
+	}
+
+	if _, err := p.messages.getPostableChat(ctx, message.ChatID, userID); err != nil {
+		return models.Poll{}, models.Message{}, err
+	}
+
+	return poll, message, nil
+}
+
+func (p *PollService) withResults(ctx context.Context, poll models.Poll, viewerID int64) (models.Poll, error) {
+	polls := []models.Poll{poll}
+	if err := setPollResults(ctx, p.repo, polls, viewerID); err != nil {
+		return models.Poll{}, err
+	}
+
+	return polls[0], nil
+}
+
+// setPollResults loads options and votes of the polls and counts results for the viewer.
+func setPollResults(ctx context.Context, repo repository.Poll, polls []models.Poll, viewerID int64) error {
+	ids := make([]int64, 0, len(polls))
+	for _, poll := range polls {
+		ids = append(ids, poll.ID)
+	}
+
+	options, err := repo.GetOptions(ctx, ids)
+	if err != nil {
+		return err
+	}
+	votes, err := repo.GetVotes(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	for i := range polls {
+		pollOptions := make([]models.PollOption, 0)
+		for _, option := range options {
+			if option.PollID == polls[i].ID {
+				pollOptions = append(pollOptions, option)
+			}
+		}
+		pollVotes := make([]models.PollVote, 0)
+		for _, vote := range votes {
+			if vote.PollID == polls[i].ID {
+				pollVotes = append(pollVotes, vote)
+			}
+		}
+		polls[i].SetResults(pollOptions, pollVotes, viewerID, now)
+	}
+
+	return nil
+}
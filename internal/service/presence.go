@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/presence"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"time"
+)
+
+type PresenceService struct {
+	store    presence.Store
+	userRepo repository.User
+	chatRepo repository.Chat
+	events   EventDispatcher
+	config   PresenceConfig
+	logger   logger.Logger
+}
+
+func NewPresenceService(
+	store presence.Store,
+	userRepo repository.User,
+	chatRepo repository.Chat,
+	events EventDispatcher,
+	config PresenceConfig,
+	logger logger.Logger,
+) *PresenceService {
+	return &PresenceService{
+		store:    store,
+		userRepo: userRepo,
+		chatRepo: chatRepo,
+		events:   events,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Touch records user activity, last seen time is persisted by Run.
+func (p *PresenceService) Touch(userID int64) {
+	p.store.Touch(userID, clock.Now())
+}
+
+// Connect marks user online while realtime connection is held,
+// returned function must be called when connection is closed.
+func (p *PresenceService) Connect(userID int64) func() {
+	p.store.Connect(userID)
+	p.Touch(userID)
+
+	return func() {
+		p.Touch(userID)
+		p.store.Disconnect(userID)
+	}
+}
+
+func (p *PresenceService) IsOnline(userID int64) bool {
+	if p.store.IsConnected(userID) {
+		return true
+	}
+
+	lastActive, ok := p.store.LastActive(userID)
+	return ok && clock.Now().Sub(lastActive) < p.config.OnlineTimeout
+}
+
+// Resolve fills presence of the user as seen by the viewer.
+func (p *PresenceService) Resolve(viewerID int64, user models.User) models.User {
+	if user.HidePresence && user.ID != viewerID {
+		user.LastSeenAt = nil
+		user.Online = nil
+		return user
+	}
+
+	if lastActive, ok := p.store.LastActive(user.ID); ok {
+		if user.LastSeenAt == nil || lastActive.After(*user.LastSeenAt) {
+			user.LastSeenAt = &lastActive
+		}
+	}
+	online := p.IsOnline(user.ID)
+	user.Online = &online
+
+	return user
+}
+
+func (p *PresenceService) SetHidden(ctx context.Context, userID int64, hidden bool) error {
+	return p.userRepo.SetHidePresence(ctx, userID, hidden)
+}
+
+// SetTyping signals other chat members that user is typing, anyone who can
+// post to the chat can type in it. Waiting clients are notified at once.
+func (p *PresenceService) SetTyping(ctx context.Context, userID int64, chatID int64) error {
+	chat, err := getPostableChat(ctx, p.chatRepo, chatID, userID)
+	if err != nil {
+		return err
+	}
+
+	now := clock.Now()
+	expiresAt := now.Add(p.config.TypingTTL)
+	p.store.Touch(userID, now)
+	p.store.SetTyping(chat.ID, userID, expiresAt)
+	p.events.Dispatch(ctx, models.NewTypingEvent(chat.ID, userID, expiresAt, now))
+
+	return nil
+}
+
+// GetTyping returns other users typing in the chat.
+func (p *PresenceService) GetTyping(ctx context.Context, userID int64, chatID int64) ([]int64, error) {
+	chat, err := getPostableChat(ctx, p.chatRepo, chatID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	typing := p.store.GetTyping(chat.ID, clock.Now())
+
+	return slices.DeleteFunc(typing, func(id int64) bool { return id == userID }), nil
+}
+
+// Run periodically persists last seen time of active users.
+func (p *PresenceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.config.PersistInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// activity since the last tick is saved on shutdown
+			p.persist(context.Background())
+			return
+		case <-ticker.C:
+			p.persist(ctx)
+		}
+	}
+}
+
+func (p *PresenceService) persist(ctx context.Context) {
+	for userID, lastSeenAt := range p.store.Flush() {
+		if err := p.userRepo.UpdateLastSeen(ctx, userID, lastSeenAt); err != nil {
+			p.logger.Errorf("PresenceService.persist: user %d: %s", userID, err)
+		}
+	}
+}
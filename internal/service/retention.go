@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"time"
+)
+
+// RetentionService deletes expired messages and purges soft-deleted messages once
+// retention period is over.
+type RetentionService struct {
+	repo   repository.Message
+	events EventDispatcher
+	config RetentionConfig
+	logger logger.Logger
+}
+
+func NewRetentionService(repo repository.Message, events EventDispatcher, config RetentionConfig, logger logger.Logger) *RetentionService {
+	return &RetentionService{
+		repo:   repo,
+		events: events,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (r *RetentionService) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.WorkerInterval)
+	defer ticker.Stop()
+	expireTicker := time.NewTicker(r.config.ExpireInterval)
+	defer expireTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-expireTicker.C:
+			expired, err := r.deleteExpired(ctx)
+			if err != nil {
+				r.logger.Errorf("RetentionService.Run: %s", err)
+			}
+			if expired > 0 {
+				r.logger.Infof("RetentionService.Run: deleted %d expired messages", expired)
+			}
+		case <-ticker.C:
+			purged, err := r.purgeDeleted(ctx)
+			if err != nil {
+				r.logger.Errorf("RetentionService.Run: %s", err)
+			}
+			if purged > 0 {
+				r.logger.Infof("RetentionService.Run: purged %d deleted messages", purged)
+			}
+		}
+	}
+}
+
+// purgeDeleted removes expired messages in batches, so a single statement
+// does not lock too many rows.
+func (r *RetentionService) purgeDeleted(ctx context.Context) (int64, error) {
+	deletedBefore := clock.Now().Add(-r.config.DeletedMessagesTTL)
+
+	var total int64
+	for {
+		purged, err := r.repo.Purge(ctx, deletedBefore, r.config.BatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+
+		if purged == 0 || uint64(purged) < r.config.BatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// deleteExpired soft-deletes messages past their TTL or chat retention period in
+// batches and notifies chat subscribers about every deleted message.
+func (r *RetentionService) deleteExpired(ctx context.Context) (int, error) {
+	var total int
+	for {
+		now := clock.Now()
+		messages, err := r.repo.GetExpired(ctx, now, r.config.BatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		ids := make([]int64, 0, len(messages))
+		for _, message := range messages {
+			ids = append(ids, message.ID)
+		}
+		if err := r.repo.Expire(ctx, ids, now); err != nil {
+			return total, err
+		}
+		for _, message := range messages {
+			r.events.Dispatch(ctx, models.NewMessageEvent(models.ChatEventMessageDeleted, message, now))
+		}
+		total += len(messages)
+
+		if len(messages) == 0 || uint64(len(messages)) < r.config.BatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/command"
+	"spsu-chat/pkg/clock"
+	"time"
+)
+
+// MessageSender posts messages on behalf of users.
+type MessageSender interface {
+	Create(ctx context.Context, message models.CreateMessageInput) (models.Message, error)
+}
+
+// ScheduledMessageService stores messages to be sent later and delivers them
+// once their time comes.
+type ScheduledMessageService struct {
+	repo     repository.ScheduledMessage
+	chatRepo repository.Chat
+	userRepo repository.User
+	sender   MessageSender
+	clock    clock.ClockI
+	config   SchedulerConfig
+	logger   logger.Logger
+}
+
+func NewScheduledMessageService(
+	repo repository.ScheduledMessage,
+	chatRepo repository.Chat,
+	userRepo repository.User,
+	sender MessageSender,
+	clock clock.ClockI,
+	config SchedulerConfig,
+	logger logger.Logger,
+) *ScheduledMessageService {
+	return &ScheduledMessageService{
+		repo:     repo,
+		chatRepo: chatRepo,
+		userRepo: userRepo,
+		sender:   sender,
+		clock:    clock,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+func (s *ScheduledMessageService) Create(ctx context.Context, input models.CreateScheduledMessageInput) (models.ScheduledMessage, error) {
+	now := s.clock.Now()
+	if err := s.validate(ctx, input.ChatID, input.SenderID, input.Text, input.SendAt, now); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+
+	return s.repo.Create(ctx, models.CreateScheduledMessageRecord{
+		ChatID:    input.ChatID,
+		SenderID:  input.SenderID,
+		Text:      input.Text,
+		SendAt:    input.SendAt,
+		CreatedAt: now,
+	})
+}
+
+func (s *ScheduledMessageService) GetAll(ctx context.Context, userID int64, filters models.GetScheduledMessagesFilters) ([]models.ScheduledMessage, error) {
+	return s.repo.GetPendingByUser(ctx, userID, filters)
+}
+
+func (s *ScheduledMessageService) Update(ctx context.Context, userID int64, id int64, input models.UpdateScheduledMessageInput) (models.ScheduledMessage, error) {
+	message, err := s.getPending(ctx, userID, id)
+	if err != nil {
+		return models.ScheduledMessage{}, err
+	}
+
+	now := s.clock.Now()
+	if err := s.validate(ctx, message.ChatID, userID, input.Text, input.SendAt, now); err != nil {
+		return models.ScheduledMessage{}, err
+	}
+
+	err = s.repo.Update(ctx, models.UpdateScheduledMessageRecord{
+		ID:        message.ID,
+		Text:      input.Text,
+		SendAt:    input.SendAt,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		// message was sent or canceled in the meantime
+		return models.ScheduledMessage{}, handleNotFoundError(err, models.ErrScheduledMessageNotPending)
+	}
+
+	return s.repo.GetByID(ctx, message.ID)
+}
+
+func (s *ScheduledMessageService) Cancel(ctx context.Context, userID int64, id int64) error {
+	message, err := s.getPending(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.SetStatus(ctx, models.SetScheduledMessageStatusRecord{
+		ID:        message.ID,
+		Status:    models.ScheduledMessageCanceled,
+		UpdatedAt: s.clock.Now(),
+	})
+
+	return handleNotFoundError(err, models.ErrScheduledMessageNotPending)
+}
+
+func (s *ScheduledMessageService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.deliverDue(ctx); err != nil {
+				s.logger.Errorf("ScheduledMessageService.Run: %s", err)
+			}
+		}
+	}
+}
+
+// deliverDue sends all messages which are due by now and returns how many of them were handled.
+func (s *ScheduledMessageService) deliverDue(ctx context.Context) (int, error) {
+	var total int
+	for {
+		messages, err := s.repo.ClaimDue(ctx, s.clock.Now(), s.config.BatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		handled := 0
+		for _, message := range messages {
+			ok, err := s.deliver(ctx, message)
+			if err != nil {
+				s.logger.Errorf("ScheduledMessageService.deliver: message %d: %s", message.ID, err)
+			}
+			if ok {
+				handled++
+			}
+		}
+		total += handled
+
+		// messages failed with temporary errors stay pending until the next tick
+		if handled == 0 || uint64(len(messages)) < s.config.BatchSize || ctx.Err() != nil {
+			return total, nil
+		}
+	}
+}
+
+// deliver sends the claimed message if the sender still can post to the chat,
+// otherwise the message is marked as failed. It returns false if the message
+// is given back to pending.
+func (s *ScheduledMessageService) deliver(ctx context.Context, message models.ScheduledMessage) (bool, error) {
+	sent, err := s.send(ctx, message)
+	// temporary failures and rate limited messages are sent on one of the next
+	// ticks, created message must not be sent twice even if later steps failed
+	isDBError := errors.As(err, &apperror.DBError{})
+	if sent.ID == 0 && (isDBError || errors.Is(err, models.ErrRateLimited)) {
+		release := s.repo.SetStatus(ctx, models.SetScheduledMessageStatusRecord{
+			ID:        message.ID,
+			Status:    models.ScheduledMessagePending,
+			UpdatedAt: s.clock.Now(),
+		})
+		if !isDBError {
+			return false, release
+		}
+		return false, errors.Join(err, release)
+	}
+
+	status := models.SetScheduledMessageStatusRecord{
+		ID:        message.ID,
+		Status:    models.ScheduledMessageSent,
+		MessageID: &sent.ID,
+		UpdatedAt: s.clock.Now(),
+	}
+	if err != nil && sent.ID == 0 {
+		reason := err.Error()
+		status.Status = models.ScheduledMessageFailed
+		status.MessageID = nil
+		status.Error = &reason
+	}
+
+	statusErr := s.repo.SetStatus(ctx, status)
+	if errors.Is(statusErr, apperror.ErrNotFound) {
+		statusErr = nil
+	}
+	// message can be posted while some of the following steps failed
+	if sent.ID != 0 {
+		return true, errors.Join(err, statusErr)
+	}
+
+	return true, statusErr
+}
+
+// send re-checks that the sender is active and still a member of the chat.
+func (s *ScheduledMessageService) send(ctx context.Context, message models.ScheduledMessage) (models.Message, error) {
+	user, err := s.userRepo.GetByID(ctx, message.SenderID)
+	if err != nil {
+		return models.Message{}, handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	if err := user.CheckStatus(s.clock.Now()); err != nil {
+		return models.Message{}, err
+	}
+
+	isJoined, err := s.chatRepo.IsUserInChat(ctx, message.ChatID, message.SenderID)
+	if err != nil {
+		return models.Message{}, err
+	}
+	if !isJoined {
+		return models.Message{}, models.ErrChatNotJoined
+	}
+
+	return s.sender.Create(ctx, models.NewCreateMessageInput(message.ChatID, message.SenderID, message.Text))
+}
+
+func (s *ScheduledMessageService) getPending(ctx context.Context, userID int64, id int64) (models.ScheduledMessage, error) {
+	message, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return models.ScheduledMessage{}, handleNotFoundError(err, models.ErrScheduledMessageNotFound)
+	}
+	if message.SenderID != userID {
+		return models.ScheduledMessage{}, models.ErrScheduledMessageNotFound
+	}
+	if message.Status != models.ScheduledMessagePending {
+		return models.ScheduledMessage{}, models.ErrScheduledMessageNotPending
+	}
+
+	return message, nil
+}
+
+func (s *ScheduledMessageService) validate(ctx context.Context, chatID, userID int64, text string, sendAt time.Time, now time.Time) error {
+	if err := models.ValidateSendAt(sendAt, now); err != nil {
+		return err
+	}
+	// command replies are shown to the caller only, there is nobody to show them to later
+	if command.IsCommand(text) {
+		return models.ErrCannotScheduleCommand
+	}
+
+	if _, err := s.chatRepo.GetByID(ctx, chatID); err != nil {
+		return handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	isJoined, err := s.chatRepo.IsUserInChat(ctx, chatID, userID)
+	if err != nil {
+		return err
+	}
+	if !isJoined {
+		return models.ErrChatNotJoined
+	}
+
+	return nil
+}
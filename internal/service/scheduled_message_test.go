@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"spsu-chat/internal/apperror"
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeScheduledMessageRepo struct {
+	repository.ScheduledMessage
+	messages map[int64]*models.ScheduledMessage
+}
+
+func (f *fakeScheduledMessageRepo) ClaimDue(ctx context.Context, now time.Time, limit uint64) ([]models.ScheduledMessage, error) {
+	due := make([]models.ScheduledMessage, 0)
+	for _, message := range f.messages {
+		if message.Status == models.ScheduledMessagePending && !message.SendAt.After(now) && uint64(len(due)) < limit {
+			message.Status = models.ScheduledMessageSending
+			due = append(due, *message)
+		}
+	}
+
+	return due, nil
+}
+
+func (f *fakeScheduledMessageRepo) SetStatus(ctx context.Context, record models.SetScheduledMessageStatusRecord) error {
+	message, ok := f.messages[record.ID]
+	if !ok || message.Status != models.ScheduledMessageSending {
+		return apperror.ErrNotFound
+	}
+	message.Status = record.Status
+	message.MessageID = record.MessageID
+	message.Error = record.Error
+
+	return nil
+}
+
+type fakeChatRepo struct {
+	repository.Chat
+	members map[int64]bool
+}
+
+func (f *fakeChatRepo) IsUserInChat(ctx context.Context, chatID, userID int64) (bool, error) {
+	return f.members[userID], nil
+}
+
+type fakeUserRepo struct {
+	repository.User
+}
+
+func (f *fakeUserRepo) GetByID(ctx context.Context, id int64) (models.User, error) {
+	return models.User{ID: id}, nil
+}
+
+type fakeMessageSender struct {
+	sent []models.CreateMessageInput
+}
+
+func (f *fakeMessageSender) Create(ctx context.Context, message models.CreateMessageInput) (models.Message, error) {
+	f.sent = append(f.sent, message)
+
+	return models.Message{ID: int64(len(f.sent)), ChatID: message.ChatID, Text: message.Text}, nil
+}
+
+func TestScheduledMessageDelivery(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	manualClock := clock.NewManualClock(now)
+
+	repo := &fakeScheduledMessageRepo{messages: map[int64]*models.ScheduledMessage{
+		1: {ID: 1, ChatID: 1, SenderID: 1, Text: "reminder", SendAt: now.Add(time.Hour)},
+		2: {ID: 2, ChatID: 1, SenderID: 2, Text: "left the chat", SendAt: now.Add(time.Hour)},
+		3: {ID: 3, ChatID: 1, SenderID: 1, Text: "tomorrow", SendAt: now.Add(24 * time.Hour)},
+	}}
+	chatRepo := &fakeChatRepo{members: map[int64]bool{1: true, 2: true}}
+	sender := &fakeMessageSender{}
+
+	scheduler := NewScheduledMessageService(
+		repo,
+		chatRepo,
+		&fakeUserRepo{},
+		sender,
+		manualClock,
+		SchedulerConfig{BatchSize: 10},
+		logger.NewLogrusLogger("error", false),
+	)
+
+	handled, err := scheduler.deliverDue(ctx)
+	require.NoError(t, err)
+	require.Zero(t, handled)
+	require.Empty(t, sender.sent)
+
+	// membership is checked at send time, not when message was scheduled
+	chatRepo.members[2] = false
+	manualClock.Advance(time.Hour)
+
+	handled, err = scheduler.deliverDue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, handled)
+	require.Len(t, sender.sent, 1)
+	require.Equal(t, "reminder", sender.sent[0].Text)
+
+	require.Equal(t, models.ScheduledMessageSent, repo.messages[1].Status)
+	require.NotNil(t, repo.messages[1].MessageID)
+	require.Equal(t, models.ScheduledMessageFailed, repo.messages[2].Status)
+	require.NotNil(t, repo.messages[2].Error)
+	require.Equal(t, models.ScheduledMessagePending, repo.messages[3].Status)
+
+	// sent messages are not delivered twice
+	handled, err = scheduler.deliverDue(ctx)
+	require.NoError(t, err)
+	require.Zero(t, handled)
+	require.Len(t, sender.sent, 1)
+
+	manualClock.Advance(23 * time.Hour)
+	handled, err = scheduler.deliverDue(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, handled)
+	require.Len(t, sender.sent, 2)
+}
@@ -2,11 +2,19 @@ package service
 
 import (
 	"context"
+	"io"
 	"spsu-chat/internal/filestorage"
 	"spsu-chat/internal/jwt"
+	"spsu-chat/internal/logger"
 	"spsu-chat/internal/models"
+	"spsu-chat/internal/presence"
+	"spsu-chat/internal/ratelimit"
 	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/command"
 	"spsu-chat/internal/service/uploader"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/linkpreview"
+	"time"
 )
 
 type Authorization interface {
@@ -19,27 +27,168 @@ type User interface {
 	GetByID(ctx context.Context, id int64) (models.User, error)
 	GetByUsername(ctx context.Context, username string) (models.User, error)
 	GetAll(ctx context.Context, pagination models.Pagination) ([]models.User, models.FullPagination, error)
+	Search(ctx context.Context, input models.SearchUsersInput) ([]models.PublicUser, error)
+	Block(ctx context.Context, blockerID int64, blockedID int64) error
+	Unblock(ctx context.Context, blockerID int64, blockedID int64) error
+	GetBlocked(ctx context.Context, blockerID int64) ([]models.User, error)
+	Delete(ctx context.Context, userID int64, password string) error
 }
 
 type Chat interface {
-	GetAll(ctx context.Context, pagination models.Pagination) ([]models.Chat, models.FullPagination, error)
+	GetAll(ctx context.Context, userID int64, pagination models.Pagination, filters models.GetChatsFilters) ([]models.Chat, models.FullPagination, error)
 	GetByID(ctx context.Context, id int64) (models.Chat, error)
 	Create(ctx context.Context, input models.CreateChatInput) error
 	JoinUser(ctx context.Context, chatID int64, userID int64, password string) error
 	LeaveUser(ctx context.Context, chatID int64, userID int64) error
+	SetMemberRole(ctx context.Context, user models.User, chatID int64, memberID int64, role models.ChatRole) error
+	UpdateSettings(ctx context.Context, user models.User, chatID int64, input models.UpdateChatSettingsInput) (models.Chat, error)
+}
+
+type Category interface {
+	GetAll(ctx context.Context) ([]models.Category, error)
+	Create(ctx context.Context, input models.CreateCategoryInput) (models.Category, error)
+	Delete(ctx context.Context, id int64) error
+	SetChatCategories(ctx context.Context, chatID int64, categoryIDs []int64) ([]models.Category, error)
+}
+
+type Draft interface {
+	Get(ctx context.Context, userID, chatID int64) (models.Draft, error)
+	Save(ctx context.Context, input models.SaveDraftInput) (models.Draft, error)
+	Delete(ctx context.Context, userID, chatID int64) error
 }
 
 type Message interface {
-	Create(ctx context.Context, message models.CreateMessageInput) error
+	Create(ctx context.Context, message models.CreateMessageInput) (models.Message, error)
 	GetAll(ctx context.Context, pagination models.Pagination, filters models.GetMessagesFilters, userID int64) ([]models.Message, uint64, error)
 	Delete(ctx context.Context, userID int64, messageID int64) error
+	GetDeleted(ctx context.Context, pagination models.Pagination, filters models.GetDeletedMessagesFilters) ([]models.Message, models.FullPagination, error)
+	Restore(ctx context.Context, messageID int64) error
+	Pin(ctx context.Context, user models.User, chatID int64, messageID int64) error
+	Unpin(ctx context.Context, user models.User, chatID int64, messageID int64) error
+	GetPinned(ctx context.Context, userID int64, chatID int64) ([]models.Message, error)
+	Forward(ctx context.Context, userID int64, messageID int64, chatIDs []int64) ([]models.Message, error)
+}
+
+type Bot interface {
+	Create(ctx context.Context, input models.CreateBotInput) (models.BotCredentials, error)
+	RotateToken(ctx context.Context, botID int64) (models.BotCredentials, error)
+	RevokeTokens(ctx context.Context, botID int64) error
+	Authenticate(ctx context.Context, botToken string) (models.User, error)
+	GetUpdates(ctx context.Context, botID int64, input models.GetBotUpdatesInput) (models.BotUpdates, error)
+	SetCommands(ctx context.Context, botID int64, commands []models.BotCommand) error
+	GetCommands(ctx context.Context, botID int64) ([]models.BotCommand, error)
+}
+
+type Webhook interface {
+	Create(ctx context.Context, user models.User, input models.CreateWebhookInput) (models.WebhookCredentials, error)
+	GetAll(ctx context.Context, user models.User, chatID int64) ([]models.Webhook, error)
+	Delete(ctx context.Context, user models.User, chatID int64, webhookID int64) error
+	GetDeliveries(ctx context.Context, user models.User, chatID int64, webhookID int64, pagination models.Pagination) ([]models.WebhookDelivery, models.FullPagination, error)
+	Run(ctx context.Context)
+}
+
+type IncomingWebhook interface {
+	Create(ctx context.Context, user models.User, input models.CreateIncomingWebhookInput) (models.IncomingWebhookCredentials, error)
+	GetAll(ctx context.Context, user models.User, chatID int64) ([]models.IncomingWebhook, error)
+	Revoke(ctx context.Context, user models.User, chatID int64, webhookID int64) error
+	Post(ctx context.Context, webhookToken string, input models.IncomingWebhookMessageInput) (models.Message, error)
+}
+
+type Mention interface {
+	GetAll(ctx context.Context, userID int64, pagination models.Pagination, filters models.GetMentionsFilters) ([]models.Mention, models.FullPagination, error)
+	CountUnread(ctx context.Context, userID int64) (uint64, error)
+	MarkRead(ctx context.Context, userID int64, ids []int64) error
+}
+
+type LinkPreview interface {
+	Enqueue(message models.Message)
+	Run(ctx context.Context)
+}
+
+type Retention interface {
+	Run(ctx context.Context)
+}
+
+type Presence interface {
+	Touch(userID int64)
+	Connect(userID int64) func()
+	Resolve(viewerID int64, user models.User) models.User
+	SetHidden(ctx context.Context, userID int64, hidden bool) error
+	SetTyping(ctx context.Context, userID int64, chatID int64) error
+	GetTyping(ctx context.Context, userID int64, chatID int64) ([]int64, error)
+	Run(ctx context.Context)
+}
+
+type Moderation interface {
+	ReportMessage(ctx context.Context, messageID int64, input models.CreateReportInput) (models.Report, error)
+	ReportUser(ctx context.Context, userID int64, input models.CreateReportInput) (models.Report, error)
+	GetReports(ctx context.Context, pagination models.Pagination, filters models.GetReportsFilters) ([]models.Report, models.FullPagination, error)
+	Resolve(ctx context.Context, admin models.User, reportID int64, input models.ResolveReportInput) error
+	GetActions(ctx context.Context, pagination models.Pagination) ([]models.ModerationActionRecord, models.FullPagination, error)
+	Suspend(ctx context.Context, admin models.User, userID int64, until time.Time, comment string) error
+	Unsuspend(ctx context.Context, admin models.User, userID int64, comment string) error
+}
+
+type ContentFilter interface {
+	GetRules(ctx context.Context, user models.User, chatID *int64) ([]models.FilterRule, error)
+	CreateRule(ctx context.Context, user models.User, input models.CreateFilterRuleInput) (models.FilterRule, error)
+	DeleteRule(ctx context.Context, user models.User, chatID *int64, ruleID int64) error
+	GetHits(ctx context.Context, pagination models.Pagination, filters models.GetFilterHitsFilters) ([]models.FilterHit, models.FullPagination, error)
+}
+
+type Export interface {
+	Request(ctx context.Context, userID int64) (models.DataExport, error)
+	GetAll(ctx context.Context, userID int64) ([]models.DataExport, error)
+	Download(ctx context.Context, userID int64, exportID int64) ([]byte, error)
+	Run(ctx context.Context)
+}
+
+type ChatExport interface {
+	GetChat(ctx context.Context, userID int64, chatID int64) (models.Chat, error)
+	Write(ctx context.Context, chat models.Chat, format models.ChatExportFormat, w io.Writer) error
+}
+
+type ChatImport interface {
+	Import(ctx context.Context, input models.ImportChatInput) (models.ImportChatResult, error)
+}
+
+type ScheduledMessage interface {
+	Create(ctx context.Context, input models.CreateScheduledMessageInput) (models.ScheduledMessage, error)
+	GetAll(ctx context.Context, userID int64, filters models.GetScheduledMessagesFilters) ([]models.ScheduledMessage, error)
+	Update(ctx context.Context, userID int64, id int64, input models.UpdateScheduledMessageInput) (models.ScheduledMessage, error)
+	Cancel(ctx context.Context, userID int64, id int64) error
+	Run(ctx context.Context)
+}
+
+type Poll interface {
+	Create(ctx context.Context, input models.CreatePollInput) (models.Message, error)
+	Get(ctx context.Context, userID int64, pollID int64) (models.Poll, error)
+	Vote(ctx context.Context, userID int64, pollID int64, optionIDs []int64) (models.Poll, error)
+	Retract(ctx context.Context, userID int64, pollID int64) (models.Poll, error)
+	Close(ctx context.Context, user models.User, pollID int64) (models.Poll, error)
 }
 
 type Services struct {
 	User
 	Authorization
 	Chat
+	Category
+	Draft
 	Message
+	Bot
+	Webhook
+	IncomingWebhook
+	Mention
+	Retention
+	Presence
+	Moderation
+	ContentFilter
+	Export
+	ChatExport
+	ChatImport
+	ScheduledMessage
+	Poll
+	LinkPreview
 }
 
 func New(
@@ -47,12 +196,105 @@ func New(
 	repository *repository.Repository,
 	jwt *jwt.JWT,
 	fileStorage filestorage.FileStorage,
+	config Config,
+	logger logger.Logger,
 ) *Services {
-	_ = uploader.NewUploader(fileStorage)
+	uploader := uploader.NewUploader(fileStorage)
+	webhook := NewWebhookService(repository.Webhook, repository.Chat, config.Webhook, logger)
+	notifier := NewUpdatesNotifier()
+	events := EventDispatchers{webhook, notifier}
+
+	filter := NewContentFilterService(
+		repository.FilterRule,
+		repository.FilterHit,
+		repository.Message,
+		repository.Chat,
+		config.Spam,
+	)
+	commands := command.NewRegistry()
+	command.RegisterBuiltins(commands, repository.Chat, repository.User, repository.Bot, repository.UserBlock, filter, events)
+	dispatcher := command.NewDispatcher(commands, repository.Chat)
+
+	presence := NewPresenceService(presence.NewMemoryStore(), repository.User, repository.Chat, events, config.Presence, logger)
+	mention := NewMentionService(repository.Mention, repository.Chat, repository.User, repository.UserBlock, presence)
+	linkPreview := NewLinkPreviewService(
+		repository.LinkPreview,
+		linkpreview.NewFetcher(linkpreview.Config{
+			Timeout:     config.LinkPreview.Timeout,
+			MaxBodySize: config.LinkPreview.MaxBodySize,
+		}),
+		events,
+		config.LinkPreview,
+		logger,
+	)
+	var limiter ratelimit.Limiter = ratelimit.NewMemoryLimiter()
+	if config.RateLimit.Backend == RateLimitBackendPostgres {
+		limiter = repository.RateLimit
+	}
+	message := NewMessageService(
+		repository.Message,
+		repository.Chat,
+		repository.User,
+		repository.UserBlock,
+		repository.Attachment,
+		repository.Poll,
+		repository.LinkPreview,
+		repository.Draft,
+		linkPreview,
+		limiter,
+		config.RateLimit,
+		dispatcher,
+		mention,
+		filter,
+		events,
+	)
+
 	return &Services{
-		User:          NewUserService(repository.User),
-		Authorization: NewAuthorizationSerive(jwt, repository.User),
-		Chat:          NewChatService(repository.Chat),
-		Message:       NewMessageService(repository.Message, repository.Chat),
+		User:            NewUserService(repository.User, repository.UserBlock, uploader, logger),
+		Authorization:   NewAuthorizationSerive(jwt, repository.User),
+		Chat:            NewChatService(repository.Chat, repository.Draft, repository.Category, events),
+		Category:        NewCategoryService(repository.Category, repository.Chat),
+		Draft:           NewDraftService(repository.Draft, repository.Chat),
+		Message:         message,
+		Bot:             NewBotService(repository.Bot, repository.User, repository.Chat, repository.Message, notifier),
+		Webhook:         webhook,
+		IncomingWebhook: NewIncomingWebhookService(repository.IncomingWebhook, repository.Chat, message, limiter, config.IncomingWebhook),
+		Mention:         mention,
+		Retention:       NewRetentionService(repository.Message, events, config.Retention, logger),
+		Presence:        presence,
+		Moderation:      NewModerationService(repository.Report, repository.User, repository.Chat, repository.Message, message),
+		ContentFilter:   filter,
+		Export: NewExportService(
+			repository.DataExport,
+			repository.User,
+			repository.Chat,
+			repository.Message,
+			repository.Attachment,
+			repository.UserBlock,
+			uploader,
+			fileStorage,
+			config.Export,
+			logger,
+		),
+		ChatExport: NewChatExportService(repository.Chat, repository.User, repository.Message, config.ChatExport, logger),
+		ChatImport: NewChatImportService(
+			repository.ChatImport,
+			repository.Chat,
+			repository.User,
+			uploader,
+			config.Import,
+			logger,
+		),
+		Poll:        NewPollService(repository.Poll, repository.Message, repository.Chat, message),
+		LinkPreview: linkPreview,
+		ScheduledMessage: NewScheduledMessageService(
+			repository.ScheduledMessage,
+			repository.Chat,
+			repository.User,
+			message,
+			clock.Get(),
+			config.Scheduler,
+			logger,
+		),
 	}
 }
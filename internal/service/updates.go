@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"sync"
+
+	"spsu-chat/internal/models"
+)
+
+// updatesBufferSize is how many events subscriber may fall behind before new ones are dropped.
+const updatesBufferSize = 16
+
+// UpdatesNotifier wakes long polling requests when chat events happen, woken
+// requests check the database for updates themselves. It is local to the process.
+type UpdatesNotifier struct {
+	mu          sync.Mutex
+	subscribers map[chan models.ChatEvent]struct{}
+}
+
+func NewUpdatesNotifier() *UpdatesNotifier {
+	return &UpdatesNotifier{
+		subscribers: make(map[chan models.ChatEvent]struct{}),
+	}
+}
+
+// Subscribe returns channel receiving dispatched events, returned function
+// must be called when the caller stops waiting.
+func (n *UpdatesNotifier) Subscribe() (<-chan models.ChatEvent, func()) {
+	events := make(chan models.ChatEvent, updatesBufferSize)
+
+	n.mu.Lock()
+	n.subscribers[events] = struct{}{}
+	n.mu.Unlock()
+
+	return events, func() {
+		n.mu.Lock()
+		delete(n.subscribers, events)
+		n.mu.Unlock()
+	}
+}
+
+// Dispatch passes the event to all subscribers, subscribers which are behind
+// miss it instead of blocking the caller.
+func (n *UpdatesNotifier) Dispatch(_ context.Context, event models.ChatEvent) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for events := range n.subscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// EventDispatchers passes events to all dispatchers in order.
+type EventDispatchers []EventDispatcher
+
+func (d EventDispatchers) Dispatch(ctx context.Context, event models.ChatEvent) {
+	for _, dispatcher := range d {
+		dispatcher.Dispatch(ctx, event)
+	}
+}
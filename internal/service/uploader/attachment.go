@@ -0,0 +1,44 @@
+package uploader
+
+import (
+	"fmt"
+	"path"
+
+	"spsu-chat/internal/filestorage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AttachmentFolder = "attachments"
+)
+
+func (u *Uploader) UploadAttachment(chatID int64, filename string, data []byte) (filestorage.FileInfo, error) {
+	fileInfo, err := u.fileStorage.SaveFile(u.formatAttachmentFolder(chatID), filename, data)
+	if err != nil {
+		return filestorage.FileInfo{}, fmt.Errorf("Uploader.UploadAttachment: %w", err)
+	}
+
+	return fileInfo, nil
+}
+
+func (u *Uploader) GetAttachment(chatID int64, fileID uuid.UUID) ([]byte, error) {
+	data, err := u.fileStorage.GetFile(u.formatAttachmentFolder(chatID), fileID)
+	if err != nil {
+		return nil, fmt.Errorf("Uploader.GetAttachment: %w", err)
+	}
+
+	return data, nil
+}
+
+func (u *Uploader) DeleteAttachment(chatID int64, fileID uuid.UUID) error {
+	if err := u.fileStorage.DeleteFile(u.formatAttachmentFolder(chatID), fileID); err != nil {
+		return fmt.Errorf("Uploader.DeleteAttachment: %w", err)
+	}
+
+	return nil
+}
+
+func (u *Uploader) formatAttachmentFolder(chatID int64) string {
+	return path.Join(AttachmentFolder, fmt.Sprintf("%d", chatID))
+}
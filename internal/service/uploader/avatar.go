@@ -0,0 +1,44 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"spsu-chat/internal/models"
+)
+
+// GetAvatars returns all avatars uploaded by the user.
+func (u *Uploader) GetAvatars(userID int64) ([]models.UploadFile, error) {
+	folder := u.formatAvatarFolder(userID)
+	infos, err := u.fileStorage.GetFilesFromBucket(folder)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []models.UploadFile{}, nil
+		}
+		return nil, fmt.Errorf("Uploader.GetAvatars: %w", err)
+	}
+
+	avatars := make([]models.UploadFile, 0, len(infos))
+	for _, info := range infos {
+		data, err := u.fileStorage.GetFile(folder, info.ID)
+		if err != nil {
+			return nil, fmt.Errorf("Uploader.GetAvatars: %w", err)
+		}
+		avatars = append(avatars, models.UploadFile{
+			Data:     data,
+			Filename: info.ID.String() + info.Extension,
+		})
+	}
+
+	return avatars, nil
+}
+
+// DeleteAvatars removes all avatars uploaded by the user.
+func (u *Uploader) DeleteAvatars(userID int64) error {
+	if err := u.fileStorage.DeleteBucket(u.formatAvatarFolder(userID)); err != nil {
+		return fmt.Errorf("Uploader.DeleteAvatars: %w", err)
+	}
+
+	return nil
+}
@@ -2,18 +2,34 @@ package service
 
 import (
 	"context"
+	"fmt"
 
+	"spsu-chat/internal/logger"
 	"spsu-chat/internal/models"
 	"spsu-chat/internal/repository"
+	"spsu-chat/internal/service/uploader"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/hash"
 )
 
 type UserService struct {
-	repo repository.User
+	repo      repository.User
+	blockRepo repository.UserBlock
+	uploader  *uploader.Uploader
+	logger    logger.Logger
 }
 
-func NewUserService(repository repository.User) *UserService {
+func NewUserService(
+	repository repository.User,
+	blockRepo repository.UserBlock,
+	uploader *uploader.Uploader,
+	logger logger.Logger,
+) *UserService {
 	return &UserService{
-		repo: repository,
+		repo:      repository,
+		blockRepo: blockRepo,
+		uploader:  uploader,
+		logger:    logger,
 	}
 }
 
@@ -35,3 +51,66 @@ func (u *UserService) GetAll(ctx context.Context, pagination models.Pagination)
 
 	return users, pagination.GetFull(total), err
 }
+
+// Search returns public profiles of active users matching the query, the best matches first.
+func (u *UserService) Search(ctx context.Context, input models.SearchUsersInput) ([]models.PublicUser, error) {
+	return u.repo.Search(ctx, models.SearchUsersRecord{
+		Query: input.Query,
+		Limit: input.Limit,
+		Now:   clock.Now(),
+	})
+}
+
+func (u *UserService) Block(ctx context.Context, blockerID int64, blockedID int64) error {
+	if blockerID == blockedID {
+		return models.ErrCannotBlockSelf
+	}
+	if _, err := u.repo.GetByID(ctx, blockedID); err != nil {
+		return handleNotFoundError(err, models.ErrUserNotFound)
+	}
+
+	return u.blockRepo.Block(ctx, models.CreateUserBlockRecord{
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: clock.Now(),
+	})
+}
+
+func (u *UserService) Unblock(ctx context.Context, blockerID int64, blockedID int64) error {
+	return handleNotFoundError(u.blockRepo.Unblock(ctx, blockerID, blockedID), models.ErrUserNotBlocked)
+}
+
+func (u *UserService) GetBlocked(ctx context.Context, blockerID int64) ([]models.User, error) {
+	return u.blockRepo.GetBlocked(ctx, blockerID)
+}
+
+// Delete anonymizes the account confirmed with its password: personal data and
+// avatars are erased, messages are kept and attributed to "Deleted user",
+// the user leaves all chats.
+func (u *UserService) Delete(ctx context.Context, userID int64, password string) error {
+	user, err := u.repo.GetByID(ctx, userID)
+	if err != nil {
+		return handleNotFoundError(err, models.ErrUserNotFound)
+	}
+	if len(user.PasswordHash) == 0 || hash.Compare(user.PasswordHash, password) != nil {
+		return models.ErrInvalidCredentials
+	}
+
+	err = u.repo.Anonymize(ctx, models.AnonymizeUserRecord{
+		ID:          user.ID,
+		Username:    fmt.Sprintf("deleted-%d", user.ID),
+		DisplayName: models.DeletedUserDisplayName,
+		DeletedAt:   clock.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	// avatars are removed after the account is deleted, so failure leaves
+	// only orphaned files
+	if err := u.uploader.DeleteAvatars(user.ID); err != nil {
+		u.logger.Errorf("UserService.Delete: user %d: %s", user.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"spsu-chat/internal/logger"
+	"spsu-chat/internal/models"
+	"spsu-chat/internal/repository"
+	"spsu-chat/pkg/clock"
+	"spsu-chat/pkg/netguard"
+	"spsu-chat/pkg/signature"
+	"spsu-chat/pkg/token"
+)
+
+const (
+	WebhookSignatureHeader  = "X-Webhook-Signature"
+	WebhookEventHeader      = "X-Webhook-Event"
+	WebhookDeliveryIDHeader = "X-Webhook-Delivery"
+
+	maxWebhookErrorLength = 512
+)
+
+// EventDispatcher delivers chat events to their subscribers.
+type EventDispatcher interface {
+	Dispatch(ctx context.Context, event models.ChatEvent)
+}
+
+type WebhookService struct {
+	repo     repository.Webhook
+	chatRepo repository.Chat
+	client   *http.Client
+	config   WebhookConfig
+	logger   logger.Logger
+}
+
+func NewWebhookService(repo repository.Webhook, chatRepo repository.Chat, config WebhookConfig, logger logger.Logger) *WebhookService {
+	// webhook urls are set by chat managers, so they must not reach internal hosts
+	dialer := &net.Dialer{Timeout: config.RequestTimeout, Control: netguard.Guard}
+
+	return &WebhookService{
+		repo:     repo,
+		chatRepo: chatRepo,
+		client: &http.Client{
+			Timeout: config.RequestTimeout,
+			Transport: &http.Transport{
+				// proxy would connect to the target instead of the guarded dialer
+				Proxy:                 nil,
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   config.RequestTimeout,
+				ResponseHeaderTimeout: config.RequestTimeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       time.Minute,
+			},
+		},
+		config: config,
+		logger: logger,
+	}
+}
+
+func (w *WebhookService) Create(ctx context.Context, user models.User, input models.CreateWebhookInput) (models.WebhookCredentials, error) {
+	if _, err := w.getManagedChat(ctx, user, input.ChatID); err != nil {
+		return models.WebhookCredentials{}, err
+	}
+
+	secret, err := token.Generate(token.DefaultLength)
+	if err != nil {
+		return models.WebhookCredentials{}, fmt.Errorf("WebhookService.Create: %w", err)
+	}
+
+	webhook, err := w.repo.Create(ctx, models.CreateWebhookRecord{
+		ChatID:    input.ChatID,
+		CreatorID: user.ID,
+		URL:       input.URL,
+		Secret:    secret,
+		CreatedAt: clock.Now(),
+	})
+	if err != nil {
+		return models.WebhookCredentials{}, err
+	}
+
+	return models.WebhookCredentials{Webhook: webhook, Secret: secret}, nil
+}
+
+func (w *WebhookService) GetAll(ctx context.Context, user models.User, chatID int64) ([]models.Webhook, error) {
+	if _, err := w.getManagedChat(ctx, user, chatID); err != nil {
+		return nil, err
+	}
+
+	return w.repo.GetAllByChat(ctx, chatID, false)
+}
+
+func (w *WebhookService) Delete(ctx context.Context, user models.User, chatID int64, webhookID int64) error {
+	if _, err := w.getChatWebhook(ctx, user, chatID, webhookID); err != nil {
+		return err
+	}
+
+	return w.repo.Delete(ctx, webhookID)
+}
+
+func (w *WebhookService) GetDeliveries(
+	ctx context.Context,
+	user models.User,
+	chatID int64,
+	webhookID int64,
+	pagination models.Pagination,
+) ([]models.WebhookDelivery, models.FullPagination, error) {
+	if _, err := w.getChatWebhook(ctx, user, chatID, webhookID); err != nil {
+		return nil, models.FullPagination{}, err
+	}
+
+	deliveries, total, err := w.repo.GetDeliveries(ctx, webhookID, models.DBPagination{
+		Offset: pagination.Offset(),
+		Limit:  pagination.Limit(),
+	})
+
+	return deliveries, pagination.GetFull(total), err
+}
+
+// Dispatch schedules delivery of the event to all active webhooks of the chat.
+// Actual delivery is done by the worker, so slow receivers don't affect the caller.
+func (w *WebhookService) Dispatch(ctx context.Context, event models.ChatEvent) {
+	if event.Type == models.ChatEventTyping {
+		return
+	}
+
+	webhooks, err := w.repo.GetAllByChat(ctx, event.ChatID, true)
+	if err != nil {
+		w.logger.Errorf("WebhookService.Dispatch: getting webhooks: %s", err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		w.logger.Errorf("WebhookService.Dispatch: marshaling event: %s", err)
+		return
+	}
+
+	deliveries := make([]models.CreateWebhookDeliveryRecord, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		deliveries = append(deliveries, models.CreateWebhookDeliveryRecord{
+			WebhookID: webhook.ID,
+			Event:     event.Type,
+			Payload:   payload,
+			CreatedAt: clock.Now(),
+		})
+	}
+
+	if err := w.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		w.logger.Errorf("WebhookService.Dispatch: creating deliveries: %s", err)
+	}
+}
+
+// Run delivers pending webhook events until ctx is done.
+func (w *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.WorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.deliverDue(ctx); err != nil {
+				w.logger.Errorf("WebhookService.Run: %s", err)
+			}
+		}
+	}
+}
+
+func (w *WebhookService) deliverDue(ctx context.Context) error {
+	deliveries, err := w.repo.GetDueDeliveries(ctx, clock.Now(), w.config.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery models.WebhookDelivery) {
+			defer wg.Done()
+			if err := w.deliver(ctx, delivery); err != nil {
+				w.logger.Errorf("WebhookService.deliver: delivery %d: %s", delivery.ID, err)
+			}
+		}(delivery)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+func (w *WebhookService) deliver(ctx context.Context, delivery models.WebhookDelivery) error {
+	webhook, err := w.repo.GetByID(ctx, delivery.WebhookID)
+	if err != nil {
+		return err
+	}
+
+	update := models.UpdateWebhookDeliveryRecord{
+		ID:       delivery.ID,
+		Attempts: delivery.Attempts + 1,
+	}
+
+	if !webhook.IsActive {
+		lastError := "webhook is disabled"
+		update.Status = models.WebhookDeliveryFailed
+		update.NextAttemptAt = delivery.NextAttemptAt
+		update.LastError = &lastError
+		update.Attempts = delivery.Attempts
+		return w.repo.UpdateDelivery(ctx, update)
+	}
+
+	responseCode, sendErr := w.send(ctx, webhook, delivery)
+	if responseCode != 0 {
+		update.ResponseCode = &responseCode
+	}
+
+	now := clock.Now()
+	if sendErr == nil {
+		update.Status = models.WebhookDeliveryDelivered
+		update.NextAttemptAt = now
+		update.DeliveredAt = &now
+		if err := w.repo.UpdateDelivery(ctx, update); err != nil {
+			return err
+		}
+		return w.repo.RecordSuccess(ctx, webhook.ID)
+	}
+
+	lastError := sendErr.Error()
+	if len(lastError) > maxWebhookErrorLength {
+		lastError = lastError[:maxWebhookErrorLength]
+	}
+	update.LastError = &lastError
+	update.Status = models.WebhookDeliveryPending
+	update.NextAttemptAt = now.Add(w.backoff(update.Attempts))
+	if update.Attempts >= w.config.MaxAttempts {
+		update.Status = models.WebhookDeliveryFailed
+	}
+
+	if err := w.repo.UpdateDelivery(ctx, update); err != nil {
+		return err
+	}
+
+	return w.repo.RecordFailure(ctx, webhook.ID, w.config.MaxFailures, now)
+}
+
+// send posts signed payload to the webhook url and returns response status code.
+func (w *WebhookService) send(ctx context.Context, webhook models.Webhook, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.Event))
+	req.Header.Set(WebhookDeliveryIDHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookSignatureHeader, signature.Sign(webhook.Secret, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// backoff returns exponential delay before the next attempt.
+func (w *WebhookService) backoff(attempts int) time.Duration {
+	delay := w.config.Backoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= w.config.MaxBackoff {
+			return w.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+func (w *WebhookService) getManagedChat(ctx context.Context, user models.User, chatID int64) (models.Chat, error) {
+	chat, err := w.chatRepo.GetByID(ctx, chatID)
+	if err != nil {
+		return chat, handleNotFoundError(err, models.ErrChatNotFound)
+	}
+	if !canManageChat(user, chat) {
+		return chat, models.ErrChatAccessDenied
+	}
+
+	return chat, nil
+}
+
+func (w *WebhookService) getChatWebhook(ctx context.Context, user models.User, chatID int64, webhookID int64) (models.Webhook, error) {
+	if _, err := w.getManagedChat(ctx, user, chatID); err != nil {
+		return models.Webhook{}, err
+	}
+
+	webhook, err := w.repo.GetByID(ctx, webhookID)
+	if err != nil {
+		return webhook, handleNotFoundError(err, models.ErrWebhookNotFound)
+	}
+	if webhook.ChatID != chatID {
+		return webhook, models.ErrWebhookNotFound
+	}
+
+	return webhook, nil
+}
@@ -1,6 +1,9 @@
 package clock
 
-import "time"
+import (
+	"sync"
+	"time"
+)
 
 type ClockI interface {
 	Now() time.Time
@@ -14,6 +17,37 @@ type DumbClock struct{}
 
 func (c DumbClock) Now() time.Time { return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC) }
 
+// ManualClock is moved forward explicitly, so tests can control time deterministically.
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewManualClock(now time.Time) *ManualClock {
+	return &ManualClock{now: now}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func (c *ManualClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
 var instance ClockI = RealClock{}
 
 func InitClock(isDumb bool) {
@@ -22,4 +56,7 @@ func InitClock(isDumb bool) {
 	}
 }
 
+// Get returns the clock used by Now, so it can be injected into components.
+func Get() ClockI { return instance }
+
 func Now() time.Time { return instance.Now() }
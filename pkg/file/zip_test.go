@@ -0,0 +1,37 @@
+package file_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"spsu-chat/pkg/file"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateZip(t *testing.T) {
+	entries := []file.ZipEntry{
+		{Name: "profile.json", Data: []byte(`{"id":1}`)},
+		{Name: "files/avatars/1.png", Data: []byte{0x89, 0x50, 0x4e, 0x47}},
+		{Name: "empty.txt", Data: []byte{}},
+	}
+
+	archive, err := file.CreateZip(entries)
+	require.NoError(t, err)
+
+	files, err := file.GetFilesFromZip(bytes.NewReader(archive))
+	require.NoError(t, err)
+	require.Len(t, files, len(entries))
+
+	for i, f := range files {
+		require.Equal(t, entries[i].Name, f.Name)
+
+		reader, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.NoError(t, reader.Close())
+		require.Equal(t, entries[i].Data, data)
+	}
+}
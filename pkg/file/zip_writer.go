@@ -0,0 +1,35 @@
+package file
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+)
+
+// ZipEntry is a file to put into zip archive, Name may contain directories.
+type ZipEntry struct {
+	Name string
+	Data []byte
+}
+
+// CreateZip builds zip archive of the entries in the given order.
+func CreateZip(entries []ZipEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+
+	for _, entry := range entries {
+		writer, err := zipWriter.Create(entry.Name)
+		if err != nil {
+			return nil, fmt.Errorf("CreateZip: failed to create %s: %w", entry.Name, err)
+		}
+		if _, err := writer.Write(entry.Data); err != nil {
+			return nil, fmt.Errorf("CreateZip: failed to write %s: %w", entry.Name, err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("CreateZip: failed to close archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,147 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"spsu-chat/pkg/netguard"
+)
+
+var (
+	ErrInvalidURL       = errors.New("url is not http or https")
+	ErrNotHTML          = errors.New("response is not html")
+	ErrTooManyRedirects = errors.New("too many redirects")
+)
+
+const (
+	DefaultTimeout      = 5 * time.Second
+	DefaultMaxBodySize  = 512 * 1024
+	DefaultMaxRedirects = 3
+	DefaultUserAgent    = "spsu-chat-linkpreview/1.0"
+)
+
+type Config struct {
+	Timeout time.Duration
+	// MaxBodySize limits how much of the page is read, metadata is expected in the head.
+	MaxBodySize  int64
+	MaxRedirects int
+	UserAgent    string
+	// AllowPrivateNetworks disables SSRF guard, it must be used only in tests.
+	AllowPrivateNetworks bool
+}
+
+// Fetcher downloads pages refusing to connect to private, loopback and other
+// non public addresses. Addresses are checked when connection is made, so
+// redirects and DNS records pointing to internal hosts are blocked too.
+type Fetcher struct {
+	client *http.Client
+	config Config
+}
+
+func NewFetcher(config Config) *Fetcher {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = DefaultMaxRedirects
+	}
+	if config.UserAgent == "" {
+		config.UserAgent = DefaultUserAgent
+	}
+
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = netguard.Guard
+	}
+
+	return &Fetcher{
+		client: &http.Client{
+			Timeout: config.Timeout,
+			Transport: &http.Transport{
+				// proxy would connect to the target instead of the guarded dialer
+				Proxy:                 nil,
+				DialContext:           dialer.DialContext,
+				TLSHandshakeTimeout:   config.Timeout,
+				ResponseHeaderTimeout: config.Timeout,
+				MaxIdleConns:          10,
+				IdleConnTimeout:       time.Minute,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) > config.MaxRedirects {
+					return ErrTooManyRedirects
+				}
+				if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+					return ErrInvalidURL
+				}
+				return nil
+			},
+		},
+		config: config,
+	}
+}
+
+// Fetch downloads the page and parses its metadata.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (Preview, error) {
+	pageURL, err := url.Parse(rawURL)
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		return Preview{}, ErrInvalidURL
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL.String(), nil)
+	if err != nil {
+		return Preview{}, fmt.Errorf("Fetcher.Fetch: %w", err)
+	}
+	req.Header.Set("User-Agent", f.config.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return Preview{}, fmt.Errorf("Fetcher.Fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Preview{}, fmt.Errorf("Fetcher.Fetch: unexpected status %d", resp.StatusCode)
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return Preview{}, ErrNotHTML
+	}
+
+	// page URL after redirects, relative image URLs are resolved against it
+	return Parse(io.LimitReader(resp.Body, f.config.MaxBodySize), resp.Request.URL), nil
+}
+
+var urlRe = regexp.MustCompile(`https?://[^\s<>"'` + "`" + `]+`)
+
+// FindURL returns the first http or https URL in the text.
+func FindURL(text string) string {
+	urls := FindURLs(text)
+	if len(urls) == 0 {
+		return ""
+	}
+	return text[urls[0][0]:urls[0][1]]
+}
+
+// FindURLs returns byte offsets of http and https URLs in the text.
+func FindURLs(text string) [][2]int {
+	urls := make([][2]int, 0)
+	for _, loc := range urlRe.FindAllStringIndex(text, -1) {
+		// trailing punctuation belongs to the sentence, not to the url
+		end := loc[0] + len(strings.TrimRight(text[loc[0]:loc[1]], ".,:;!?)]}"))
+		urls = append(urls, [2]int{loc[0], end})
+	}
+	return urls
+}
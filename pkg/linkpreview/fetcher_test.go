@@ -0,0 +1,128 @@
+package linkpreview_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"spsu-chat/pkg/linkpreview"
+	"spsu-chat/pkg/netguard"
+
+	"github.com/stretchr/testify/require"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+	<title>Fallback title</title>
+	<meta property="og:title" content="  Open   Graph title ">
+	<meta property="og:description" content="Page description">
+	<meta property="og:image" content="/images/cover.png">
+	<meta property="og:site_name" content="Example">
+</head>
+<body><meta property="og:title" content="ignored"></body>
+</html>`
+
+func newServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(page))
+	})
+	mux.HandleFunc("/plain", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Plain</title><meta name="description" content="Meta description"></head></html>`))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/huge", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html><head>" + strings.Repeat("<meta name=x content=y>", 1000) + "<title>Too far</title></head></html>"))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFetcherFetch(t *testing.T) {
+	server := newServer(t)
+	fetcher := linkpreview.NewFetcher(linkpreview.Config{AllowPrivateNetworks: true, MaxBodySize: 4096})
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected linkpreview.Preview
+		err      error
+	}{
+		{
+			name: "open graph",
+			path: "/page",
+			expected: linkpreview.Preview{
+				URL:         server.URL + "/page",
+				Title:       "Open Graph title",
+				Description: "Page description",
+				ImageURL:    server.URL + "/images/cover.png",
+				SiteName:    "Example",
+			},
+		},
+		{
+			name: "fallback",
+			path: "/plain",
+			expected: linkpreview.Preview{
+				URL:         server.URL + "/plain",
+				Title:       "Plain",
+				Description: "Meta description",
+			},
+		},
+		{
+			name: "redirect",
+			path: "/redirect",
+			expected: linkpreview.Preview{
+				URL:         server.URL + "/page",
+				Title:       "Open Graph title",
+				Description: "Page description",
+				ImageURL:    server.URL + "/images/cover.png",
+				SiteName:    "Example",
+			},
+		},
+		{name: "body limit", path: "/huge", expected: linkpreview.Preview{URL: server.URL + "/huge"}},
+		{name: "not html", path: "/json", err: linkpreview.ErrNotHTML},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preview, err := fetcher.Fetch(context.Background(), server.URL+tc.path)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, preview)
+		})
+	}
+}
+
+func TestFetcherGuard(t *testing.T) {
+	server := newServer(t)
+	fetcher := linkpreview.NewFetcher(linkpreview.Config{})
+
+	_, err := fetcher.Fetch(context.Background(), server.URL+"/page")
+	require.ErrorIs(t, err, netguard.ErrForbiddenAddress)
+
+	_, err = fetcher.Fetch(context.Background(), "file:///etc/passwd")
+	require.ErrorIs(t, err, linkpreview.ErrInvalidURL)
+}
+
+func TestFindURL(t *testing.T) {
+	require.Equal(t, "https://example.com/a?b=1", linkpreview.FindURL("see https://example.com/a?b=1."))
+	require.Equal(t, "", linkpreview.FindURL("no links here"))
+}
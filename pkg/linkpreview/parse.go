@@ -0,0 +1,136 @@
+// Package linkpreview fetches OpenGraph metadata of web pages to show link previews.
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+const (
+	maxTitleLength       = 256
+	maxDescriptionLength = 1024
+)
+
+// Preview is the page metadata shown under the message.
+type Preview struct {
+	URL         string
+	Title       string
+	Description string
+	ImageURL    string
+	SiteName    string
+}
+
+// IsEmpty reports whether page has nothing to show in the preview.
+func (p Preview) IsEmpty() bool {
+	return p.Title == "" && p.Description == "" && p.ImageURL == ""
+}
+
+// Parse reads OpenGraph metadata from the page head, <title> and description
+// meta tag are used if OpenGraph ones are missing. Relative image URL is
+// resolved against the page URL.
+func Parse(r io.Reader, pageURL *url.URL) Preview {
+	var (
+		preview = Preview{URL: pageURL.String()}
+		og      = make(map[string]string)
+		meta    = make(map[string]string)
+		title   strings.Builder
+		inTitle bool
+	)
+
+	z := html.NewTokenizer(r)
+parsing:
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			break parsing
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Body:
+				break parsing
+			case atom.Title:
+				inTitle = true
+			case atom.Meta:
+				if !hasAttr {
+					continue
+				}
+				key, content := metaAttrs(z)
+				if strings.HasPrefix(key, "og:") {
+					og[key] = content
+				} else if key != "" {
+					meta[key] = content
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+			switch atom.Lookup(name) {
+			case atom.Head:
+				break parsing
+			case atom.Title:
+				inTitle = false
+			}
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		}
+	}
+
+	preview.Title = clean(firstNonEmpty(og["og:title"], title.String()), maxTitleLength)
+	preview.Description = clean(firstNonEmpty(og["og:description"], meta["description"]), maxDescriptionLength)
+	preview.SiteName = clean(og["og:site_name"], maxTitleLength)
+	if image, err := pageURL.Parse(strings.TrimSpace(og["og:image"])); err == nil && og["og:image"] != "" &&
+		(image.Scheme == "http" || image.Scheme == "https") {
+		preview.ImageURL = image.String()
+	}
+
+	return preview
+}
+
+// metaAttrs returns lowercased property (or name) and content of the meta tag.
+func metaAttrs(z *html.Tokenizer) (string, string) {
+	var key, content string
+	for {
+		name, value, more := z.TagAttr()
+		switch string(name) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(string(value)))
+			}
+		case "content":
+			content = string(value)
+		}
+		if !more {
+			return key, content
+		}
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+var spacesRe = regexp.MustCompile(`\s+`)
+
+// clean collapses whitespaces and truncates the value to the maximum number of runes.
+func clean(value string, maxLength int) string {
+	value = strings.TrimSpace(spacesRe.ReplaceAllString(value, " "))
+	if !utf8.ValidString(value) {
+		value = strings.ToValidUTF8(value, "")
+	}
+	if utf8.RuneCountInString(value) > maxLength {
+		value = string([]rune(value)[:maxLength])
+	}
+	return value
+}
@@ -0,0 +1,139 @@
+package markdown
+
+import (
+	"html"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var allowedSchemes = []string{"http", "https", "mailto"}
+
+// SanitizeURL returns normalized link URL if it is allowed in links, links
+// with other schemes (e.g. javascript:) are not formatted.
+func SanitizeURL(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > MaxURLLength {
+		return "", false
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || !slices.Contains(allowedSchemes, strings.ToLower(u.Scheme)) {
+		return "", false
+	}
+	if u.Scheme != "mailto" && u.Host == "" {
+		return "", false
+	}
+
+	return u.String(), true
+}
+
+// HTML renders the text with entities as HTML escaping everything except
+// formatting tags. Entities which are out of the text or cross other
+// entities are ignored, so stored entities can not break the markup.
+func HTML(text string, entities []Entity) string {
+	runes := []rune(text)
+
+	sorted := slices.Clone(entities)
+	sortEntities(sorted)
+
+	var (
+		b     strings.Builder
+		open  = make([]Entity, 0, MaxDepth)
+		next  = 0
+		start = 0
+	)
+	flush := func(end int) {
+		if end > start {
+			b.WriteString(html.EscapeString(string(runes[start:end])))
+			start = end
+		}
+	}
+
+	for pos := 0; pos <= len(runes); pos++ {
+		for len(open) > 0 && open[len(open)-1].end() == pos {
+			flush(pos)
+			b.WriteString(closingTag(open[len(open)-1]))
+			open = open[:len(open)-1]
+		}
+
+		for next < len(sorted) && sorted[next].Offset <= pos {
+			entity := sorted[next]
+			next++
+			if !isRenderable(entity, pos, len(runes), open) {
+				continue
+			}
+
+			tag, ok := openingTag(entity)
+			if !ok {
+				continue
+			}
+			flush(pos)
+			b.WriteString(tag)
+			open = append(open, entity)
+		}
+	}
+	flush(len(runes))
+
+	return b.String()
+}
+
+func isRenderable(entity Entity, pos int, length int, open []Entity) bool {
+	if entity.Offset != pos || entity.Length <= 0 || entity.end() > length {
+		return false
+	}
+	if len(open) == 0 {
+		return true
+	}
+
+	parent := open[len(open)-1]
+	if parent.Type == EntityCode || parent.Type == EntityPre {
+		return false
+	}
+	return entity.end() <= parent.end()
+}
+
+func openingTag(entity Entity) (string, bool) {
+	switch entity.Type {
+	case EntityBold:
+		return "<b>", true
+	case EntityItalic:
+		return "<i>", true
+	case EntityCode:
+		return "<code>", true
+	case EntityPre:
+		if entity.Language != "" && len(entity.Language) <= MaxLanguageLength && languageRe.MatchString(entity.Language) {
+			return `<pre><code class="language-` + html.EscapeString(entity.Language) + `">`, true
+		}
+		return "<pre><code>", true
+	case EntityLink:
+		u, ok := SanitizeURL(entity.URL)
+		if !ok {
+			return "", false
+		}
+		return `<a href="` + html.EscapeString(u) + `" rel="nofollow noopener noreferrer" target="_blank">`, true
+	case EntitySpoiler:
+		return `<span class="spoiler">`, true
+	}
+
+	return "", false
+}
+
+func closingTag(entity Entity) string {
+	switch entity.Type {
+	case EntityBold:
+		return "</b>"
+	case EntityItalic:
+		return "</i>"
+	case EntityCode:
+		return "</code>"
+	case EntityPre:
+		return "</code></pre>"
+	case EntityLink:
+		return "</a>"
+	case EntitySpoiler:
+		return "</span>"
+	}
+
+	return ""
+}
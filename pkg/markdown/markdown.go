@@ -0,0 +1,291 @@
+// Package markdown parses the Markdown subset supported in messages into plain
+// text with formatting entities and renders entities back as safe HTML.
+//
+// Supported markup:
+//
+//	**bold**, *italic* or _italic_, `code`, ```language
+//	code block```, [text](https://example.com), ||spoiler||
+//
+// Markup which can not be parsed is left in the text as is, so parsing never fails.
+package markdown
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxEntities is the maximum number of entities in the text, text with
+	// more entities is not formatted at all.
+	MaxEntities = 100
+	// MaxDepth is the maximum nesting of entities, deeper markup is left as is.
+	MaxDepth = 3
+	// MaxURLLength is the maximum length of link URL.
+	MaxURLLength = 2048
+	// MaxLanguageLength is the maximum length of code block language.
+	MaxLanguageLength = 32
+)
+
+type EntityType string
+
+const (
+	EntityBold    EntityType = "bold"
+	EntityItalic  EntityType = "italic"
+	EntityCode    EntityType = "code"
+	EntityPre     EntityType = "pre"
+	EntityLink    EntityType = "link"
+	EntitySpoiler EntityType = "spoiler"
+)
+
+// Entity formats Length runes of the text starting from Offset rune.
+type Entity struct {
+	Type   EntityType `json:"type"`
+	Offset int        `json:"offset"`
+	Length int        `json:"length"`
+	// Language is set for code blocks if it is specified.
+	Language string `json:"language,omitempty"`
+	// URL is set for links.
+	URL string `json:"url,omitempty"`
+}
+
+func (e Entity) end() int {
+	return e.Offset + e.Length
+}
+
+var languageRe = regexp.MustCompile(`^[A-Za-z0-9_+#.-]+$`)
+
+// escapable runes are taken literally after backslash.
+const escapable = "\\*_`[]()|"
+
+// Parse strips markup from the text and returns entities of the plain text.
+func Parse(text string) (string, []Entity) {
+	p := parser{}
+	p.parse([]rune(text), 0)
+
+	if len(p.entities) == 0 {
+		return string(p.out), nil
+	}
+	if len(p.entities) > MaxEntities {
+		return text, nil
+	}
+
+	// entities are appended when they are closed, so outer ones come after inner
+	slices.Reverse(p.entities)
+	sortEntities(p.entities)
+
+	return string(p.out), p.entities
+}
+
+// sortEntities orders entities so outer ones go before the nested ones.
+func sortEntities(entities []Entity) {
+	slices.SortStableFunc(entities, func(a, b Entity) int {
+		if a.Offset != b.Offset {
+			return a.Offset - b.Offset
+		}
+		return b.Length - a.Length
+	})
+}
+
+type parser struct {
+	out      []rune
+	entities []Entity
+}
+
+func (p *parser) parse(src []rune, depth int) {
+	for i := 0; i < len(src); {
+		if n := p.parseSpan(src, i, depth); n > 0 {
+			i += n
+			continue
+		}
+
+		if src[i] == '\\' && i+1 < len(src) && strings.ContainsRune(escapable, src[i+1]) {
+			p.out = append(p.out, src[i+1])
+			i += 2
+			continue
+		}
+
+		p.out = append(p.out, src[i])
+		i++
+	}
+}
+
+// parseSpan parses formatted span starting at i and returns the number of
+// consumed runes, zero means there is no valid span at i.
+func (p *parser) parseSpan(src []rune, i int, depth int) int {
+	switch {
+	case hasPrefix(src[i:], "```"):
+		return p.parsePre(src, i)
+	case src[i] == '`':
+		return p.parseCode(src, i)
+	case hasPrefix(src[i:], "**"):
+		return p.parseEmphasis(src, i, depth, "**", EntityBold)
+	case hasPrefix(src[i:], "||"):
+		return p.parseEmphasis(src, i, depth, "||", EntitySpoiler)
+	case src[i] == '*':
+		return p.parseEmphasis(src, i, depth, "*", EntityItalic)
+	case src[i] == '_':
+		// underscores inside words are not markup, e.g. snake_case
+		if i > 0 && isWordRune(src[i-1]) {
+			return 0
+		}
+		return p.parseEmphasis(src, i, depth, "_", EntityItalic)
+	case src[i] == '[':
+		return p.parseLink(src, i, depth)
+	}
+
+	return 0
+}
+
+func (p *parser) parsePre(src []rune, i int) int {
+	start := i + 3
+	end := index(src, start, "```")
+	if end < 0 {
+		return 0
+	}
+
+	content := src[start:end]
+	language := ""
+	if newline := slices.Index(content, '\n'); newline >= 0 {
+		first := string(content[:newline])
+		if first == "" || (len(first) <= MaxLanguageLength && languageRe.MatchString(first)) {
+			language = first
+			content = content[newline+1:]
+		}
+	}
+	if len(content) == 0 {
+		return 0
+	}
+
+	p.entities = append(p.entities, Entity{
+		Type:     EntityPre,
+		Offset:   len(p.out),
+		Length:   len(content),
+		Language: language,
+	})
+	p.out = append(p.out, content...)
+
+	return end + 3 - i
+}
+
+func (p *parser) parseCode(src []rune, i int) int {
+	start := i + 1
+	end := index(src, start, "`")
+	if end <= start {
+		return 0
+	}
+
+	p.entities = append(p.entities, Entity{
+		Type:   EntityCode,
+		Offset: len(p.out),
+		Length: end - start,
+	})
+	p.out = append(p.out, src[start:end]...)
+
+	return end + 1 - i
+}
+
+func (p *parser) parseEmphasis(src []rune, i int, depth int, delimiter string, entityType EntityType) int {
+	start := i + len(delimiter)
+	if start >= len(src) || unicode.IsSpace(src[start]) {
+		return 0
+	}
+
+	end := p.closing(src, start, delimiter)
+	if end < 0 {
+		return 0
+	}
+
+	return p.appendNested(src[start:end], depth, Entity{Type: entityType}) + 2*len(delimiter)
+}
+
+// closing returns position of the delimiter closing the span which starts at
+// start. Closing delimiter must follow non-space rune, single "*" must not be
+// a part of "**" and "_" must not be followed by a word rune.
+func (p *parser) closing(src []rune, start int, delimiter string) int {
+	for end := start + 1; end < len(src); end++ {
+		end = index(src, end, delimiter)
+		if end < 0 {
+			return -1
+		}
+		if unicode.IsSpace(src[end-1]) || src[end-1] == '\\' {
+			continue
+		}
+
+		next := end + len(delimiter)
+		if delimiter == "*" && next < len(src) && src[next] == '*' {
+			end++
+			continue
+		}
+		if delimiter == "_" && next < len(src) && isWordRune(src[next]) {
+			continue
+		}
+
+		return end
+	}
+
+	return -1
+}
+
+func (p *parser) parseLink(src []rune, i int, depth int) int {
+	textEnd := index(src, i+1, "](")
+	if textEnd <= i+1 {
+		return 0
+	}
+	urlStart := textEnd + 2
+	urlEnd := index(src, urlStart, ")")
+	if urlEnd < 0 {
+		return 0
+	}
+
+	url, ok := SanitizeURL(string(src[urlStart:urlEnd]))
+	if !ok {
+		return 0
+	}
+
+	p.appendNested(src[i+1:textEnd], depth, Entity{Type: EntityLink, URL: url})
+
+	return urlEnd + 1 - i
+}
+
+// appendNested appends entity for the span content parsing content markup if
+// nesting allows it and returns number of runes taken by the content.
+func (p *parser) appendNested(content []rune, depth int, entity Entity) int {
+	entity.Offset = len(p.out)
+	if depth+1 < MaxDepth {
+		p.parse(content, depth+1)
+	} else {
+		p.out = append(p.out, content...)
+	}
+	entity.Length = len(p.out) - entity.Offset
+	if entity.Length > 0 {
+		p.entities = append(p.entities, entity)
+	}
+
+	return len(content)
+}
+
+func hasPrefix(src []rune, prefix string) bool {
+	for _, r := range prefix {
+		if len(src) == 0 || src[0] != r {
+			return false
+		}
+		src = src[1:]
+	}
+	return true
+}
+
+// index returns position of the first substr occurrence in src at or after from.
+func index(src []rune, from int, substr string) int {
+	for i := from; i < len(src); i++ {
+		if hasPrefix(src[i:], substr) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
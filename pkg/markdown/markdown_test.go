@@ -0,0 +1,127 @@
+package markdown_test
+
+import (
+	"testing"
+
+	"spsu-chat/pkg/markdown"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		plain    string
+		entities []markdown.Entity
+	}{
+		{name: "plain", text: "hello world", plain: "hello world"},
+		{
+			name:  "bold and italic",
+			text:  "**bold** and *italic* _too_",
+			plain: "bold and italic too",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityBold, Offset: 0, Length: 4},
+				{Type: markdown.EntityItalic, Offset: 9, Length: 6},
+				{Type: markdown.EntityItalic, Offset: 16, Length: 3},
+			},
+		},
+		{
+			name:  "nested",
+			text:  "**жирный _курсив_**",
+			plain: "жирный курсив",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityBold, Offset: 0, Length: 13},
+				{Type: markdown.EntityItalic, Offset: 7, Length: 6},
+			},
+		},
+		{
+			name:  "code keeps markup",
+			text:  "run `**x**`",
+			plain: "run **x**",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityCode, Offset: 4, Length: 5},
+			},
+		},
+		{
+			name:  "code block with language",
+			text:  "```go\nfmt.Println(1)```",
+			plain: "fmt.Println(1)",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityPre, Offset: 0, Length: 14, Language: "go"},
+			},
+		},
+		{
+			name:  "link and spoiler",
+			text:  "[site](https://example.com) ||secret||",
+			plain: "site secret",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityLink, Offset: 0, Length: 4, URL: "https://example.com"},
+				{Type: markdown.EntitySpoiler, Offset: 5, Length: 6},
+			},
+		},
+		{name: "unsafe link", text: "[x](javascript:alert(1))", plain: "[x](javascript:alert(1))"},
+		{name: "unclosed", text: "**bold *italic", plain: "**bold *italic"},
+		{name: "arithmetic", text: "2 * 3 * 4", plain: "2 * 3 * 4"},
+		{name: "snake case", text: "snake_case_name", plain: "snake_case_name"},
+		{name: "escaped", text: `\*not italic\*`, plain: "*not italic*"},
+		{
+			name:  "too deep",
+			text:  "**a _b ||c *d*||_**",
+			plain: "a b c *d*",
+			entities: []markdown.Entity{
+				{Type: markdown.EntityBold, Offset: 0, Length: 9},
+				{Type: markdown.EntityItalic, Offset: 2, Length: 7},
+				{Type: markdown.EntitySpoiler, Offset: 4, Length: 5},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			plain, entities := markdown.Parse(tc.text)
+			require.Equal(t, tc.plain, plain)
+			require.Equal(t, tc.entities, entities)
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "escapes text", text: "<script>alert(1)</script>", expected: "&lt;script&gt;alert(1)&lt;/script&gt;"},
+		{name: "formatting", text: "**a** _b_ ||c||", expected: `<b>a</b> <i>b</i> <span class="spoiler">c</span>`},
+		{name: "code", text: "`<b>`", expected: "<code>&lt;b&gt;</code>"},
+		{
+			name:     "code block",
+			text:     "```js\nif (a < b) {}```",
+			expected: `<pre><code class="language-js">if (a &lt; b) {}</code></pre>`,
+		},
+		{
+			name:     "link",
+			text:     `[**x**](https://example.com/?a=1&b="2")`,
+			expected: `<a href="https://example.com/?a=1&amp;b=&#34;2&#34;" rel="nofollow noopener noreferrer" target="_blank"><b>x</b></a>`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, markdown.HTML(markdown.Parse(tc.text)))
+		})
+	}
+}
+
+func TestHTMLInvalidEntities(t *testing.T) {
+	entities := []markdown.Entity{
+		{Type: markdown.EntityBold, Offset: 0, Length: 3},
+		{Type: markdown.EntityItalic, Offset: 2, Length: 3},
+		{Type: markdown.EntityLink, Offset: 0, Length: 1, URL: "javascript:alert(1)"},
+		{Type: markdown.EntityCode, Offset: 4, Length: 10},
+		{Type: "script", Offset: 4, Length: 1},
+	}
+
+	require.Equal(t, "<b>abc</b>de", markdown.HTML("abcde", entities))
+}
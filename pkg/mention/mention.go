@@ -0,0 +1,66 @@
+package mention
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	Prefix = '@'
+
+	// All mentions every chat member.
+	All = "all"
+	// Here mentions chat members who are online.
+	Here = "here"
+)
+
+// Parse returns unique mentioned usernames in order of appearance.
+// Mention starts with "@" at the beginning of the text or after a character
+// which can not be a part of a username, so emails are not mentions.
+// Special mentions "all" and "here" are returned lowercased.
+func Parse(text string) []string {
+	var (
+		usernames = make([]string, 0)
+		seen      = make(map[string]struct{})
+		prev      rune
+	)
+
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r != Prefix || isUsernameRune(prev) {
+			prev = r
+			i += size
+			continue
+		}
+
+		start := i + size
+		end := start
+		for end < len(text) {
+			r, size := utf8.DecodeRuneInString(text[end:])
+			if !isUsernameRune(r) {
+				break
+			}
+			end += size
+		}
+
+		// trailing punctuation belongs to the sentence, not to the username
+		username := strings.TrimRight(text[start:end], ".-")
+		if lower := strings.ToLower(username); lower == All || lower == Here {
+			username = lower
+		}
+		if _, ok := seen[username]; username != "" && !ok {
+			seen[username] = struct{}{}
+			usernames = append(usernames, username)
+		}
+
+		prev = Prefix
+		i = end
+	}
+
+	return usernames
+}
+
+func isUsernameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
@@ -0,0 +1,32 @@
+package mention_test
+
+import (
+	"testing"
+
+	"spsu-chat/pkg/mention"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{name: "no mentions", text: "hello world", expected: []string{}},
+		{name: "single", text: "@admin hello", expected: []string{"admin"}},
+		{name: "in sentence", text: "hi, @john.doe.", expected: []string{"john.doe"}},
+		{name: "duplicates", text: "@bob @alice @bob", expected: []string{"bob", "alice"}},
+		{name: "email", text: "write to user@example.com", expected: []string{}},
+		{name: "special", text: "@ALL @Here", expected: []string{"all", "here"}},
+		{name: "cyrillic", text: "привет @иван_1!", expected: []string{"иван_1"}},
+		{name: "bare prefix", text: "@ @@", expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, mention.Parse(tc.text))
+		})
+	}
+}
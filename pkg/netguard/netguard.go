@@ -0,0 +1,50 @@
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/netip"
+	"syscall"
+)
+
+var (
+	ErrForbiddenAddress = errors.New("address is not public")
+)
+
+// Guard is a net.Dialer Control function refusing connections to non public
+// addresses, it is checked after DNS resolution for every connection, so
+// redirects and DNS records pointing to internal hosts are blocked too.
+func Guard(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !IsPublicAddr(addr) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddr reports whether the address is routable in the internet.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
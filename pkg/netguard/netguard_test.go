@@ -0,0 +1,42 @@
+package netguard_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"spsu-chat/pkg/netguard"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard(t *testing.T) {
+	require.NoError(t, netguard.Guard("tcp", "8.8.8.8:443", nil))
+	require.ErrorIs(t, netguard.Guard("tcp", "127.0.0.1:80", nil), netguard.ErrForbiddenAddress)
+	require.ErrorIs(t, netguard.Guard("tcp6", "[::1]:80", nil), netguard.ErrForbiddenAddress)
+}
+
+func TestIsPublicAddr(t *testing.T) {
+	testCases := []struct {
+		addr     string
+		expected bool
+	}{
+		{addr: "8.8.8.8", expected: true},
+		{addr: "2a00:1450:4010:c05::8a", expected: true},
+		{addr: "127.0.0.1"},
+		{addr: "10.1.2.3"},
+		{addr: "172.16.0.1"},
+		{addr: "192.168.1.1"},
+		{addr: "169.254.169.254"},
+		{addr: "100.64.0.1"},
+		{addr: "0.0.0.0"},
+		{addr: "::1"},
+		{addr: "fd00::1"},
+		{addr: "::ffff:127.0.0.1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.addr, func(t *testing.T) {
+			require.Equal(t, tc.expected, netguard.IsPublicAddr(netip.MustParseAddr(tc.addr)))
+		})
+	}
+}
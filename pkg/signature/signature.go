@@ -0,0 +1,24 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+const (
+	Prefix = "sha256="
+)
+
+// Sign returns HMAC-SHA256 signature of the payload in "sha256=<hex>" format.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return Prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature matches the payload.
+func Verify(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
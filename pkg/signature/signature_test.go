@@ -0,0 +1,34 @@
+package signature_test
+
+import (
+	"testing"
+
+	"spsu-chat/pkg/signature"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	testCases := []struct {
+		secret  string
+		payload string
+	}{
+		{secret: "secret", payload: `{"event":"message.created"}`},
+		{secret: "5jTqCoqFqx6mFT", payload: ""},
+		{secret: "", payload: "payload"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.payload, func(t *testing.T) {
+			sign := signature.Sign(tc.secret, []byte(tc.payload))
+			require.True(t, signature.Verify(tc.secret, []byte(tc.payload), sign))
+			require.False(t, signature.Verify(tc.secret+"x", []byte(tc.payload), sign))
+		})
+	}
+}
+
+func TestSignKnownValue(t *testing.T) {
+	// RFC 4231 test case 2
+	sign := signature.Sign("Jefe", []byte("what do ya want for nothing?"))
+	require.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", sign)
+}
@@ -0,0 +1,130 @@
+// Package telegram reads chat exports made by Telegram Desktop ("Export chat history" in JSON format).
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFilename = "result.json"
+
+	MessageTypeMessage = "message"
+	MessageTypeService = "service"
+
+	dateLayout = "2006-01-02T15:04:05"
+)
+
+var (
+	ErrNotChatExport = errors.New("file is not a single chat export")
+)
+
+type Export struct {
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Type     string    `json:"type"`
+	Messages []Message `json:"messages"`
+}
+
+type Message struct {
+	ID           int64  `json:"id"`
+	Type         string `json:"type"`
+	Date         string `json:"date"`
+	DateUnixtime string `json:"date_unixtime"`
+	From         string `json:"from"`
+	FromID       string `json:"from_id"`
+	Text         Text   `json:"text"`
+	// Photo and File are paths relative to the export directory.
+	Photo    string `json:"photo"`
+	File     string `json:"file"`
+	MimeType string `json:"mime_type"`
+}
+
+// Time returns the time message was sent at. Unix time is preferred, since date
+// is written in the timezone of the exporting client.
+func (m Message) Time() (time.Time, error) {
+	if m.DateUnixtime != "" {
+		seconds, err := strconv.ParseInt(m.DateUnixtime, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("message %d: invalid date_unixtime: %w", m.ID, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	date, err := time.Parse(dateLayout, m.Date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("message %d: invalid date: %w", m.ID, err)
+	}
+
+	return date, nil
+}
+
+// Media returns paths of the files attached to the message. Files that were
+// not included into the export are skipped.
+func (m Message) Media() []string {
+	media := make([]string, 0, 2)
+	for _, path := range []string{m.Photo, m.File} {
+		// Telegram writes "(File not included. Change data exporting settings to download.)"
+		if path == "" || strings.HasPrefix(path, "(") {
+			continue
+		}
+		media = append(media, path)
+	}
+
+	return media
+}
+
+// Text is message text, Telegram writes it either as a string or as an array
+// of strings and formatted entities, the formatting is dropped.
+type Text string
+
+func (t *Text) UnmarshalJSON(data []byte) error {
+	var plain string
+	if err := json.Unmarshal(data, &plain); err == nil {
+		*t = Text(plain)
+		return nil
+	}
+
+	var parts []json.RawMessage
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("text must be a string or an array: %w", err)
+	}
+
+	var text strings.Builder
+	for _, part := range parts {
+		var entity struct {
+			Text string `json:"text"`
+		}
+		if err := json.Unmarshal(part, &plain); err == nil {
+			text.WriteString(plain)
+		} else if err := json.Unmarshal(part, &entity); err == nil {
+			text.WriteString(entity.Text)
+		} else {
+			return fmt.Errorf("invalid text entity: %w", err)
+		}
+	}
+	*t = Text(text.String())
+
+	return nil
+}
+
+// Parse reads single chat export. Full account exports are rejected.
+func Parse(r io.Reader) (Export, error) {
+	var document struct {
+		Export
+		Chats json.RawMessage `json:"chats"`
+	}
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return Export{}, fmt.Errorf("telegram.Parse: %w", err)
+	}
+	if document.Messages == nil {
+		return Export{}, ErrNotChatExport
+	}
+
+	return document.Export, nil
+}
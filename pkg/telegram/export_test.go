@@ -0,0 +1,75 @@
+package telegram_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"spsu-chat/pkg/telegram"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testExport = `{
+ "name": "Study group",
+ "type": "private_supergroup",
+ "id": 1234,
+ "messages": [
+  {
+   "id": 1,
+   "type": "service",
+   "date": "2023-09-01T10:00:00",
+   "date_unixtime": "1693551600",
+   "actor": "Alice",
+   "actor_id": "user1",
+   "action": "create_group",
+   "text": ""
+  },
+  {
+   "id": 2,
+   "type": "message",
+   "date": "2023-09-01T10:01:00",
+   "date_unixtime": "1693551660",
+   "from": "Alice",
+   "from_id": "user1",
+   "text": ["see ", {"type": "bold", "text": "this"}, " link"]
+  },
+  {
+   "id": 3,
+   "type": "message",
+   "date": "2023-09-01T10:02:00",
+   "from": "Bob",
+   "from_id": "user2",
+   "photo": "photos/photo_1.jpg",
+   "file": "(File not included. Change data exporting settings to download.)",
+   "text": "plain"
+  }
+ ]
+}`
+
+func TestParse(t *testing.T) {
+	export, err := telegram.Parse(strings.NewReader(testExport))
+	require.NoError(t, err)
+
+	require.Equal(t, "Study group", export.Name)
+	require.Len(t, export.Messages, 3)
+
+	message := export.Messages[1]
+	require.Equal(t, telegram.MessageTypeMessage, message.Type)
+	require.Equal(t, telegram.Text("see this link"), message.Text)
+	createdAt, err := message.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 9, 1, 7, 1, 0, 0, time.UTC), createdAt)
+
+	message = export.Messages[2]
+	require.Equal(t, telegram.Text("plain"), message.Text)
+	require.Equal(t, []string{"photos/photo_1.jpg"}, message.Media())
+	createdAt, err = message.Time()
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, 9, 1, 10, 2, 0, 0, time.UTC), createdAt)
+}
+
+func TestParseRejectsAccountExport(t *testing.T) {
+	_, err := telegram.Parse(strings.NewReader(`{"about": "", "chats": {"list": []}}`))
+	require.ErrorIs(t, err, telegram.ErrNotChatExport)
+}
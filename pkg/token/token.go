@@ -0,0 +1,29 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	DefaultLength = 32
+)
+
+// Generate returns random hex encoded token built from n random bytes.
+func Generate(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generating token: %w", err)
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Hash returns SHA-256 hash of the token.
+// Tokens are random enough, so there is no need in salt and slow hashing.
+func Hash(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
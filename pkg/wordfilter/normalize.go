@@ -0,0 +1,105 @@
+package wordfilter
+
+import (
+	"strings"
+	"unicode"
+)
+
+// cyrillicLookalikes are latin letters used instead of similar cyrillic ones.
+var cyrillicLookalikes = map[rune]rune{
+	'a': 'а', 'b': 'в', 'c': 'с', 'e': 'е', 'h': 'н', 'k': 'к', 'm': 'м',
+	'o': 'о', 'p': 'р', 't': 'т', 'u': 'и', 'x': 'х', 'y': 'у',
+}
+
+// latinLookalikes are cyrillic letters used instead of similar latin ones.
+var latinLookalikes = map[rune]rune{
+	'а': 'a', 'в': 'b', 'с': 'c', 'е': 'e', 'н': 'h', 'к': 'k', 'м': 'm',
+	'о': 'o', 'р': 'p', 'т': 't', 'х': 'x', 'у': 'y',
+}
+
+var cyrillicLeet = map[rune]rune{
+	'0': 'о', '3': 'з', '4': 'ч', '6': 'б', '@': 'а', '$': 'с',
+}
+
+var latinLeet = map[rune]rune{
+	'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's',
+}
+
+// russianEndings are noun and adjective endings stripped by stem, longer go
+// first. Verb endings are not stripped as they are also endings of noun stems.
+var russianEndings = []string{
+	"иями",
+	"ями", "ами", "ией", "иях", "ого", "его", "ому", "ему", "ыми", "ими",
+	"ях", "ах", "ов", "ев", "ей", "ой", "ий", "ый", "ая", "яя", "ое", "ее", "ие", "ые", "ом", "ем", "ам",
+	"ям", "ую", "юю", "ых", "их", "ия", "ья", "ью", "ию",
+	"а", "я", "о", "е", "ы", "и", "у", "ю", "ь", "й",
+}
+
+// minStemLength keeps short words from being stripped to nothing.
+const minStemLength = 3
+
+// Normalize returns the canonical form of a single word, so its variants
+// written with different case, leet digits, letters of the other script or
+// repeated letters are equal. Russian words are also stemmed, so different
+// forms of the word are equal too.
+func Normalize(word string) string {
+	normalized, cyrillic := fold(word)
+	if cyrillic {
+		normalized = stem(normalized)
+	}
+
+	return string(normalized)
+}
+
+// fold returns the word written in a single script without leet and repeated
+// letters and reports whether it is cyrillic.
+func fold(word string) ([]rune, bool) {
+	runes := []rune(strings.ToLower(word))
+
+	cyrillic := false
+	for _, r := range runes {
+		if unicode.Is(unicode.Cyrillic, r) {
+			cyrillic = true
+			break
+		}
+	}
+
+	leet, lookalikes := latinLeet, latinLookalikes
+	if cyrillic {
+		leet, lookalikes = cyrillicLeet, cyrillicLookalikes
+	}
+
+	normalized := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if r == 'ё' {
+			r = 'е'
+		}
+		if replacement, ok := leet[r]; ok {
+			r = replacement
+		}
+		if replacement, ok := lookalikes[r]; ok {
+			r = replacement
+		}
+		// repeated letters are collapsed: "дууурак" is "дурак"
+		if len(normalized) > 0 && normalized[len(normalized)-1] == r {
+			continue
+		}
+		normalized = append(normalized, r)
+	}
+
+	return normalized, cyrillic
+}
+
+// stem strips russian inflectional ending of the word.
+func stem(word []rune) []rune {
+	for _, ending := range russianEndings {
+		suffix := []rune(ending)
+		if len(word)-len(suffix) < minStemLength {
+			continue
+		}
+		if string(word[len(word)-len(suffix):]) == ending {
+			return word[:len(word)-len(suffix)]
+		}
+	}
+	return word
+}
@@ -0,0 +1,152 @@
+// Package wordfilter finds banned words in text written with intentional
+// misspellings: leet digits, letters of the other script and repeated letters.
+package wordfilter
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PrefixWildcard at the end of the pattern makes it match any word starting with it.
+const PrefixWildcard = "*"
+
+// Match is a banned word found in the text.
+type Match struct {
+	// Start and End are byte offsets of the word in the text.
+	Start int
+	End   int
+	// Pattern is index of the matched pattern.
+	Pattern int
+}
+
+type Filter struct {
+	words    map[string]int
+	prefixes []prefix
+}
+
+type prefix struct {
+	value   string
+	pattern int
+}
+
+// New creates filter of the patterns, pattern is a single word optionally
+// ending with PrefixWildcard.
+func New(patterns []string) *Filter {
+	f := &Filter{
+		words: make(map[string]int, len(patterns)),
+	}
+
+	for i, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if value, ok := strings.CutSuffix(pattern, PrefixWildcard); ok {
+			// prefixes are not stemmed, so they are normalized without stemming
+			if value = normalizePrefix(value); value != "" {
+				f.prefixes = append(f.prefixes, prefix{value: value, pattern: i})
+			}
+			continue
+		}
+		if word := Normalize(pattern); word != "" {
+			if _, ok := f.words[word]; !ok {
+				f.words[word] = i
+			}
+		}
+	}
+
+	return f
+}
+
+// Find returns banned words of the text in order of appearance.
+func (f *Filter) Find(text string) []Match {
+	matches := make([]Match, 0)
+	for _, w := range words(text) {
+		word := text[w.start:w.end]
+		if pattern, ok := f.match(word); ok {
+			matches = append(matches, Match{Start: w.start, End: w.end, Pattern: pattern})
+		}
+	}
+	return matches
+}
+
+func (f *Filter) match(word string) (int, bool) {
+	if pattern, ok := f.words[Normalize(word)]; ok {
+		return pattern, true
+	}
+	if len(f.prefixes) == 0 {
+		return 0, false
+	}
+
+	normalized := normalizePrefix(word)
+	for _, p := range f.prefixes {
+		if strings.HasPrefix(normalized, p.value) {
+			return p.pattern, true
+		}
+	}
+	return 0, false
+}
+
+// normalizePrefix normalizes the word keeping its ending.
+func normalizePrefix(word string) string {
+	normalized, _ := fold(word)
+	return string(normalized)
+}
+
+// Mask replaces letters and digits of the text ranges with asterisks, so text
+// length in runes does not change.
+func Mask(text string, ranges [][2]int) string {
+	if len(ranges) == 0 {
+		return text
+	}
+
+	masked := make([]byte, 0, len(text))
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if inRanges(i, ranges) && !unicode.IsSpace(r) {
+			masked = append(masked, '*')
+		} else {
+			masked = append(masked, text[i:i+size]...)
+		}
+		i += size
+	}
+	return string(masked)
+}
+
+func inRanges(offset int, ranges [][2]int) bool {
+	for _, r := range ranges {
+		if offset >= r[0] && offset < r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+type span struct {
+	start int
+	end   int
+}
+
+// words splits the text into words. Word starts with a letter, a digit or "$"
+// and may contain leet symbols, so "@" of mentions is not a part of the word.
+func words(text string) []span {
+	spans := make([]span, 0)
+	start := -1
+	for i, r := range text {
+		isLetter := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case start < 0 && (isLetter || r == '$'):
+			start = i
+		case start >= 0 && !isLetter && r != '@' && r != '$':
+			spans = append(spans, span{start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start: start, end: len(text)})
+	}
+
+	// trailing leet symbols are punctuation
+	for i := range spans {
+		spans[i].end = spans[i].start + len(strings.TrimRight(text[spans[i].start:spans[i].end], "@$"))
+	}
+	return spans
+}
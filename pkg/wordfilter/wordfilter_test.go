@@ -0,0 +1,73 @@
+package wordfilter_test
+
+import (
+	"testing"
+
+	"spsu-chat/pkg/wordfilter"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name     string
+		words    []string
+		expected string
+	}{
+		{name: "case and leet", words: []string{"spam", "SPAM", "5p@m", "sp4m"}, expected: "spam"},
+		{name: "repeated letters", words: []string{"spaaaam", "sspam"}, expected: "spam"},
+		{name: "russian forms", words: []string{"дурак", "дураки", "дураков", "дуракам", "ДУРАКА"}, expected: "дурак"},
+		{name: "latin lookalikes", words: []string{"дypak", "дуpaк"}, expected: "дурак"},
+		{name: "cyrillic leet", words: []string{"3апрет", "запрет", "запреты"}, expected: "запрет"},
+		{name: "yo", words: []string{"ёж", "еж"}, expected: "еж"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, word := range tc.words {
+				require.Equal(t, tc.expected, wordfilter.Normalize(word), word)
+			}
+		})
+	}
+}
+
+func TestFilterFind(t *testing.T) {
+	filter := wordfilter.New([]string{"дурак", "spam", "казино*"})
+
+	testCases := []struct {
+		name     string
+		text     string
+		expected []wordfilter.Match
+	}{
+		{name: "clean", text: "привет, как дела?", expected: []wordfilter.Match{}},
+		{
+			name: "word forms",
+			text: "ну ты и дypaк, все дураки",
+			expected: []wordfilter.Match{
+				{Start: 13, End: 20, Pattern: 0},
+				{Start: 29, End: 41, Pattern: 0},
+			},
+		},
+		{name: "leet", text: "no $p@m!", expected: []wordfilter.Match{{Start: 3, End: 7, Pattern: 1}}},
+		{name: "prefix", text: "онлайн-казиночик", expected: []wordfilter.Match{{Start: 13, End: 31, Pattern: 2}}},
+		{name: "mention is not leet", text: "@spamer hi", expected: []wordfilter.Match{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, filter.Find(tc.text))
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	filter := wordfilter.New([]string{"дурак"})
+	text := "ты дурак!"
+
+	ranges := make([][2]int, 0)
+	for _, match := range filter.Find(text) {
+		ranges = append(ranges, [2]int{match.Start, match.End})
+	}
+
+	require.Equal(t, "ты *****!", wordfilter.Mask(text, ranges))
+}
@@ -0,0 +1,9 @@
+CREATE TABLE bot_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    bot_id BIGINT NOT NULL REFERENCES users(id),
+    token_hash BYTEA NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX bot_tokens_bot_id_idx ON bot_tokens(bot_id);
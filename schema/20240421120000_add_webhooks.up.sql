@@ -0,0 +1,30 @@
+CREATE TABLE chat_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats(id),
+    creator_id BIGINT NOT NULL REFERENCES users(id),
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    disabled_at TIMESTAMPTZ
+);
+
+CREATE INDEX chat_webhooks_chat_id_idx ON chat_webhooks(chat_id);
+
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    webhook_id BIGINT NOT NULL REFERENCES chat_webhooks(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status SMALLINT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL,
+    response_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries(webhook_id);
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries(next_attempt_at) WHERE status = 0;
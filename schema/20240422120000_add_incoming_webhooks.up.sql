@@ -0,0 +1,14 @@
+CREATE TABLE incoming_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats(id),
+    creator_id BIGINT NOT NULL REFERENCES users(id),
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    token_hash BYTEA NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE INDEX incoming_webhooks_chat_id_idx ON incoming_webhooks(chat_id);
+
+ALTER TABLE messages ADD COLUMN sender_name TEXT;
@@ -0,0 +1,3 @@
+DROP TABLE bot_commands;
+ALTER TABLE chat_users DROP COLUMN role;
+ALTER TABLE chats DROP COLUMN topic;
@@ -0,0 +1,9 @@
+ALTER TABLE chats ADD COLUMN topic TEXT NOT NULL DEFAULT '';
+ALTER TABLE chat_users ADD COLUMN role SMALLINT NOT NULL DEFAULT 0;
+
+CREATE TABLE bot_commands (
+    bot_id BIGINT NOT NULL REFERENCES users(id),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    PRIMARY KEY (bot_id, name)
+);
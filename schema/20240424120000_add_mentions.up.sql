@@ -0,0 +1,13 @@
+CREATE TABLE mentions (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind SMALLINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    read_at TIMESTAMPTZ,
+    UNIQUE (message_id, user_id)
+);
+
+CREATE INDEX mentions_user_id_idx ON mentions (user_id, id DESC);
+CREATE INDEX mentions_unread_idx ON mentions (user_id) WHERE read_at IS NULL;
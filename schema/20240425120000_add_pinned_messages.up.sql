@@ -0,0 +1,9 @@
+CREATE TABLE pinned_messages (
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    pinned_by BIGINT NOT NULL REFERENCES users(id),
+    pinned_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (chat_id, message_id)
+);
+
+CREATE INDEX pinned_messages_message_id_idx ON pinned_messages (message_id);
@@ -0,0 +1,4 @@
+DELETE FROM messages WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE messages DROP COLUMN deleted_by;
+ALTER TABLE messages DROP COLUMN deleted_at;
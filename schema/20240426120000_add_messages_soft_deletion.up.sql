@@ -0,0 +1,4 @@
+ALTER TABLE messages ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE messages ADD COLUMN deleted_by BIGINT REFERENCES users(id);
+
+CREATE INDEX messages_deleted_at_idx ON messages (deleted_at) WHERE deleted_at IS NOT NULL;
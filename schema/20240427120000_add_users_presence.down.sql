@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN hide_presence;
+ALTER TABLE users DROP COLUMN last_seen_at;
@@ -0,0 +1,4 @@
+DROP TABLE moderation_actions;
+DROP TABLE reports;
+
+ALTER TABLE users DROP COLUMN status;
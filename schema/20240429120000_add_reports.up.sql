@@ -0,0 +1,30 @@
+ALTER TABLE users ADD COLUMN status SMALLINT NOT NULL DEFAULT 0;
+
+CREATE TABLE reports (
+    id BIGSERIAL PRIMARY KEY,
+    reporter_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type TEXT NOT NULL,
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open',
+    created_at TIMESTAMPTZ NOT NULL,
+    resolved_at TIMESTAMPTZ,
+    resolved_by BIGINT REFERENCES users(id)
+);
+
+CREATE INDEX reports_status_idx ON reports (status, id);
+CREATE UNIQUE INDEX reports_open_message_idx ON reports (reporter_id, message_id) WHERE status = 'open' AND target_type = 'message';
+CREATE UNIQUE INDEX reports_open_user_idx ON reports (reporter_id, user_id) WHERE status = 'open' AND target_type = 'user';
+
+CREATE TABLE moderation_actions (
+    id BIGSERIAL PRIMARY KEY,
+    admin_id BIGINT NOT NULL REFERENCES users(id),
+    action TEXT NOT NULL,
+    report_id BIGINT REFERENCES reports(id) ON DELETE SET NULL,
+    target_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    target_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,13 @@
+CREATE TABLE data_exports (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status SMALLINT NOT NULL DEFAULT 0,
+    file_id UUID,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+CREATE UNIQUE INDEX data_exports_pending_idx ON data_exports (user_id) WHERE status = 0;
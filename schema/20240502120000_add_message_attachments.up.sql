@@ -0,0 +1,11 @@
+CREATE TABLE message_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    file_id UUID NOT NULL,
+    filename TEXT NOT NULL,
+    url TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX message_attachments_message_id_idx ON message_attachments (message_id);
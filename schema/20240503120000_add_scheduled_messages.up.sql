@@ -0,0 +1,15 @@
+CREATE TABLE scheduled_messages (
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    send_at TIMESTAMPTZ NOT NULL,
+    status SMALLINT NOT NULL DEFAULT 0,
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX scheduled_messages_pending_idx ON scheduled_messages (send_at) WHERE status = 0;
+CREATE INDEX scheduled_messages_user_id_idx ON scheduled_messages (user_id);
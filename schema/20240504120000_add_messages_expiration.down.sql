@@ -0,0 +1,5 @@
+DROP INDEX messages_chat_id_created_at_idx;
+DROP INDEX messages_expires_at_idx;
+
+ALTER TABLE chats DROP COLUMN retention_days;
+ALTER TABLE messages DROP COLUMN expires_at;
@@ -0,0 +1,5 @@
+ALTER TABLE messages ADD COLUMN expires_at TIMESTAMPTZ;
+ALTER TABLE chats ADD COLUMN retention_days INTEGER;
+
+CREATE INDEX messages_expires_at_idx ON messages (expires_at) WHERE expires_at IS NOT NULL AND deleted_at IS NULL;
+CREATE INDEX messages_chat_id_created_at_idx ON messages (chat_id, created_at) WHERE deleted_at IS NULL;
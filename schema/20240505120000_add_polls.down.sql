@@ -0,0 +1,5 @@
+DROP TABLE poll_votes;
+DROP TABLE poll_options;
+DROP TABLE polls;
+
+ALTER TABLE messages DROP COLUMN kind;
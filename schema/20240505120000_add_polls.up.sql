@@ -0,0 +1,30 @@
+ALTER TABLE messages ADD COLUMN kind SMALLINT NOT NULL DEFAULT 0;
+
+CREATE TABLE polls (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL UNIQUE REFERENCES messages(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    multiple BOOLEAN NOT NULL,
+    anonymous BOOLEAN NOT NULL,
+    closes_at TIMESTAMPTZ,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE poll_options (
+    id BIGSERIAL PRIMARY KEY,
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    text TEXT NOT NULL,
+    UNIQUE (poll_id, position)
+);
+
+CREATE TABLE poll_votes (
+    poll_id BIGINT NOT NULL REFERENCES polls(id) ON DELETE CASCADE,
+    option_id BIGINT NOT NULL REFERENCES poll_options(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE INDEX poll_votes_poll_id_user_id_idx ON poll_votes (poll_id, user_id);
@@ -0,0 +1,6 @@
+ALTER TABLE chats DROP COLUMN forwarding_disabled;
+
+ALTER TABLE messages DROP COLUMN forwarded_sent_at;
+ALTER TABLE messages DROP COLUMN forwarded_from_message_id;
+ALTER TABLE messages DROP COLUMN forwarded_from_user_id;
+ALTER TABLE messages DROP COLUMN forwarded_from_chat_id;
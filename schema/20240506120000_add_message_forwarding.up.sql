@@ -0,0 +1,6 @@
+ALTER TABLE messages ADD COLUMN forwarded_from_chat_id BIGINT REFERENCES chats(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN forwarded_from_user_id BIGINT REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN forwarded_from_message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN forwarded_sent_at TIMESTAMPTZ;
+
+ALTER TABLE chats ADD COLUMN forwarding_disabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+ALTER TABLE messages DROP COLUMN link_preview_url;
+
+DROP TABLE link_previews;
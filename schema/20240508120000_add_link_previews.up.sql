@@ -0,0 +1,12 @@
+CREATE TABLE link_previews (
+    url TEXT PRIMARY KEY,
+    title TEXT NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    site_name TEXT NOT NULL DEFAULT '',
+    -- failed previews are cached too, so broken links are not fetched on every message
+    failed BOOLEAN NOT NULL DEFAULT FALSE,
+    fetched_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE messages ADD COLUMN link_preview_url TEXT REFERENCES link_previews (url) ON DELETE SET NULL;
@@ -0,0 +1,7 @@
+CREATE TABLE drafts (
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    chat_id BIGINT NOT NULL REFERENCES chats (id) ON DELETE CASCADE,
+    text TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, chat_id)
+);
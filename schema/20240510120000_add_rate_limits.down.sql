@@ -0,0 +1,3 @@
+DROP TABLE rate_limits;
+
+ALTER TABLE chats DROP COLUMN slow_mode_seconds;
@@ -0,0 +1,7 @@
+ALTER TABLE chats ADD COLUMN slow_mode_seconds INTEGER NOT NULL DEFAULT 0;
+
+-- tat is theoretical arrival time of the next event allowed by the limit
+CREATE TABLE rate_limits (
+    key TEXT PRIMARY KEY,
+    tat TIMESTAMPTZ NOT NULL
+);
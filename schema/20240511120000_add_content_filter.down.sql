@@ -0,0 +1,3 @@
+DROP INDEX messages_chat_id_user_id_created_at_idx;
+DROP TABLE filter_hits;
+DROP TABLE filter_rules;
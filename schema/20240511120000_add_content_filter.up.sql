@@ -0,0 +1,29 @@
+-- rules without chat are global, link_allow rules have no action
+CREATE TABLE filter_rules (
+    id BIGSERIAL PRIMARY KEY,
+    chat_id BIGINT REFERENCES chats(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL DEFAULT '',
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX filter_rules_pattern_idx ON filter_rules (COALESCE(chat_id, 0), kind, pattern);
+
+-- message_id is null for blocked messages, rule_id is null for spam heuristics
+CREATE TABLE filter_hits (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id BIGINT REFERENCES filter_rules(id) ON DELETE SET NULL,
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id BIGINT REFERENCES messages(id) ON DELETE SET NULL,
+    kind TEXT NOT NULL,
+    action TEXT NOT NULL,
+    match TEXT NOT NULL DEFAULT '',
+    text TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX filter_hits_chat_id_idx ON filter_hits (chat_id, id);
+CREATE INDEX messages_chat_id_user_id_created_at_idx ON messages (chat_id, user_id, created_at) WHERE deleted_at IS NULL;
@@ -0,0 +1,8 @@
+DROP TABLE chat_categories;
+DROP TABLE categories;
+
+DROP INDEX chat_users_chat_id_idx;
+DROP INDEX chats_created_at_idx;
+DROP INDEX chats_name_trgm_idx;
+
+DROP EXTENSION pg_trgm;
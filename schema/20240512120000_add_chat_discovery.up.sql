@@ -0,0 +1,22 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- trigram index serves case-insensitive substring search by name
+CREATE INDEX chats_name_trgm_idx ON chats USING GIN (name gin_trgm_ops);
+CREATE INDEX chats_created_at_idx ON chats (created_at);
+-- member count of a chat, last activity is served by messages_chat_id_created_at_idx
+CREATE INDEX chat_users_chat_id_idx ON chat_users (chat_id);
+
+CREATE TABLE categories (
+    id BIGSERIAL PRIMARY KEY,
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE chat_categories (
+    chat_id BIGINT NOT NULL REFERENCES chats(id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    PRIMARY KEY (chat_id, category_id)
+);
+
+CREATE INDEX chat_categories_category_id_idx ON chat_categories (category_id);
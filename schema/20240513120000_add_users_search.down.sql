@@ -0,0 +1,2 @@
+DROP INDEX users_display_name_trgm_idx;
+DROP INDEX users_username_trgm_idx;
@@ -0,0 +1,3 @@
+-- trigram indexes serve both prefix (ILIKE) and fuzzy (%) matching, pg_trgm is created by chat discovery migration
+CREATE INDEX users_username_trgm_idx ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX users_display_name_trgm_idx ON users USING GIN (display_name gin_trgm_ops);